@@ -0,0 +1,296 @@
+// Copyright 2018 The go-ruereum Authors
+// This file is part of the go-ruereum library.
+//
+// The go-ruereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ruereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ruereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Rue-Foundation/go-rue/cmd/utils"
+	"github.com/Rue-Foundation/go-rue/common"
+	"github.com/Rue-Foundation/go-rue/core"
+	"github.com/Rue-Foundation/go-rue/log"
+	"github.com/Rue-Foundation/go-rue/ruedb"
+	"github.com/olekukonko/tablewriter"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	inspectJSONFlag = cli.BoolFlag{
+		Name:  "json",
+		Usage: "Print the statistics as JSON",
+	}
+	dbCommand = cli.Command{
+		Name:     "db",
+		Usage:    "Low level database operations",
+		Category: "DATABASE COMMANDS",
+		Subcommands: []cli.Command{
+			{
+				Name:   "inspect",
+				Usage:  "Inspect the storage size for each type of data in the database",
+				Action: utils.MigrateFlags(inspectDB),
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.AncientFlag,
+					utils.TestnetFlag,
+					utils.RinkebyFlag,
+					utils.LightModeFlag,
+					utils.CacheFlag,
+					inspectJSONFlag,
+				},
+				Description: `
+grue db inspect
+iterates over the entire chain database, classifying each entry by its key, and
+prints the number of entries and their total size per category of data. Blocks
+moved into the ancient store are accounted for separately. With --json the
+statistics are printed as a JSON array, sizes in bytes.`,
+			},
+			{
+				Name:   "convert",
+				Usage:  "Migrate the databases to another backend engine",
+				Action: utils.MigrateFlags(convertDB),
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.DBEngineFlag,
+					utils.TestnetFlag,
+					utils.RinkebyFlag,
+					utils.CacheFlag,
+				},
+				Description: `
+grue db convert --db.engine <engine>
+copies the full and light chain databases of the data directory into new ones
+created with the requested backend engine, replacing the originals and recording
+the new engine in the data directory. The ancient store is moved over as is. The
+engine is only recorded once every database was replaced, on failure the
+originals are restored.
+
+The node must be stopped while converting. Enough free disk space to hold a
+second copy of the databases is needed.`,
+			},
+		},
+	}
+)
+
+// inspectDB prints the storage accounting of the chain database.
+func inspectDB(ctx *cli.Context) error {
+	stack, _ := makeConfigNode(ctx)
+	chainDb := utils.MakeChainDatabase(ctx, stack)
+	defer chainDb.Close()
+
+	stats, err := core.InspectDatabase(chainDb)
+	if err != nil {
+		utils.Fatalf("Failed to inspect database: %v", err)
+	}
+	if ctx.Bool(inspectJSONFlag.Name) {
+		out, err := json.MarshalIndent(stats, "", "  ")
+		if err != nil {
+			utils.Fatalf("Failed to encode statistics: %v", err)
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+	var (
+		table = tablewriter.NewWriter(os.Stdout)
+		count uint64
+		size  uint64
+	)
+	table.SetAutoFormatHeaders(false)
+	table.SetHeader([]string{"Database", "Category", "Items", "Size"})
+	for _, stat := range stats {
+		table.Append([]string{stat.Database, stat.Category, fmt.Sprintf("%d", stat.Count), common.StorageSize(stat.Size).String()})
+		if stat.Database != "Ancient store" || stat.Category == "Headers" {
+			count += stat.Count
+		}
+		size += stat.Size
+	}
+	table.SetFooter([]string{"", "Total", fmt.Sprintf("%d", count), common.StorageSize(size).String()})
+	table.Render()
+	return nil
+}
+
+// convertDB migrates the chain databases of the data directory to the backend
+// engine requested via --db.engine.
+func convertDB(ctx *cli.Context) error {
+	target := ctx.GlobalString(utils.DBEngineFlag.Name)
+	if target == "" {
+		utils.Fatalf("Target engine missing, use --%s", utils.DBEngineFlag.Name)
+	}
+	stack, cfg := makeConfigNode(ctx)
+
+	// Resolve the engine currently in use, ignoring the requested one
+	current := cfg.Node
+	current.DBEngine = ""
+	source, err := current.DatabaseEngine()
+	if err != nil {
+		utils.Fatalf("Failed to resolve database engine: %v", err)
+	}
+	if source == target {
+		utils.Fatalf("Databases already use the %s engine", target)
+	}
+	var dirs []string
+	for _, name := range []string{"chaindata", "lightchaindata"} {
+		dir := stack.ResolvePath(name)
+		if !common.FileExist(dir) {
+			continue
+		}
+		if common.FileExist(dir + ".old") {
+			utils.Fatalf("Leftover %s from an interrupted conversion, remove it first", dir+".old")
+		}
+		dirs = append(dirs, dir)
+	}
+	// Copy every database before touching any, so a failure leaves the originals intact
+	for _, dir := range dirs {
+		if err := convertDatabase(dir, source, target, cfg.Eth.DatabaseCache, cfg.Eth.DatabaseHandles); err != nil {
+			restoreDatabases(dirs)
+			utils.Fatalf("Failed to convert %s: %v", dir, err)
+		}
+	}
+	// Swap in the converted databases and only then record the new engine. If
+	// anything fails, the swapped directories are rolled back to the originals.
+	for _, dir := range dirs {
+		if err := swapDatabase(dir); err != nil {
+			restoreDatabases(dirs)
+			utils.Fatalf("Failed to replace %s: %v", dir, err)
+		}
+	}
+	if err := current.SetDatabaseEngine(target); err != nil {
+		restoreDatabases(dirs)
+		utils.Fatalf("Failed to record database engine: %v", err)
+	}
+	// All databases were replaced, drop the originals
+	for _, dir := range dirs {
+		if err := os.RemoveAll(dir + ".old"); err != nil {
+			log.Error("Failed to remove original database", "path", dir+".old", "err", err)
+		}
+	}
+	log.Info("Database conversion successful", "from", source, "to", target)
+	return nil
+}
+
+// convertDatabase copies all the entries of a database into a new one using the
+// target engine, created next to the original with a .converting suffix.
+func convertDatabase(dir string, source, target string, cache, handles int) error {
+	tmp := dir + ".converting"
+	if err := os.RemoveAll(tmp); err != nil {
+		return err
+	}
+	src, err := ruedb.Open(source, dir, cache, handles)
+	if err != nil {
+		return err
+	}
+	dst, err := ruedb.Open(target, tmp, cache, handles)
+	if err != nil {
+		src.Close()
+		return err
+	}
+	err = copyDatabase(dir, src, dst)
+	src.Close()
+	dst.Close()
+	return err
+}
+
+// swapDatabase moves the ancient store into the converted database and swaps
+// the two directories. The original is kept with an .old suffix.
+func swapDatabase(dir string) error {
+	tmp := dir + ".converting"
+	if ancient := filepath.Join(dir, "ancient"); common.FileExist(ancient) {
+		if err := os.Rename(ancient, filepath.Join(tmp, "ancient")); err != nil {
+			return err
+		}
+	}
+	if err := os.Rename(dir, dir+".old"); err != nil {
+		return err
+	}
+	return os.Rename(tmp, dir)
+}
+
+// restoreDatabases rolls back a failed conversion, putting the original
+// databases and their ancient stores back in place and deleting the converted
+// ones. Each database may be in any state between copying and swapping.
+func restoreDatabases(dirs []string) {
+	for _, dir := range dirs {
+		if err := restoreDatabase(dir); err != nil {
+			log.Error("Failed to restore original database", "path", dir, "err", err)
+		}
+	}
+}
+
+// restoreDatabase undoes the steps of convertDatabase and swapDatabase done on
+// a single database, in reverse order.
+func restoreDatabase(dir string) error {
+	tmp := dir + ".converting"
+	if common.FileExist(dir + ".old") {
+		if common.FileExist(dir) {
+			if err := os.Rename(dir, tmp); err != nil {
+				return err
+			}
+		}
+		if err := os.Rename(dir+".old", dir); err != nil {
+			return err
+		}
+	}
+	if ancient := filepath.Join(tmp, "ancient"); common.FileExist(ancient) {
+		if err := os.Rename(ancient, filepath.Join(dir, "ancient")); err != nil {
+			return err
+		}
+	}
+	return os.RemoveAll(tmp)
+}
+
+// copyDatabase copies all the entries of the source database into the
+// destination one in batches.
+func copyDatabase(dir string, src, dst ruedb.Database) error {
+	var (
+		it     = src.NewIterator(nil, nil)
+		batch  = dst.NewBatch()
+		count  int
+		size   common.StorageSize
+		start  = time.Now()
+		logged = time.Now()
+	)
+	defer it.Release()
+
+	for it.Next() {
+		if err := batch.Put(it.Key(), it.Value()); err != nil {
+			return err
+		}
+		count++
+		size += common.StorageSize(len(it.Key()) + len(it.Value()))
+
+		if batch.ValueSize() >= ruedb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Converting database", "path", dir, "entries", count, "size", size, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	log.Info("Converted database", "path", dir, "entries", count, "size", size, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
@@ -0,0 +1,71 @@
+// Copyright 2018 The go-ruereum Authors
+// This file is part of the go-ruereum library.
+//
+// The go-ruereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ruereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ruereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Tests that a database conversion failing midway is rolled back, restoring the
+// original databases and their ancient stores whether or not they were swapped.
+func TestConvertRollback(t *testing.T) {
+	root, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(root)
+
+	var dirs []string
+	for _, name := range []string{"chaindata", "lightchaindata"} {
+		dir := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Join(dir, "ancient"), 0700); err != nil {
+			t.Fatalf("failed to create database: %v", err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, "data"), []byte("original"), 0600); err != nil {
+			t.Fatalf("failed to write database: %v", err)
+		}
+		if err := os.MkdirAll(dir+".converting", 0700); err != nil {
+			t.Fatalf("failed to create converted database: %v", err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir+".converting", "data"), []byte("converted"), 0600); err != nil {
+			t.Fatalf("failed to write converted database: %v", err)
+		}
+		dirs = append(dirs, dir)
+	}
+	// Swap only the first database, as if the second one failed
+	if err := swapDatabase(dirs[0]); err != nil {
+		t.Fatalf("failed to swap database: %v", err)
+	}
+	restoreDatabases(dirs)
+
+	for _, dir := range dirs {
+		if blob, err := ioutil.ReadFile(filepath.Join(dir, "data")); err != nil || string(blob) != "original" {
+			t.Errorf("%s: original not restored: have %q, err %v", dir, blob, err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "ancient")); err != nil {
+			t.Errorf("%s: ancient store not restored: %v", dir, err)
+		}
+		for _, leftover := range []string{dir + ".old", dir + ".converting"} {
+			if _, err := os.Stat(leftover); !os.IsNotExist(err) {
+				t.Errorf("%s: leftover not removed: %v", leftover, err)
+			}
+		}
+	}
+}
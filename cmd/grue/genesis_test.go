@@ -82,6 +82,27 @@ var customGenesisTests = []struct {
 		query:  "rue.getBlock(0).nonce",
 		result: "0x0000000000000042",
 	},
+	// Genesis file scheduling the Byzantium and Constantinople forks
+	{
+		genesis: `{
+			"alloc"      : {},
+			"coinbase"   : "0x0000000000000000000000000000000000000000",
+			"difficulty" : "0x20000",
+			"extraData"  : "",
+			"gasLimit"   : "0x2fefd8",
+			"nonce"      : "0x0000000000000042",
+			"mixhash"    : "0x0000000000000000000000000000000000000000000000000000000000000000",
+			"parentHash" : "0x0000000000000000000000000000000000000000000000000000000000000000",
+			"timestamp"  : "0x00",
+			"config"     : {
+				"homesteadBlock"      : 314,
+				"byzantiumBlock"      : 4370,
+				"constantinopleBlock" : 7280
+			},
+		}`,
+		query:  "rue.getBlock(0).nonce",
+		result: "0x0000000000000042",
+	},
 }
 
 // Tests that initializing Grue with a custom genesis block and chain definitions
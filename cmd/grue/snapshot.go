@@ -0,0 +1,112 @@
+// Copyright 2018 The go-ruereum Authors
+// This file is part of go-ruereum.
+//
+// go-ruereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ruereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ruereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"strconv"
+
+	"github.com/Rue-Foundation/go-rue/cmd/utils"
+	"github.com/Rue-Foundation/go-rue/common"
+	"github.com/Rue-Foundation/go-rue/core"
+	"github.com/Rue-Foundation/go-rue/core/state/pruner"
+	"github.com/Rue-Foundation/go-rue/log"
+	"github.com/Rue-Foundation/go-rue/ruedb"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	snapshotCommand = cli.Command{
+		Name:     "snapshot",
+		Usage:    "A set of commands based on the state snapshot",
+		Category: "MISCELLANEOUS COMMANDS",
+		Subcommands: []cli.Command{
+			{
+				Name:      "prune-state",
+				Usage:     "Prune stale state data from the database",
+				ArgsUsage: "[<blockNum|blockHash>]",
+				Action:    utils.MigrateFlags(pruneState),
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.AncientFlag,
+					utils.TestnetFlag,
+					utils.RinkebyFlag,
+					utils.CacheFlag,
+					utils.BloomFilterSizeFlag,
+				},
+				Description: `
+grue snapshot prune-state [<blockNum|blockHash>]
+will prune all the historical state data not reachable from the state of the
+given block, or from the state 127 blocks below the current head if omitted.
+The genesis state is always retained.
+
+The node must be stopped while pruning. If pruning is interrupted, running the
+command again or starting the node will finish the interrupted run. After the
+pruning is done, the node rewinds its head to the retained block on the next
+start and re-executes the blocks above it.`,
+			},
+		},
+	}
+)
+
+// pruneState deletes all the state trie nodes not reachable from the state of
+// the requested block, or the most recent safe one if none was given.
+func pruneState(ctx *cli.Context) error {
+	if len(ctx.Args()) > 1 {
+		utils.Fatalf("This command accepts at most one argument.")
+	}
+	stack, _ := makeConfigNode(ctx)
+	chainDb := utils.MakeChainDatabase(ctx, stack)
+	defer chainDb.Close()
+
+	var root common.Hash
+	if arg := ctx.Args().First(); arg != "" {
+		root = blockRoot(chainDb, arg)
+	}
+	p, err := pruner.NewPruner(chainDb, stack.ResolvePath(pruner.BloomFileName), ctx.Uint64(utils.BloomFilterSizeFlag.Name))
+	if err != nil {
+		utils.Fatalf("Failed to create state pruner: %v", err)
+	}
+	if err := p.Prune(root); err != nil {
+		utils.Fatalf("Failed to prune state: %v", err)
+	}
+	log.Info("State pruning successful")
+	return nil
+}
+
+// blockRoot resolves a block number or hash argument into the state root of the
+// referenced block.
+func blockRoot(db ruedb.Database, arg string) common.Hash {
+	var (
+		hash   common.Hash
+		number uint64
+	)
+	if hashish(arg) {
+		hash = common.HexToHash(arg)
+		number = core.GetBlockNumber(db, hash)
+	} else {
+		n, err := strconv.ParseUint(arg, 10, 64)
+		if err != nil {
+			utils.Fatalf("Invalid block number %q: %v", arg, err)
+		}
+		number, hash = n, core.GetCanonicalHash(db, n)
+	}
+	header := core.GetHeader(db, hash, number)
+	if header == nil {
+		utils.Fatalf("Block %s not found", arg)
+	}
+	return header.Root
+}
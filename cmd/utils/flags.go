@@ -19,6 +19,7 @@ package utils
 
 import (
 	"crypto/ecdsa"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"math/big"
@@ -39,6 +40,7 @@ import (
 	"github.com/Rue-Foundation/go-rue/core/vm"
 	"github.com/Rue-Foundation/go-rue/crypto"
 	"github.com/Rue-Foundation/go-rue/dashboard"
+	"github.com/Rue-Foundation/go-rue/graphql"
 	"github.com/Rue-Foundation/go-rue/rue"
 	"github.com/Rue-Foundation/go-rue/rue/downloader"
 	"github.com/Rue-Foundation/go-rue/rue/gasprice"
@@ -117,6 +119,14 @@ var (
 		Usage: "Data directory for the databases and keystore",
 		Value: DirectoryString{node.DefaultDataDir()},
 	}
+	AncientFlag = DirectoryFlag{
+		Name:  "datadir.ancient",
+		Usage: "Data directory for ancient chain segments (default = inside chaindata)",
+	}
+	DBEngineFlag = cli.StringFlag{
+		Name:  "db.engine",
+		Usage: "Backend engine of new databases, recorded in the datadir (" + strings.Join(ruedb.Engines(), ", ") + ")",
+	}
 	KeyStoreDirFlag = DirectoryFlag{
 		Name:  "keystore",
 		Usage: "Directory for the keystore (default = inside the datadir)",
@@ -169,6 +179,19 @@ var (
 		Usage: `Blockchain sync mode ("fast", "full", or "light")`,
 		Value: &defaultSyncMode,
 	}
+	GCModeFlag = cli.StringFlag{
+		Name:  "gcmode",
+		Usage: `Blockchain garbage collection mode ("full", "archive")`,
+		Value: "full",
+	}
+	LogIndexFlag = cli.BoolFlag{
+		Name:  "index.logs",
+		Usage: "Maintain an address and topic index of all logs for faster log filtering",
+	}
+	AddressIndexFlag = cli.BoolFlag{
+		Name:  "index.addresses",
+		Usage: "Maintain an index of the transactions touching each address (enables rue_getTransactionsByAddress lookups)",
+	}
 
 	LightServFlag = cli.IntFlag{
 		Name:  "lightserv",
@@ -254,6 +277,10 @@ var (
 		Usage: "Time interval to regenerate the local transaction journal",
 		Value: core.DefaultTxPoolConfig.Rejournal,
 	}
+	TxPoolJournalRemotesFlag = cli.BoolFlag{
+		Name:  "txpool.journalremotes",
+		Usage: "Journal remote transactions too, restoring the full pool on restart",
+	}
 	TxPoolPriceLimitFlag = cli.Uint64Flag{
 		Name:  "txpool.pricelimit",
 		Usage: "Minimum gas price limit to enforce for acceptance into the pool",
@@ -300,6 +327,11 @@ var (
 		Usage: "Number of trie node generations to keep in memory",
 		Value: int(state.MaxTrieCacheGen),
 	}
+	BloomFilterSizeFlag = cli.Uint64Flag{
+		Name:  "bloomfilter.size",
+		Usage: "Megabytes of memory allocated to bloom-filter for pruning",
+		Value: 2048,
+	}
 	// Miner settings
 	MiningEnabledFlag = cli.BoolFlag{
 		Name:  "mine",
@@ -387,6 +419,11 @@ var (
 		Usage: "API's offered over the HTTP-RPC interface",
 		Value: "",
 	}
+	RPCVirtualHostsFlag = cli.StringFlag{
+		Name:  "rpcvhosts",
+		Usage: "Comma separated list of virtual hostnames from which to accept requests (server enforced). Accepts '*' wildcard.",
+		Value: strings.Join(node.DefaultConfig.HTTPVirtualHosts, ","),
+	}
 	IPCDisabledFlag = cli.BoolFlag{
 		Name:  "ipcdisable",
 		Usage: "Disable the IPC-RPC server",
@@ -419,6 +456,64 @@ var (
 		Usage: "Origins from which to accept websockets requests",
 		Value: "",
 	}
+	GraphQLEnabledFlag = cli.BoolFlag{
+		Name:  "graphql",
+		Usage: "Enable the GraphQL server",
+	}
+	GraphQLListenAddrFlag = cli.StringFlag{
+		Name:  "graphqladdr",
+		Usage: "GraphQL server listening interface",
+		Value: node.DefaultGraphQLHost,
+	}
+	GraphQLPortFlag = cli.IntFlag{
+		Name:  "graphqlport",
+		Usage: "GraphQL server listening port",
+		Value: node.DefaultGraphQLPort,
+	}
+	GraphQLCORSDomainFlag = cli.StringFlag{
+		Name:  "graphqlcorsdomain",
+		Usage: "Comma separated list of domains from which to accept cross origin requests (browser enforced)",
+		Value: "",
+	}
+	GraphQLVirtualHostsFlag = cli.StringFlag{
+		Name:  "graphqlvhosts",
+		Usage: "Comma separated list of virtual hostnames from which to accept requests (server enforced). Accepts '*' wildcard.",
+		Value: strings.Join(node.DefaultConfig.GraphQLVirtualHosts, ","),
+	}
+	RPCJWTSecretFlag = cli.StringFlag{
+		Name:  "rpcjwtsecret",
+		Usage: "File containing the hex encoded secret to verify HS256 JWT bearer tokens on the HTTP-RPC and WS-RPC interfaces",
+		Value: "",
+	}
+	RPCJWTPublicFlag = cli.StringFlag{
+		Name:  "rpcjwtpublic",
+		Usage: "API's and methods callable without a JWT bearer token (e.g. rue,net_version)",
+		Value: "",
+	}
+	RPCRateLimitFlag = cli.Float64Flag{
+		Name:  "rpcratelimit",
+		Usage: "Maximum HTTP-RPC and WS-RPC requests per second per client (0 = unlimited)",
+	}
+	RPCRateBurstFlag = cli.IntFlag{
+		Name:  "rpcrateburst",
+		Usage: "Maximum HTTP-RPC and WS-RPC requests a client may make at once (0 = rate limit)",
+	}
+	RPCBatchLimitFlag = cli.IntFlag{
+		Name:  "rpcbatchlimit",
+		Usage: "Maximum number of requests in an RPC batch (0 = unlimited)",
+	}
+	RPCResponseLimitFlag = cli.IntFlag{
+		Name:  "rpcresponselimit",
+		Usage: "Maximum size of an RPC call's result in bytes (0 = unlimited)",
+	}
+	RPCTimeoutFlag = cli.DurationFlag{
+		Name:  "rpctimeout",
+		Usage: "Maximum execution time of an RPC call (0 = unlimited)",
+	}
+	RPCSubscriptionLimitFlag = cli.IntFlag{
+		Name:  "rpcsublimit",
+		Usage: "Maximum number of concurrent subscriptions per WS-RPC connection (0 = unlimited)",
+	}
 	ExecFlag = cli.StringFlag{
 		Name:  "exec",
 		Usage: "Execute JavaScript statement",
@@ -683,6 +778,9 @@ func setHTTP(ctx *cli.Context, cfg *node.Config) {
 	if ctx.GlobalIsSet(RPCApiFlag.Name) {
 		cfg.HTTPModules = splitAndTrim(ctx.GlobalString(RPCApiFlag.Name))
 	}
+	if ctx.GlobalIsSet(RPCVirtualHostsFlag.Name) {
+		cfg.HTTPVirtualHosts = splitAndTrim(ctx.GlobalString(RPCVirtualHostsFlag.Name))
+	}
 }
 
 // setWS creates the WebSocket RPC listener interface string from the set
@@ -706,6 +804,61 @@ func setWS(ctx *cli.Context, cfg *node.Config) {
 	}
 }
 
+// setGraphQL creates the GraphQL listener interface string from the set
+// command line flags, returning empty if the GraphQL endpoint is disabled.
+func setGraphQL(ctx *cli.Context, cfg *node.Config) {
+	if ctx.GlobalBool(GraphQLEnabledFlag.Name) && cfg.GraphQLHost == "" {
+		cfg.GraphQLHost = "127.0.0.1"
+		if ctx.GlobalIsSet(GraphQLListenAddrFlag.Name) {
+			cfg.GraphQLHost = ctx.GlobalString(GraphQLListenAddrFlag.Name)
+		}
+	}
+
+	if ctx.GlobalIsSet(GraphQLPortFlag.Name) {
+		cfg.GraphQLPort = ctx.GlobalInt(GraphQLPortFlag.Name)
+	}
+	if ctx.GlobalIsSet(GraphQLCORSDomainFlag.Name) {
+		cfg.GraphQLCors = splitAndTrim(ctx.GlobalString(GraphQLCORSDomainFlag.Name))
+	}
+	if ctx.GlobalIsSet(GraphQLVirtualHostsFlag.Name) {
+		cfg.GraphQLVirtualHosts = splitAndTrim(ctx.GlobalString(GraphQLVirtualHostsFlag.Name))
+	}
+}
+
+// setJWT creates the RPC bearer token authentication configuration from the set
+// command line flags.
+func setJWT(ctx *cli.Context, cfg *node.Config) {
+	if ctx.GlobalIsSet(RPCJWTSecretFlag.Name) {
+		cfg.JWTSecret = ctx.GlobalString(RPCJWTSecretFlag.Name)
+	}
+	if ctx.GlobalIsSet(RPCJWTPublicFlag.Name) {
+		cfg.JWTPublic = splitAndTrim(ctx.GlobalString(RPCJWTPublicFlag.Name))
+	}
+}
+
+// setRPCLimits creates the RPC rate limit and quota configuration from the set
+// command line flags.
+func setRPCLimits(ctx *cli.Context, cfg *node.Config) {
+	if ctx.GlobalIsSet(RPCRateLimitFlag.Name) {
+		cfg.RPCLimits.RequestRate = ctx.GlobalFloat64(RPCRateLimitFlag.Name)
+	}
+	if ctx.GlobalIsSet(RPCRateBurstFlag.Name) {
+		cfg.RPCLimits.RequestBurst = ctx.GlobalInt(RPCRateBurstFlag.Name)
+	}
+	if ctx.GlobalIsSet(RPCBatchLimitFlag.Name) {
+		cfg.RPCLimits.BatchSize = ctx.GlobalInt(RPCBatchLimitFlag.Name)
+	}
+	if ctx.GlobalIsSet(RPCResponseLimitFlag.Name) {
+		cfg.RPCLimits.ResponseSize = ctx.GlobalInt(RPCResponseLimitFlag.Name)
+	}
+	if ctx.GlobalIsSet(RPCTimeoutFlag.Name) {
+		cfg.RPCLimits.Timeout = ctx.GlobalDuration(RPCTimeoutFlag.Name)
+	}
+	if ctx.GlobalIsSet(RPCSubscriptionLimitFlag.Name) {
+		cfg.RPCLimits.Subscriptions = ctx.GlobalInt(RPCSubscriptionLimitFlag.Name)
+	}
+}
+
 // setIPC creates an IPC path configuration from the set command line flags,
 // returning an empty string if IPC was explicitly disabled, or the set path.
 func setIPC(ctx *cli.Context, cfg *node.Config) {
@@ -841,6 +994,9 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 	setIPC(ctx, cfg)
 	setHTTP(ctx, cfg)
 	setWS(ctx, cfg)
+	setGraphQL(ctx, cfg)
+	setJWT(ctx, cfg)
+	setRPCLimits(ctx, cfg)
 	setNodeUserIdent(ctx, cfg)
 
 	switch {
@@ -854,6 +1010,9 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 		cfg.DataDir = filepath.Join(node.DefaultDataDir(), "rinkeby")
 	}
 
+	if ctx.GlobalIsSet(DBEngineFlag.Name) {
+		cfg.DBEngine = ctx.GlobalString(DBEngineFlag.Name)
+	}
 	if ctx.GlobalIsSet(KeyStoreDirFlag.Name) {
 		cfg.KeyStoreDir = ctx.GlobalString(KeyStoreDirFlag.Name)
 	}
@@ -884,6 +1043,9 @@ func setTxPool(ctx *cli.Context, cfg *core.TxPoolConfig) {
 	if ctx.GlobalIsSet(TxPoolRejournalFlag.Name) {
 		cfg.Rejournal = ctx.GlobalDuration(TxPoolRejournalFlag.Name)
 	}
+	if ctx.GlobalIsSet(TxPoolJournalRemotesFlag.Name) {
+		cfg.JournalRemotes = ctx.GlobalBool(TxPoolJournalRemotesFlag.Name)
+	}
 	if ctx.GlobalIsSet(TxPoolPriceLimitFlag.Name) {
 		cfg.PriceLimit = ctx.GlobalUint64(TxPoolPriceLimitFlag.Name)
 	}
@@ -976,6 +1138,19 @@ func SetShhConfig(ctx *cli.Context, stack *node.Node, cfg *whisper.Config) {
 	}
 }
 
+// isArchiveMode validates the garbage collection mode requested on the command
+// line and returns whether it asks for an archive node retaining all state.
+func isArchiveMode(ctx *cli.Context) bool {
+	switch ctx.GlobalString(GCModeFlag.Name) {
+	case "full":
+		return false
+	case "archive":
+		return true
+	}
+	Fatalf("--%s must be either 'full' or 'archive'", GCModeFlag.Name)
+	return false
+}
+
 // SetEthConfig applies rue-related command line flags to the config.
 func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *rue.Config) {
 	// Avoid conflicting network flags
@@ -1007,11 +1182,17 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *rue.Config) {
 	if ctx.GlobalIsSet(NetworkIdFlag.Name) {
 		cfg.NetworkId = ctx.GlobalUint64(NetworkIdFlag.Name)
 	}
+	cfg.NoPruning = isArchiveMode(ctx)
+	cfg.LogIndex = ctx.GlobalBool(LogIndexFlag.Name)
+	cfg.AddressIndex = ctx.GlobalBool(AddressIndexFlag.Name)
 
 	if ctx.GlobalIsSet(CacheFlag.Name) {
 		cfg.DatabaseCache = ctx.GlobalInt(CacheFlag.Name)
 	}
 	cfg.DatabaseHandles = makeDatabaseHandles()
+	if ctx.GlobalIsSet(AncientFlag.Name) {
+		cfg.DatabaseFreezer = ctx.GlobalString(AncientFlag.Name)
+	}
 
 	if ctx.GlobalIsSet(MinerThreadsFlag.Name) {
 		cfg.MinerThreads = ctx.GlobalInt(MinerThreadsFlag.Name)
@@ -1135,6 +1316,27 @@ func RegisterEthStatsService(stack *node.Node, url string) {
 	}
 }
 
+// RegisterGraphQLService adds a GraphQL endpoint serving the chain data of the
+// full or light Ruereum service to the given node.
+func RegisterGraphQLService(stack *node.Node, endpoint string, cors, vhosts []string) {
+	if err := stack.Register(func(ctx *node.ServiceContext) (node.Service, error) {
+		// Try to construct the GraphQL service backed by a full node
+		var rueServ *rue.Ruereum
+		if err := ctx.Service(&rueServ); err == nil {
+			return graphql.New(rueServ.ApiBackend, endpoint, cors, vhosts)
+		}
+		// Try to construct the GraphQL service backed by a light node
+		var lesServ *les.LightRuereum
+		if err := ctx.Service(&lesServ); err == nil {
+			return graphql.New(lesServ.ApiBackend, endpoint, cors, vhosts)
+		}
+		// Well, this should not have happened, bail out
+		return nil, errors.New("no Ruereum service")
+	}); err != nil {
+		Fatalf("Failed to register the GraphQL service: %v", err)
+	}
+}
+
 // SetupNetwork configures the system for either the main net or some test network.
 func SetupNetwork(ctx *cli.Context) {
 	// TODO(fjl): move target gas limit into config
@@ -1155,6 +1357,20 @@ func MakeChainDatabase(ctx *cli.Context, stack *node.Node) ruedb.Database {
 	if err != nil {
 		Fatalf("Could not open database: %v", err)
 	}
+	// Attach the ancient store to full node databases. Offline commands only access
+	// it, migrating new blocks into it is left to the running node.
+	if dir := stack.ResolvePath(name); dir != "" && !ctx.GlobalBool(LightModeFlag.Name) {
+		freezer := ctx.GlobalString(AncientFlag.Name)
+		switch {
+		case freezer == "":
+			freezer = filepath.Join(dir, "ancient")
+		case !filepath.IsAbs(freezer):
+			freezer = stack.ResolvePath(freezer)
+		}
+		if chainDb, err = core.NewDatabaseWithFreezer(chainDb, freezer, "rue/db/chaindata/", core.ImmutabilityThreshold, false); err != nil {
+			Fatalf("Could not open ancient database: %v", err)
+		}
+	}
 	return chainDb
 }
 
@@ -1196,8 +1412,13 @@ func MakeChain(ctx *cli.Context, stack *node.Node) (chain *core.BlockChain, chai
 			})
 		}
 	}
+	cache := &core.CacheConfig{
+		Disabled:      isArchiveMode(ctx),
+		TrieNodeLimit: rue.DefaultConfig.TrieCache,
+		TrieTimeLimit: rue.DefaultConfig.TrieTimeout,
+	}
 	vmcfg := vm.Config{EnablePreimageRecording: ctx.GlobalBool(VMEnableDebugFlag.Name)}
-	chain, err = core.NewBlockChain(chainDb, config, engine, vmcfg)
+	chain, err = core.NewBlockChain(chainDb, cache, config, engine, vmcfg)
 	if err != nil {
 		Fatalf("Can't create BlockChain: %v", err)
 	}
@@ -0,0 +1,189 @@
+// Copyright 2018 The go-ruereum Authors
+// This file is part of the go-ruereum library.
+//
+// The go-ruereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ruereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ruereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/Rue-Foundation/go-rue/common"
+	"github.com/Rue-Foundation/go-rue/log"
+	"github.com/Rue-Foundation/go-rue/ruedb"
+)
+
+// Key prefixes of the light client's trie indexes, defined in package light.
+var (
+	chtRootPrefix       = []byte("chtRoot-")
+	chtTablePrefix      = []byte("cht-")
+	chtIndexPrefix      = []byte("chtIndex-")
+	bloomTrieRootPrefix = []byte("bltRoot-")
+	bloomTrieTable      = []byte("blt-")
+	bloomTrieIndex      = []byte("bltIndex-")
+)
+
+// Categories of database entries reported by InspectDatabase.
+const (
+	statHeaders         = "Headers"
+	statBodies          = "Bodies"
+	statReceipts        = "Receipts"
+	statDifficulties    = "Difficulties"
+	statCanonicalHashes = "Canonical hashes"
+	statNumberLookups   = "Block number lookups"
+	statTxLookups       = "Transaction lookups"
+	statBloomBits       = "Bloom bits"
+	statLogIndex        = "Log index"
+	statAddrTxIndex     = "Address transaction index"
+	statTrieNodes       = "Trie nodes"
+	statContractCodes   = "Contract codes"
+	statPreimages       = "Trie preimages"
+	statIndexerMeta     = "Chain indexer metadata"
+	statCHT             = "Light client CHT"
+	statBloomTrie       = "Light client bloom trie"
+	statClique          = "Clique snapshots"
+	statMetadata        = "Singleton metadata"
+	statUnaccounted     = "Unaccounted"
+)
+
+// inspectOrder is the order in which the key-value store categories are listed.
+var inspectOrder = []string{
+	statHeaders, statBodies, statReceipts, statDifficulties, statCanonicalHashes,
+	statNumberLookups, statTxLookups, statBloomBits, statLogIndex, statAddrTxIndex,
+	statTrieNodes, statContractCodes, statPreimages, statIndexerMeta, statCHT,
+	statBloomTrie, statClique, statMetadata, statUnaccounted,
+}
+
+// metadataKeys are the singleton entries of the database.
+var metadataKeys = [][]byte{
+	headHeaderKey, headBlockKey, headFastKey, []byte("BlockchainVersion"), []byte("_requestCostStats"),
+}
+
+// DatabaseStat is the storage accounting of a category of database entries.
+type DatabaseStat struct {
+	Database string `json:"database"` // Store holding the entries, key-value or ancient
+	Category string `json:"category"` // Kind of data held by the entries
+	Count    uint64 `json:"count"`    // Number of entries
+	Size     uint64 `json:"size"`     // Total size of the entries' keys and values in bytes
+}
+
+// InspectDatabase iterates over the entire key-value store of the database,
+// classifying each entry by its key, and accounts for the data moved into the
+// ancient store too. The returned statistics hold one entry per category.
+func InspectDatabase(db ruedb.Database) ([]*DatabaseStat, error) {
+	var (
+		stats  = make(map[string]*DatabaseStat)
+		count  uint64
+		start  = time.Now()
+		logged = time.Now()
+	)
+	for _, category := range inspectOrder {
+		stats[category] = &DatabaseStat{Database: "Key-Value store", Category: category}
+	}
+	it := KeyValueStore(db).NewIterator(nil, nil)
+	defer it.Release()
+
+	for it.Next() {
+		key, value := it.Key(), it.Value()
+
+		stat := stats[classifyKey(key, value)]
+		stat.Count++
+		stat.Size += uint64(len(key) + len(value))
+
+		if count++; time.Since(logged) > 8*time.Second {
+			log.Info("Inspecting database", "count", count, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+	result := make([]*DatabaseStat, 0, len(inspectOrder))
+	for _, category := range inspectOrder {
+		result = append(result, stats[category])
+	}
+	// Account for the ancient store, if the database has one attached
+	if ancients, ok := db.(AncientReader); ok {
+		frozen, err := ancients.Ancients()
+		if err != nil {
+			return nil, err
+		}
+		for _, table := range []struct{ kind, category string }{
+			{freezerHeaderTable, statHeaders},
+			{freezerBodiesTable, statBodies},
+			{freezerReceiptTable, statReceipts},
+			{freezerDifficultyTable, statDifficulties},
+			{freezerHashTable, statCanonicalHashes},
+		} {
+			size, err := ancients.AncientSize(table.kind)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, &DatabaseStat{Database: "Ancient store", Category: table.category, Count: frozen, Size: size})
+		}
+	}
+	return result, nil
+}
+
+// classifyKey returns the category of a key-value store entry.
+func classifyKey(key, value []byte) string {
+	switch {
+	case bytes.HasPrefix(key, headerPrefix) && len(key) == len(headerPrefix)+8+common.HashLength:
+		return statHeaders
+	case bytes.HasPrefix(key, headerPrefix) && len(key) == len(headerPrefix)+8+common.HashLength+len(tdSuffix) && bytes.HasSuffix(key, tdSuffix):
+		return statDifficulties
+	case bytes.HasPrefix(key, headerPrefix) && len(key) == len(headerPrefix)+8+len(numSuffix) && bytes.HasSuffix(key, numSuffix):
+		return statCanonicalHashes
+	case bytes.HasPrefix(key, blockHashPrefix) && len(key) == len(blockHashPrefix)+common.HashLength:
+		return statNumberLookups
+	case bytes.HasPrefix(key, bodyPrefix) && len(key) == len(bodyPrefix)+8+common.HashLength:
+		return statBodies
+	case bytes.HasPrefix(key, blockReceiptsPrefix) && len(key) == len(blockReceiptsPrefix)+8+common.HashLength:
+		return statReceipts
+	case bytes.HasPrefix(key, lookupPrefix) && len(key) == len(lookupPrefix)+common.HashLength:
+		return statTxLookups
+	case bytes.HasPrefix(key, bloomBitsPrefix) && len(key) == len(bloomBitsPrefix)+2+8+common.HashLength:
+		return statBloomBits
+	case bytes.HasPrefix(key, logIndexPrefix):
+		return statLogIndex
+	case bytes.HasPrefix(key, addrTxIndexPrefix):
+		return statAddrTxIndex
+	case bytes.HasPrefix(key, []byte(preimagePrefix)) && len(key) == len(preimagePrefix)+common.HashLength:
+		return statPreimages
+	case bytes.HasPrefix(key, BloomBitsIndexPrefix), bytes.HasPrefix(key, LogIndexPrefix), bytes.HasPrefix(key, AddrTxIndexPrefix):
+		return statIndexerMeta
+	case bytes.HasPrefix(key, chtRootPrefix), bytes.HasPrefix(key, chtTablePrefix), bytes.HasPrefix(key, chtIndexPrefix):
+		return statCHT
+	case bytes.HasPrefix(key, bloomTrieRootPrefix), bytes.HasPrefix(key, bloomTrieTable), bytes.HasPrefix(key, bloomTrieIndex):
+		return statBloomTrie
+	case bytes.HasPrefix(key, []byte("clique-")) && len(key) == len("clique-")+common.HashLength:
+		return statClique
+	case bytes.HasPrefix(key, configPrefix):
+		return statMetadata
+	case len(key) == common.HashLength:
+		// Trie nodes and contract codes share the hash keyspace, but nodes are
+		// always RLP lists while code practically never starts with such a byte
+		if len(value) > 0 && value[0] >= 0xc0 {
+			return statTrieNodes
+		}
+		return statContractCodes
+	}
+	for _, meta := range metadataKeys {
+		if bytes.Equal(key, meta) {
+			return statMetadata
+		}
+	}
+	return statUnaccounted
+}
@@ -0,0 +1,66 @@
+// Copyright 2018 The go-ruereum Authors
+// This file is part of the go-ruereum library.
+//
+// The go-ruereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ruereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ruereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/Rue-Foundation/go-rue/common"
+	"github.com/Rue-Foundation/go-rue/core/types"
+	"github.com/Rue-Foundation/go-rue/ruedb"
+)
+
+// Tests that database inspection classifies the entries of the key-value store
+// into the correct categories.
+func TestInspectDatabase(t *testing.T) {
+	db, _ := ruedb.NewMemDatabase()
+
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1), Extra: []byte("test block")})
+	WriteBlock(db, block)
+	WriteTd(db, block.Hash(), 1, big.NewInt(42))
+	WriteCanonicalHash(db, block.Hash(), 1)
+	WriteHeadBlockHash(db, block.Hash())
+
+	db.Put(common.Hash{0x01}.Bytes(), []byte{0xc2, 0x01, 0x02}) // trie node
+	db.Put(common.Hash{0x02}.Bytes(), []byte{0x60, 0x60})       // contract code
+	db.Put([]byte("unknown"), []byte{0x01})
+
+	stats, err := InspectDatabase(db)
+	if err != nil {
+		t.Fatalf("failed to inspect database: %v", err)
+	}
+	counts := make(map[string]uint64)
+	for _, stat := range stats {
+		counts[stat.Category] += stat.Count
+	}
+	for category, want := range map[string]uint64{
+		statHeaders:         1,
+		statBodies:          1,
+		statDifficulties:    1,
+		statCanonicalHashes: 1,
+		statNumberLookups:   1,
+		statTrieNodes:       1,
+		statContractCodes:   1,
+		statMetadata:        1,
+		statUnaccounted:     1,
+	} {
+		if counts[category] != want {
+			t.Errorf("%s count mismatch: have %d, want %d", category, counts[category], want)
+		}
+	}
+}
@@ -26,6 +26,7 @@ import (
 
 	"github.com/Rue-Foundation/go-rue/common"
 	"github.com/Rue-Foundation/go-rue/core/types"
+	"github.com/Rue-Foundation/go-rue/crypto"
 	"github.com/Rue-Foundation/go-rue/ruedb"
 	"github.com/Rue-Foundation/go-rue/log"
 	"github.com/Rue-Foundation/go-rue/metrics"
@@ -43,6 +44,36 @@ type DatabaseDeleter interface {
 	Delete(key []byte) error
 }
 
+// AncientReader wraps the read methods of an immutable ancient data store, into
+// which old canonical blocks are moved out of the key-value store.
+type AncientReader interface {
+	// HasAncient returns an indicator whether the specified data exists in the
+	// ancient store.
+	HasAncient(kind string, number uint64) (bool, error)
+
+	// Ancient retrieves an ancient binary blob from the append-only immutable files.
+	Ancient(kind string, number uint64) ([]byte, error)
+
+	// Ancients returns the number of blocks held in the ancient store.
+	Ancients() (uint64, error)
+
+	// AncientSize returns the ancient size of the specified category.
+	AncientSize(kind string) (uint64, error)
+}
+
+// AncientWriter wraps the write methods of an immutable ancient data store.
+type AncientWriter interface {
+	// AppendAncient injects all binary blobs belonging to a block at the end of
+	// the append-only immutable table files.
+	AppendAncient(number uint64, hash, header, body, receipts, td []byte) error
+
+	// TruncateAncients discards all but the first n ancient blocks.
+	TruncateAncients(n uint64) error
+
+	// Sync flushes all in-memory ancient data to disk.
+	Sync() error
+}
+
 var (
 	headHeaderKey = []byte("LastHeader")
 	headBlockKey  = []byte("LastBlock")
@@ -57,12 +88,16 @@ var (
 	blockReceiptsPrefix = []byte("r") // blockReceiptsPrefix + num (uint64 big endian) + hash -> block receipts
 	lookupPrefix        = []byte("l") // lookupPrefix + hash -> transaction/receipt lookup metadata
 	bloomBitsPrefix     = []byte("B") // bloomBitsPrefix + bit (uint16 big endian) + section (uint64 big endian) + hash -> bloom bits
+	logIndexPrefix      = []byte("g") // logIndexPrefix + section (uint64 big endian) + hash + key -> log positions
+	addrTxIndexPrefix   = []byte("A") // addrTxIndexPrefix + section (uint64 big endian) + hash + address -> transaction positions
 
 	preimagePrefix = "secure-key-"              // preimagePrefix + hash -> preimage
 	configPrefix   = []byte("ruereum-config-") // config prefix for the db
 
 	// Chain index prefixes (use `i` + single byte to avoid mixing data types).
 	BloomBitsIndexPrefix = []byte("iB") // BloomBitsIndexPrefix is the data table of a chain indexer to track its progress
+	LogIndexPrefix       = []byte("iL") // LogIndexPrefix is the data table of the log indexer to track its progress
+	AddrTxIndexPrefix    = []byte("iA") // AddrTxIndexPrefix is the data table of the address transaction indexer to track its progress
 
 	// used by old db, now only used for conversion
 	oldReceiptsPrefix = []byte("receipts-")
@@ -82,6 +117,51 @@ type TxLookupEntry struct {
 	Index      uint64
 }
 
+// LogPosition is the location of a single log event within the canonical chain,
+// as recorded by the log index.
+type LogPosition struct {
+	Block    uint64 // Number of the block containing the log
+	TxIndex  uint32 // Index of the transaction within the block
+	LogIndex uint32 // Index of the log within the block
+}
+
+// LogIndexAddressKey returns the log index key tracking all logs emitted by the
+// given contract address.
+func LogIndexAddressKey(address common.Address) []byte {
+	return append([]byte{'a'}, address.Bytes()...)
+}
+
+// LogIndexTopicKey returns the log index key tracking all logs containing the
+// given topic at the given position.
+func LogIndexTopicKey(position int, topic common.Hash) []byte {
+	return append([]byte{'t', byte(position)}, topic.Bytes()...)
+}
+
+// TxPosition is the location of a single transaction within the canonical chain,
+// as recorded by the address transaction index.
+type TxPosition struct {
+	Block uint64 // Number of the block containing the transaction
+	Index uint32 // Index of the transaction within the block
+}
+
+// TxAddresses returns the addresses a transaction is indexed under in the address
+// transaction index: its sender and either its recipient or the contract created.
+func TxAddresses(signer types.Signer, tx *types.Transaction) ([]common.Address, error) {
+	from, err := types.Sender(signer, tx)
+	if err != nil {
+		return nil, err
+	}
+	to := tx.To()
+	if to == nil {
+		created := crypto.CreateAddress(from, tx.Nonce())
+		to = &created
+	}
+	if *to == from {
+		return []common.Address{from}, nil
+	}
+	return []common.Address{from, *to}, nil
+}
+
 // encodeBlockNumber encodes a block number as big endian uint64
 func encodeBlockNumber(number uint64) []byte {
 	enc := make([]byte, 8)
@@ -89,8 +169,28 @@ func encodeBlockNumber(number uint64) []byte {
 	return enc
 }
 
+// readAncient retrieves an item of the given kind belonging to a block from the
+// ancient store, if the database has one attached and the block was frozen.
+func readAncient(db DatabaseReader, kind string, hash common.Hash, number uint64) []byte {
+	adb, ok := db.(AncientReader)
+	if !ok {
+		return nil
+	}
+	// Only canonical blocks are frozen, make sure the hash matches
+	if data, _ := adb.Ancient(freezerHashTable, number); !bytes.Equal(data, hash[:]) {
+		return nil
+	}
+	data, _ := adb.Ancient(kind, number)
+	return data
+}
+
 // GetCanonicalHash retrieves a hash assigned to a canonical block number.
 func GetCanonicalHash(db DatabaseReader, number uint64) common.Hash {
+	if adb, ok := db.(AncientReader); ok {
+		if data, _ := adb.Ancient(freezerHashTable, number); len(data) > 0 {
+			return common.BytesToHash(data)
+		}
+	}
 	data, _ := db.Get(append(append(headerPrefix, encodeBlockNumber(number)...), numSuffix...))
 	if len(data) == 0 {
 		return common.Hash{}
@@ -98,6 +198,23 @@ func GetCanonicalHash(db DatabaseReader, number uint64) common.Hash {
 	return common.BytesToHash(data)
 }
 
+// getAllHashes retrieves the hashes of all headers, canonical or not, stored in
+// the key-value store at a certain block number.
+func getAllHashes(db ruedb.Database, number uint64) []common.Hash {
+	prefix := append(headerPrefix, encodeBlockNumber(number)...)
+
+	it := db.NewIterator(prefix, nil)
+	defer it.Release()
+
+	var hashes []common.Hash
+	for it.Next() {
+		if key := it.Key(); len(key) == len(prefix)+common.HashLength {
+			hashes = append(hashes, common.BytesToHash(key[len(prefix):]))
+		}
+	}
+	return hashes
+}
+
 // missingNumber is returned by GetBlockNumber if no header with the
 // given block hash has been stored in the database
 const missingNumber = uint64(0xffffffffffffffff)
@@ -149,10 +266,24 @@ func GetHeadFastBlockHash(db DatabaseReader) common.Hash {
 // GetHeaderRLP retrieves a block header in its raw RLP database encoding, or nil
 // if the header's not found.
 func GetHeaderRLP(db DatabaseReader, hash common.Hash, number uint64) rlp.RawValue {
+	if data := readAncient(db, freezerHeaderTable, hash, number); len(data) > 0 {
+		return data
+	}
 	data, _ := db.Get(headerKey(hash, number))
 	return data
 }
 
+// HasHeader verifies the existence of a block header corresponding to the hash.
+func HasHeader(db ruedb.Database, hash common.Hash, number uint64) bool {
+	if adb, ok := db.(AncientReader); ok {
+		if data, _ := adb.Ancient(freezerHashTable, number); bytes.Equal(data, hash[:]) {
+			return true
+		}
+	}
+	ok, _ := db.Has(headerKey(hash, number))
+	return ok
+}
+
 // GetHeader retrieves the block header corresponding to the hash, nil if none
 // found.
 func GetHeader(db DatabaseReader, hash common.Hash, number uint64) *types.Header {
@@ -170,10 +301,24 @@ func GetHeader(db DatabaseReader, hash common.Hash, number uint64) *types.Header
 
 // GetBodyRLP retrieves the block body (transactions and uncles) in RLP encoding.
 func GetBodyRLP(db DatabaseReader, hash common.Hash, number uint64) rlp.RawValue {
+	if data := readAncient(db, freezerBodiesTable, hash, number); len(data) > 0 {
+		return data
+	}
 	data, _ := db.Get(blockBodyKey(hash, number))
 	return data
 }
 
+// HasBody verifies the existence of a block body corresponding to the hash.
+func HasBody(db ruedb.Database, hash common.Hash, number uint64) bool {
+	if adb, ok := db.(AncientReader); ok {
+		if data, _ := adb.Ancient(freezerHashTable, number); bytes.Equal(data, hash[:]) {
+			return true
+		}
+	}
+	ok, _ := db.Has(blockBodyKey(hash, number))
+	return ok
+}
+
 func headerKey(hash common.Hash, number uint64) []byte {
 	return append(append(headerPrefix, encodeBlockNumber(number)...), hash.Bytes()...)
 }
@@ -182,6 +327,14 @@ func blockBodyKey(hash common.Hash, number uint64) []byte {
 	return append(append(bodyPrefix, encodeBlockNumber(number)...), hash.Bytes()...)
 }
 
+func tdKey(hash common.Hash, number uint64) []byte {
+	return append(headerKey(hash, number), tdSuffix...)
+}
+
+func blockReceiptsKey(hash common.Hash, number uint64) []byte {
+	return append(append(blockReceiptsPrefix, encodeBlockNumber(number)...), hash.Bytes()...)
+}
+
 // GetBody retrieves the block body (transactons, uncles) corresponding to the
 // hash, nil if none found.
 func GetBody(db DatabaseReader, hash common.Hash, number uint64) *types.Body {
@@ -200,7 +353,10 @@ func GetBody(db DatabaseReader, hash common.Hash, number uint64) *types.Body {
 // GetTd retrieves a block's total difficulty corresponding to the hash, nil if
 // none found.
 func GetTd(db DatabaseReader, hash common.Hash, number uint64) *big.Int {
-	data, _ := db.Get(append(append(append(headerPrefix, encodeBlockNumber(number)...), hash[:]...), tdSuffix...))
+	data := readAncient(db, freezerDifficultyTable, hash, number)
+	if len(data) == 0 {
+		data, _ = db.Get(tdKey(hash, number))
+	}
 	if len(data) == 0 {
 		return nil
 	}
@@ -235,7 +391,10 @@ func GetBlock(db DatabaseReader, hash common.Hash, number uint64) *types.Block {
 // GetBlockReceipts retrieves the receipts generated by the transactions included
 // in a block given by its hash.
 func GetBlockReceipts(db DatabaseReader, hash common.Hash, number uint64) types.Receipts {
-	data, _ := db.Get(append(append(blockReceiptsPrefix, encodeBlockNumber(number)...), hash[:]...))
+	data := readAncient(db, freezerReceiptTable, hash, number)
+	if len(data) == 0 {
+		data, _ = db.Get(blockReceiptsKey(hash, number))
+	}
 	if len(data) == 0 {
 		return nil
 	}
@@ -341,6 +500,44 @@ func GetBloomBits(db DatabaseReader, bit uint, section uint64, head common.Hash)
 	return db.Get(key)
 }
 
+// GetLogIndex retrieves the positions of all the logs tracked under the given
+// index key within a log index section, or nil if no such logs exist.
+func GetLogIndex(db DatabaseReader, key []byte, section uint64, head common.Hash) ([]LogPosition, error) {
+	data, _ := db.Get(logIndexKey(key, section, head))
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var positions []LogPosition
+	if err := rlp.DecodeBytes(data, &positions); err != nil {
+		return nil, err
+	}
+	return positions, nil
+}
+
+// GetAddrTxIndex retrieves the positions of all the transactions touching the
+// given address within an address transaction index section, or nil if none.
+func GetAddrTxIndex(db DatabaseReader, address common.Address, section uint64, head common.Hash) ([]TxPosition, error) {
+	data, _ := db.Get(addrTxIndexKey(address, section, head))
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var positions []TxPosition
+	if err := rlp.DecodeBytes(data, &positions); err != nil {
+		return nil, err
+	}
+	return positions, nil
+}
+
+// addrTxIndexKey = addrTxIndexPrefix + section (uint64 big endian) + head + address
+func addrTxIndexKey(address common.Address, section uint64, head common.Hash) []byte {
+	return append(append(append(addrTxIndexPrefix, encodeBlockNumber(section)...), head.Bytes()...), address.Bytes()...)
+}
+
+// logIndexKey = logIndexPrefix + section (uint64 big endian) + head + key
+func logIndexKey(key []byte, section uint64, head common.Hash) []byte {
+	return append(append(append(logIndexPrefix, encodeBlockNumber(section)...), head.Bytes()...), key...)
+}
+
 // WriteCanonicalHash stores the canonical hash for the given block number.
 func WriteCanonicalHash(db ruedb.Putter, hash common.Hash, number uint64) error {
 	key := append(append(headerPrefix, encodeBlockNumber(number)...), numSuffix...)
@@ -493,6 +690,32 @@ func WriteBloomBits(db ruedb.Putter, bit uint, section uint64, head common.Hash,
 	}
 }
 
+// WriteLogIndex stores the positions of all the logs tracked under the given
+// index key within a log index section.
+func WriteLogIndex(db ruedb.Putter, key []byte, section uint64, head common.Hash, positions []LogPosition) error {
+	data, err := rlp.EncodeToBytes(positions)
+	if err != nil {
+		return err
+	}
+	if err := db.Put(logIndexKey(key, section, head), data); err != nil {
+		log.Crit("Failed to store log index", "err", err)
+	}
+	return nil
+}
+
+// WriteAddrTxIndex stores the positions of all the transactions touching the
+// given address within an address transaction index section.
+func WriteAddrTxIndex(db ruedb.Putter, address common.Address, section uint64, head common.Hash, positions []TxPosition) error {
+	data, err := rlp.EncodeToBytes(positions)
+	if err != nil {
+		return err
+	}
+	if err := db.Put(addrTxIndexKey(address, section, head), data); err != nil {
+		log.Crit("Failed to store address transaction index", "err", err)
+	}
+	return nil
+}
+
 // DeleteCanonicalHash removes the number to hash canonical mapping.
 func DeleteCanonicalHash(db DatabaseDeleter, number uint64) {
 	db.Delete(append(append(headerPrefix, encodeBlockNumber(number)...), numSuffix...))
@@ -22,7 +22,18 @@ import (
 )
 
 // TxPreEvent is posted when a transaction enters the transaction pool.
-type TxPreEvent struct{ Tx *types.Transaction }
+type TxPreEvent struct {
+	Tx      *types.Transaction
+	Private bool // Whether the transaction must not be propagated to the network
+}
+
+// TxDropEvent is posted when a transaction is removed from the transaction pool
+// without having been included in a block.
+type TxDropEvent struct {
+	Tx          *types.Transaction
+	Reason      TxDropReason
+	Replacement common.Hash // Transaction superseding the dropped one, if replaced
+}
 
 // PendingLogsEvent is posted pre mining and notifies of pending logs.
 type PendingLogsEvent struct {
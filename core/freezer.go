@@ -0,0 +1,445 @@
+// Copyright 2018 The go-ruereum Authors
+// This file is part of the go-ruereum library.
+//
+// The go-ruereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ruereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ruereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Rue-Foundation/go-rue/common"
+	"github.com/Rue-Foundation/go-rue/log"
+	"github.com/Rue-Foundation/go-rue/metrics"
+	"github.com/Rue-Foundation/go-rue/ruedb"
+)
+
+const (
+	// freezerHeaderTable indicates the name of the freezer header table.
+	freezerHeaderTable = "headers"
+
+	// freezerHashTable indicates the name of the freezer canonical hash table.
+	freezerHashTable = "hashes"
+
+	// freezerBodiesTable indicates the name of the freezer block body table.
+	freezerBodiesTable = "bodies"
+
+	// freezerReceiptTable indicates the name of the freezer receipts table.
+	freezerReceiptTable = "receipts"
+
+	// freezerDifficultyTable indicates the name of the freezer total difficulty table.
+	freezerDifficultyTable = "diffs"
+)
+
+// freezerNoSnappy configures whether compression is disabled for the ancient
+// tables. Hashes and difficulties don't compress well.
+var freezerNoSnappy = map[string]bool{
+	freezerHeaderTable:     false,
+	freezerHashTable:       true,
+	freezerBodiesTable:     false,
+	freezerReceiptTable:    false,
+	freezerDifficultyTable: true,
+}
+
+const (
+	// ImmutabilityThreshold is the default number of blocks after which a chain
+	// segment is considered immutable (i.e. soft finality). It is used to decide
+	// when blocks are moved from the key-value store into the ancient store.
+	ImmutabilityThreshold = 90000
+
+	// freezerRecheckInterval is the frequency to check the key-value database for
+	// chain progression that might permit new blocks to be frozen into immutable
+	// storage.
+	freezerRecheckInterval = time.Minute
+
+	// freezerBatchLimit is the maximum number of blocks to freeze in one batch
+	// before doing an fsync and deleting it from the key-value store.
+	freezerBatchLimit = 30000
+)
+
+// errUnknownTable is returned if the user attempts to read from a table that is
+// not tracked by the freezer.
+var errUnknownTable = errors.New("unknown table")
+
+// freezer is an append-only database to store immutable chain data into flat
+// files, one set per data table:
+//
+//   - The append only nature ensures that disk writes are minimized.
+//   - Keeping the immutable chain segment out of leveldb avoids paying the cost of
+//     compacting it over and over again, and allows placing it on cheaper disks.
+type freezer struct {
+	// WARNING: The `frozen` field is accessed atomically. On 32 bit platforms, only
+	// 64-bit aligned fields can be atomic. The struct is guaranteed to be so aligned,
+	// so take advantage of that (https://golang.org/pkg/sync/atomic/#pkg-note-BUG).
+	frozen uint64 // Number of blocks already frozen
+
+	threshold uint64                   // Number of recent blocks not to freeze
+	tables    map[string]*freezerTable // Data tables for storing everything
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// newFreezer creates a chain freezer that moves ancient chain data into
+// append-only flat file containers.
+func newFreezer(datadir string, namespace string, threshold uint64) (*freezer, error) {
+	// Create the initial freezer object
+	var (
+		readMeter  = metrics.NewMeter(namespace + "ancient/read")
+		writeMeter = metrics.NewMeter(namespace + "ancient/write")
+	)
+	freezer := &freezer{
+		threshold: threshold,
+		tables:    make(map[string]*freezerTable),
+		quit:      make(chan struct{}),
+	}
+	for name, disableSnappy := range freezerNoSnappy {
+		table, err := newTable(datadir, name, readMeter, writeMeter, disableSnappy)
+		if err != nil {
+			for _, table := range freezer.tables {
+				table.Close()
+			}
+			return nil, err
+		}
+		freezer.tables[name] = table
+	}
+	if err := freezer.repair(); err != nil {
+		for _, table := range freezer.tables {
+			table.Close()
+		}
+		return nil, err
+	}
+	log.Info("Opened ancient database", "database", datadir, "frozen", freezer.frozen)
+	return freezer, nil
+}
+
+// Close terminates the chain freezer, closing all the data files.
+func (f *freezer) Close() error {
+	close(f.quit)
+	f.wg.Wait()
+
+	var errs []error
+	for _, table := range f.tables {
+		if err := table.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if errs != nil {
+		return fmt.Errorf("%v", errs)
+	}
+	return nil
+}
+
+// HasAncient returns an indicator whether the specified ancient data exists
+// in the freezer.
+func (f *freezer) HasAncient(kind string, number uint64) (bool, error) {
+	if table := f.tables[kind]; table != nil {
+		return table.has(number), nil
+	}
+	return false, nil
+}
+
+// Ancient retrieves an ancient binary blob from the append-only immutable files.
+func (f *freezer) Ancient(kind string, number uint64) ([]byte, error) {
+	if table := f.tables[kind]; table != nil {
+		return table.Retrieve(number)
+	}
+	return nil, errUnknownTable
+}
+
+// Ancients returns the length of the frozen items.
+func (f *freezer) Ancients() (uint64, error) {
+	return atomic.LoadUint64(&f.frozen), nil
+}
+
+// AncientSize returns the ancient size of the specified category.
+func (f *freezer) AncientSize(kind string) (uint64, error) {
+	if table := f.tables[kind]; table != nil {
+		return table.size()
+	}
+	return 0, errUnknownTable
+}
+
+// AppendAncient injects all binary blobs belong to block at the end of the
+// append-only immutable table files.
+//
+// Notably, this function is lock free but kind of thread-safe. All out-of-order
+// injection will be rejected. But if two injections with same number happen at
+// the same time, we can get into the trouble.
+func (f *freezer) AppendAncient(number uint64, hash, header, body, receipts, td []byte) (err error) {
+	// Ensure the binary blobs we are appending is continuous with freezer.
+	if atomic.LoadUint64(&f.frozen) != number {
+		return errOutOrderInsert
+	}
+	// Rollback all inserted data if any insertion below failed to ensure
+	// the tables won't out of sync.
+	defer func() {
+		if err != nil {
+			rerr := f.repair()
+			if rerr != nil {
+				log.Crit("Failed to repair freezer", "err", rerr)
+			}
+			log.Info("Append ancient failed", "number", number, "err", err)
+		}
+	}()
+	// Inject all the components into the relevant data tables
+	if err := f.tables[freezerHashTable].Append(f.frozen, hash[:]); err != nil {
+		log.Error("Failed to append ancient hash", "number", f.frozen, "hash", hash, "err", err)
+		return err
+	}
+	if err := f.tables[freezerHeaderTable].Append(f.frozen, header); err != nil {
+		log.Error("Failed to append ancient header", "number", f.frozen, "hash", hash, "err", err)
+		return err
+	}
+	if err := f.tables[freezerBodiesTable].Append(f.frozen, body); err != nil {
+		log.Error("Failed to append ancient body", "number", f.frozen, "hash", hash, "err", err)
+		return err
+	}
+	if err := f.tables[freezerReceiptTable].Append(f.frozen, receipts); err != nil {
+		log.Error("Failed to append ancient receipts", "number", f.frozen, "hash", hash, "err", err)
+		return err
+	}
+	if err := f.tables[freezerDifficultyTable].Append(f.frozen, td); err != nil {
+		log.Error("Failed to append ancient difficulty", "number", f.frozen, "hash", hash, "err", err)
+		return err
+	}
+	atomic.AddUint64(&f.frozen, 1) // Only modify atomically
+	return nil
+}
+
+// TruncateAncients discards any recent data above the provided threshold number.
+func (f *freezer) TruncateAncients(items uint64) error {
+	if atomic.LoadUint64(&f.frozen) <= items {
+		return nil
+	}
+	for _, table := range f.tables {
+		if err := table.truncate(items); err != nil {
+			return err
+		}
+	}
+	atomic.StoreUint64(&f.frozen, items)
+	return nil
+}
+
+// Sync flushes all data tables to disk.
+func (f *freezer) Sync() error {
+	var errs []error
+	for _, table := range f.tables {
+		if err := table.Sync(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if errs != nil {
+		return fmt.Errorf("%v", errs)
+	}
+	return nil
+}
+
+// freeze is a background thread that periodically checks the blockchain for any
+// import progress and moves ancient data from the fast database into the freezer.
+//
+// This functionality is deliberately broken off from block importing to avoid
+// incurring additional data shuffling delays on block propagation.
+func (f *freezer) freeze(db ruedb.Database) {
+	defer f.wg.Done()
+
+	for {
+		// Retrieve the freezing threshold. The fast block head is tracked too,
+		// as fast sync imports bodies and receipts without advancing the full one.
+		var head uint64
+		for _, hash := range []common.Hash{GetHeadBlockHash(db), GetHeadFastBlockHash(db)} {
+			if hash == (common.Hash{}) {
+				continue
+			}
+			if number := GetBlockNumber(db, hash); number != missingNumber && number > head {
+				head = number
+			}
+		}
+		frozen := atomic.LoadUint64(&f.frozen)
+		if head < f.threshold || head-f.threshold < frozen {
+			log.Debug("Ancient blocks frozen already", "number", head, "frozen", frozen)
+			if !f.wait() {
+				return
+			}
+			continue
+		}
+		// Seems we have data ready to be frozen, process in usable batches
+		limit := head - f.threshold
+		if limit-frozen >= freezerBatchLimit {
+			limit = frozen + freezerBatchLimit - 1
+		}
+		var (
+			start    = time.Now()
+			first    = frozen
+			ancients = make([]common.Hash, 0, limit-frozen+1)
+		)
+		for number := first; number <= limit; number++ {
+			// Retrieve all the components of the canonical block
+			hash := GetCanonicalHash(db, number)
+			if hash == (common.Hash{}) {
+				log.Error("Canonical hash missing, can't freeze", "number", number)
+				break
+			}
+			header := GetHeaderRLP(db, hash, number)
+			if len(header) == 0 {
+				log.Error("Block header missing, can't freeze", "number", number, "hash", hash)
+				break
+			}
+			body := GetBodyRLP(db, hash, number)
+			if len(body) == 0 {
+				log.Error("Block body missing, can't freeze", "number", number, "hash", hash)
+				break
+			}
+			receipts, _ := db.Get(blockReceiptsKey(hash, number))
+			if len(receipts) == 0 {
+				log.Error("Block receipts missing, can't freeze", "number", number, "hash", hash)
+				break
+			}
+			td, _ := db.Get(tdKey(hash, number))
+			if len(td) == 0 {
+				log.Error("Total difficulty missing, can't freeze", "number", number, "hash", hash)
+				break
+			}
+			// Inject all the components into the relevant data tables
+			if err := f.AppendAncient(number, hash[:], header, body, receipts, td); err != nil {
+				break
+			}
+			ancients = append(ancients, hash)
+		}
+		// Batch of blocks have been frozen, flush them before wiping from leveldb
+		if err := f.Sync(); err != nil {
+			log.Crit("Failed to flush frozen tables", "err", err)
+		}
+		// Wipe out all data from the active database. The hash to number mappings
+		// are retained as they are needed to look the blocks up.
+		for i, hash := range ancients {
+			// Always keep the genesis block in active database
+			number := first + uint64(i)
+			if number != 0 {
+				deleteFrozenBlock(db, hash, number)
+			}
+			// Side chains forking off below the threshold can never become canonical
+			// again, delete them altogether to avoid leaking them in the database.
+			for _, side := range getAllHashes(db, number) {
+				if side != hash {
+					DeleteBlock(db, side, number)
+				}
+			}
+		}
+		// Log something friendly for the user
+		context := []interface{}{
+			"blocks", len(ancients), "elapsed", common.PrettyDuration(time.Since(start)), "number", first + uint64(len(ancients)) - 1,
+		}
+		if n := len(ancients); n > 0 {
+			context = append(context, []interface{}{"hash", ancients[n-1]}...)
+		}
+		log.Info("Deep froze chain segment", context...)
+
+		// Avoid database thrashing with tiny writes
+		if len(ancients) < freezerBatchLimit {
+			if !f.wait() {
+				return
+			}
+		}
+	}
+}
+
+// wait blocks until the next freeze attempt is due, returning false if the
+// freezer was closed in the meantime.
+func (f *freezer) wait() bool {
+	select {
+	case <-time.NewTimer(freezerRecheckInterval).C:
+		return true
+	case <-f.quit:
+		log.Info("Freezer shutting down")
+		return false
+	}
+}
+
+// repair truncates all data tables to the same length.
+func (f *freezer) repair() error {
+	min := uint64(1<<64 - 1)
+	for _, table := range f.tables {
+		items := atomic.LoadUint64(&table.items)
+		if min > items {
+			min = items
+		}
+	}
+	for _, table := range f.tables {
+		if err := table.truncate(min); err != nil {
+			return err
+		}
+	}
+	atomic.StoreUint64(&f.frozen, min)
+	return nil
+}
+
+// freezerdb is a database wrapper that enables freezer data retrievals.
+type freezerdb struct {
+	ruedb.Database
+	*freezer
+}
+
+// NewDatabaseWithFreezer creates a high level database on top of a given key-
+// value data store with a freezer moving immutable chain segments into cold
+// storage. If freeze is set, blocks older than threshold below the chain head
+// are migrated in the background, otherwise the ancient store is only read and
+// rewound (e.g. by offline tools operating on the chain).
+func NewDatabaseWithFreezer(db ruedb.Database, freezer string, namespace string, threshold uint64, freeze bool) (ruedb.Database, error) {
+	frdb, err := newFreezer(freezer, namespace, threshold)
+	if err != nil {
+		return nil, err
+	}
+	if freeze {
+		frdb.wg.Add(1)
+		go frdb.freeze(db)
+	}
+
+	return &freezerdb{
+		Database: db,
+		freezer:  frdb,
+	}, nil
+}
+
+// Close implements ruedb.Database, closing both the fast key-value store as well
+// as the slow ancient tables.
+func (frdb *freezerdb) Close() {
+	if err := frdb.freezer.Close(); err != nil {
+		log.Error("Failed to close ancient database", "err", err)
+	}
+	frdb.Database.Close()
+}
+
+// KeyValueStore returns the key-value store backing a database, stripping the
+// ancient store attached by NewDatabaseWithFreezer if any.
+func KeyValueStore(db ruedb.Database) ruedb.Database {
+	if frdb, ok := db.(*freezerdb); ok {
+		return frdb.Database
+	}
+	return db
+}
+
+// deleteFrozenBlock removes the canonical block data moved into the freezer
+// from the key-value store, leaving the hash to number mapping in place.
+func deleteFrozenBlock(db DatabaseDeleter, hash common.Hash, number uint64) {
+	DeleteCanonicalHash(db, number)
+	DeleteBlockReceipts(db, hash, number)
+	DeleteBody(db, hash, number)
+	DeleteTd(db, hash, number)
+	db.Delete(headerKey(hash, number))
+}
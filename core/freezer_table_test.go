@@ -0,0 +1,283 @@
+// Copyright 2018 The go-ruereum Authors
+// This file is part of the go-ruereum library.
+//
+// The go-ruereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ruereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ruereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Rue-Foundation/go-rue/common"
+	"github.com/Rue-Foundation/go-rue/core/types"
+	"github.com/Rue-Foundation/go-rue/ruedb"
+	gometrics "github.com/rcrowley/go-metrics"
+)
+
+// getChunk returns a chunk of data, filled with the given byte.
+func getChunk(size int, b int) []byte {
+	data := make([]byte, size)
+	for i := range data {
+		data[i] = byte(b)
+	}
+	return data
+}
+
+// Tests that items can be appended to and retrieved from a freezer table, also
+// across data file boundaries and after reopening the table.
+func TestFreezerTableBasics(t *testing.T) {
+	for _, noCompression := range []bool{false, true} {
+		dir, err := ioutil.TempDir("", "freezer")
+		if err != nil {
+			t.Fatalf("failed to create temporary directory: %v", err)
+		}
+		defer os.RemoveAll(dir)
+
+		meter := gometrics.NewMeter()
+
+		// Fill a table with small max file size, forcing multiple data files
+		table, err := newCustomTable(dir, "test", meter, meter, 50, noCompression)
+		if err != nil {
+			t.Fatalf("failed to open table: %v", err)
+		}
+		for i := 0; i < 255; i++ {
+			if err := table.Append(uint64(i), getChunk(15, i)); err != nil {
+				t.Fatalf("failed to append item %d: %v", i, err)
+			}
+		}
+		if err := table.Append(300, getChunk(15, 0)); err == nil {
+			t.Fatalf("out of order append succeeded")
+		}
+		table.Close()
+
+		// Reopen the table and verify all the content
+		if table, err = newCustomTable(dir, "test", meter, meter, 50, noCompression); err != nil {
+			t.Fatalf("failed to reopen table: %v", err)
+		}
+		for i := 0; i < 255; i++ {
+			blob, err := table.Retrieve(uint64(i))
+			if err != nil {
+				t.Fatalf("failed to retrieve item %d: %v", i, err)
+			}
+			if want := getChunk(15, i); !bytes.Equal(blob, want) {
+				t.Fatalf("item %d mismatch: have %x, want %x", i, blob, want)
+			}
+		}
+		if _, err := table.Retrieve(255); err != errOutOfBounds {
+			t.Fatalf("out of bounds retrieval error mismatch: have %v, want %v", err, errOutOfBounds)
+		}
+		table.Close()
+	}
+}
+
+// Tests that a freezer table with a data file shorter than its index is repaired
+// on open by dropping the dangling index entries.
+func TestFreezerTableRepairDanglingIndex(t *testing.T) {
+	dir, err := ioutil.TempDir("", "freezer")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	meter := gometrics.NewMeter()
+
+	table, err := newCustomTable(dir, "test", meter, meter, 1000, true)
+	if err != nil {
+		t.Fatalf("failed to open table: %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		table.Append(uint64(i), getChunk(10, i))
+	}
+	table.Close()
+
+	// Chop off the last four and a half items from the data file
+	if err := os.Truncate(filepath.Join(dir, "test.0000.rdat"), 55); err != nil {
+		t.Fatalf("failed to truncate data file: %v", err)
+	}
+	if table, err = newCustomTable(dir, "test", meter, meter, 1000, true); err != nil {
+		t.Fatalf("failed to reopen table: %v", err)
+	}
+	defer table.Close()
+
+	if table.items != 5 {
+		t.Fatalf("item count mismatch: have %d, want %d", table.items, 5)
+	}
+	if _, err := table.Retrieve(5); err != errOutOfBounds {
+		t.Fatalf("dangling item retrievable: %v", err)
+	}
+	// New items must be appendable after the repaired head
+	if err := table.Append(5, getChunk(10, 0xff)); err != nil {
+		t.Fatalf("failed to append after repair: %v", err)
+	}
+	if blob, _ := table.Retrieve(5); !bytes.Equal(blob, getChunk(10, 0xff)) {
+		t.Fatalf("item mismatch after repair: have %x", blob)
+	}
+}
+
+// Tests that truncating a freezer table drops items, including any data files
+// that became unused.
+func TestFreezerTableTruncate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "freezer")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	meter := gometrics.NewMeter()
+
+	table, err := newCustomTable(dir, "test", meter, meter, 50, false)
+	if err != nil {
+		t.Fatalf("failed to open table: %v", err)
+	}
+	defer table.Close()
+
+	for i := 0; i < 30; i++ {
+		table.Append(uint64(i), getChunk(15, i))
+	}
+	if err := table.truncate(10); err != nil {
+		t.Fatalf("failed to truncate table: %v", err)
+	}
+	if _, err := table.Retrieve(10); err != errOutOfBounds {
+		t.Fatalf("truncated item retrievable: %v", err)
+	}
+	if blob, err := table.Retrieve(9); err != nil || !bytes.Equal(blob, getChunk(15, 9)) {
+		t.Fatalf("retained item mismatch: have %x, err %v", blob, err)
+	}
+	if err := table.Append(10, getChunk(15, 0xaa)); err != nil {
+		t.Fatalf("failed to append after truncation: %v", err)
+	}
+}
+
+// Tests that the block accessors transparently read frozen blocks out of the
+// ancient store attached to the database.
+func TestFreezerDatabaseAccessors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "freezer")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	kvdb, _ := ruedb.NewMemDatabase()
+	db, err := NewDatabaseWithFreezer(kvdb, dir, "", ImmutabilityThreshold, false)
+	if err != nil {
+		t.Fatalf("failed to create freezer database: %v", err)
+	}
+	defer db.Close()
+
+	// Write a block into the key-value store and move it by hand into the freezer
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(0), Extra: []byte("test block")})
+	receipts := types.Receipts{types.NewReceipt(nil, false, new(big.Int))}
+
+	WriteBlock(kvdb, block)
+	WriteTd(kvdb, block.Hash(), 0, big.NewInt(42))
+	WriteBlockReceipts(kvdb, block.Hash(), 0, receipts)
+	WriteCanonicalHash(kvdb, block.Hash(), 0)
+
+	var (
+		hash        = block.Hash()
+		header      = GetHeaderRLP(kvdb, hash, 0)
+		body        = GetBodyRLP(kvdb, hash, 0)
+		rawRcpts, _ = kvdb.Get(blockReceiptsKey(hash, 0))
+		td, _       = kvdb.Get(tdKey(hash, 0))
+	)
+	if err := db.(AncientWriter).AppendAncient(0, hash[:], header, body, rawRcpts, td); err != nil {
+		t.Fatalf("failed to freeze block: %v", err)
+	}
+	deleteFrozenBlock(kvdb, hash, 0)
+
+	if HasHeader(kvdb, hash, 0) {
+		t.Fatalf("frozen header still in key-value store")
+	}
+	if have := GetCanonicalHash(db, 0); have != hash {
+		t.Fatalf("canonical hash mismatch: have %x, want %x", have, hash)
+	}
+	if entry := GetBlock(db, hash, 0); entry == nil || entry.Hash() != hash {
+		t.Fatalf("frozen block mismatch: have %v, want %v", entry, block)
+	}
+	if td := GetTd(db, hash, 0); td == nil || td.Cmp(big.NewInt(42)) != 0 {
+		t.Fatalf("frozen td mismatch: have %v, want %v", td, 42)
+	}
+	if entry := GetBlockReceipts(db, hash, 0); len(entry) != len(receipts) {
+		t.Fatalf("frozen receipts mismatch: have %d, want %d", len(entry), len(receipts))
+	}
+	if HasBody(db, common.Hash{0x01}, 0) {
+		t.Fatalf("non-canonical body reported as frozen")
+	}
+}
+
+// Tests that the background freezer moves canonical blocks into the ancient store
+// and drops side chain blocks at the frozen heights from the key-value store.
+func TestFreezerSideChainDeletion(t *testing.T) {
+	dir, err := ioutil.TempDir("", "freezer")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Create a short canonical chain with a side block forking off the genesis
+	kvdb, _ := ruedb.NewMemDatabase()
+
+	var (
+		canon  []*types.Block
+		parent common.Hash
+	)
+	for i := int64(0); i < 4; i++ {
+		block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(i), ParentHash: parent})
+		WriteBlock(kvdb, block)
+		WriteTd(kvdb, block.Hash(), block.NumberU64(), big.NewInt(i+1))
+		WriteBlockReceipts(kvdb, block.Hash(), block.NumberU64(), nil)
+		WriteCanonicalHash(kvdb, block.Hash(), block.NumberU64())
+
+		canon, parent = append(canon, block), block.Hash()
+	}
+	WriteHeadBlockHash(kvdb, parent)
+
+	side := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1), ParentHash: canon[0].Hash(), Extra: []byte("side")})
+	WriteBlock(kvdb, side)
+	WriteTd(kvdb, side.Hash(), 1, big.NewInt(2))
+
+	// Freeze everything except the head and wait for the side block to disappear
+	db, err := NewDatabaseWithFreezer(kvdb, dir, "", 1, true)
+	if err != nil {
+		t.Fatalf("failed to create freezer database: %v", err)
+	}
+	defer db.Close()
+
+	for deadline := time.Now().Add(5 * time.Second); HasHeader(kvdb, side.Hash(), 1); {
+		if time.Now().After(deadline) {
+			t.Fatalf("side chain block not deleted")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if frozen, _ := db.(AncientReader).Ancients(); frozen != 3 {
+		t.Fatalf("frozen block count mismatch: have %d, want %d", frozen, 3)
+	}
+	if GetBody(kvdb, side.Hash(), 1) != nil || GetTd(kvdb, side.Hash(), 1) != nil {
+		t.Fatalf("side chain block data retained")
+	}
+	for _, block := range canon[:3] {
+		if entry := GetBlock(db, block.Hash(), block.NumberU64()); entry == nil || entry.Hash() != block.Hash() {
+			t.Fatalf("block %d: frozen block mismatch: have %v, want %v", block.NumberU64(), entry, block)
+		}
+	}
+	if !HasHeader(kvdb, canon[3].Hash(), 3) {
+		t.Fatalf("unfrozen head block deleted")
+	}
+}
@@ -366,8 +366,7 @@ func (hc *HeaderChain) HasHeader(hash common.Hash, number uint64) bool {
 	if hc.numberCache.Contains(hash) || hc.headerCache.Contains(hash) {
 		return true
 	}
-	ok, _ := hc.chainDb.Has(headerKey(hash, number))
-	return ok
+	return HasHeader(hc.chainDb, hash, number)
 }
 
 // GetHeaderByNumber retrieves a block header from the database by number,
@@ -421,6 +420,12 @@ func (hc *HeaderChain) SetHead(head uint64, delFn DeleteCallback) {
 	for i := height; i > head; i-- {
 		DeleteCanonicalHash(hc.chainDb, i)
 	}
+	// Drop any frozen blocks above the new head from the ancient store
+	if adb, ok := hc.chainDb.(AncientWriter); ok {
+		if err := adb.TruncateAncients(head + 1); err != nil {
+			log.Crit("Failed to truncate ancient store", "err", err)
+		}
+	}
 	// Clear out any stale content from the caches
 	hc.headerCache.Purge()
 	hc.tdCache.Purge()
@@ -0,0 +1,191 @@
+// Copyright 2018 The go-ruereum Authors
+// This file is part of the go-ruereum library.
+//
+// The go-ruereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ruereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ruereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package pruner
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+
+	"github.com/Rue-Foundation/go-rue/common"
+)
+
+// bloomHashes is the number of bits set in the filter for every inserted key.
+const bloomHashes = 4
+
+// bloomMagic is the header prefix of a persisted state bloom filter.
+var bloomMagic = []byte("ruebloom")
+
+// errBloomCorrupt is returned if a persisted bloom filter cannot be decoded.
+var errBloomCorrupt = errors.New("corrupt state bloom filter")
+
+// stateBloom is a bloom filter used during offline pruning to mark all the trie
+// nodes and contract codes reachable from the retained state. Every key stored
+// in the filter is a keccak256 hash, so the key material itself is used as the
+// source of the bit positions instead of rehashing.
+type stateBloom struct {
+	bits  []uint64 // Bit vector backing the filter
+	items uint64   // Number of keys inserted, used to estimate the error rate
+}
+
+// newStateBloomWithSize creates an empty state bloom filter backed by the given
+// amount of memory in megabytes.
+func newStateBloomWithSize(size uint64) (*stateBloom, error) {
+	words := size * 1024 * 1024 / 8
+	if words == 0 {
+		return nil, errors.New("bloom filter size too small")
+	}
+	return &stateBloom{bits: make([]uint64, words)}, nil
+}
+
+// locations returns the bit positions associated with a hash key, derived with
+// the usual double hashing scheme from two independent slices of the key.
+func (bloom *stateBloom) locations(key []byte) [bloomHashes]uint64 {
+	var (
+		h1   = binary.BigEndian.Uint64(key[0:8])
+		h2   = binary.BigEndian.Uint64(key[8:16])
+		size = uint64(len(bloom.bits)) * 64
+		locs [bloomHashes]uint64
+	)
+	for i := range locs {
+		locs[i] = (h1 + uint64(i)*h2) % size
+	}
+	return locs
+}
+
+// add inserts a hash key into the filter.
+func (bloom *stateBloom) add(key []byte) {
+	for _, loc := range bloom.locations(key) {
+		bloom.bits[loc/64] |= 1 << (loc % 64)
+	}
+	bloom.items++
+}
+
+// contain reports whether the hash key might have been inserted into the filter.
+// False positives are possible, false negatives are not.
+func (bloom *stateBloom) contain(key []byte) bool {
+	for _, loc := range bloom.locations(key) {
+		if bloom.bits[loc/64]&(1<<(loc%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// falsePositiveRate estimates the probability of an unrelated key being reported
+// as contained in the filter.
+func (bloom *stateBloom) falsePositiveRate() float64 {
+	size := float64(len(bloom.bits)) * 64
+	return math.Pow(1-math.Exp(-bloomHashes*float64(bloom.items)/size), bloomHashes)
+}
+
+// commit persists the filter along with the state root it was generated for into
+// the given file. The data is written into a temporary file first and moved into
+// place afterwards, so the file either holds a complete filter or doesn't exist.
+func (bloom *stateBloom) commit(path string, root common.Hash) error {
+	tmp := path + ".tmp"
+
+	file, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if err := bloom.write(file, root); err != nil {
+		file.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// write serializes the filter and the state root into the writer.
+func (bloom *stateBloom) write(w io.Writer, root common.Hash) error {
+	out := bufio.NewWriter(w)
+
+	var header [16]byte
+	binary.BigEndian.PutUint64(header[0:8], bloom.items)
+	binary.BigEndian.PutUint64(header[8:16], uint64(len(bloom.bits)))
+
+	out.Write(bloomMagic)
+	out.Write(root[:])
+	out.Write(header[:])
+
+	var word [8]byte
+	for _, bits := range bloom.bits {
+		binary.BigEndian.PutUint64(word[:], bits)
+		if _, err := out.Write(word[:]); err != nil {
+			return err
+		}
+	}
+	return out.Flush()
+}
+
+// loadStateBloom reads back a filter persisted by commit, returning it along
+// with the state root it was generated for.
+func loadStateBloom(path string) (*stateBloom, common.Hash, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, common.Hash{}, err
+	}
+	defer file.Close()
+
+	in := bufio.NewReader(file)
+
+	var (
+		magic  = make([]byte, len(bloomMagic))
+		root   common.Hash
+		header [16]byte
+	)
+	if _, err := io.ReadFull(in, magic); err != nil || !bytes.Equal(magic, bloomMagic) {
+		return nil, common.Hash{}, errBloomCorrupt
+	}
+	if _, err := io.ReadFull(in, root[:]); err != nil {
+		return nil, common.Hash{}, errBloomCorrupt
+	}
+	if _, err := io.ReadFull(in, header[:]); err != nil {
+		return nil, common.Hash{}, errBloomCorrupt
+	}
+	bloom := &stateBloom{items: binary.BigEndian.Uint64(header[0:8])}
+
+	words := binary.BigEndian.Uint64(header[8:16])
+	if info, err := file.Stat(); err != nil || uint64(info.Size()) != uint64(len(bloomMagic))+common.HashLength+16+words*8 {
+		return nil, common.Hash{}, errBloomCorrupt
+	}
+	bloom.bits = make([]uint64, words)
+
+	var word [8]byte
+	for i := range bloom.bits {
+		if _, err := io.ReadFull(in, word[:]); err != nil {
+			return nil, common.Hash{}, fmt.Errorf("%v: %v", errBloomCorrupt, err)
+		}
+		bloom.bits[i] = binary.BigEndian.Uint64(word[:])
+	}
+	return bloom, root, nil
+}
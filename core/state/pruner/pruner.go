@@ -0,0 +1,289 @@
+// Copyright 2018 The go-ruereum Authors
+// This file is part of the go-ruereum library.
+//
+// The go-ruereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ruereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ruereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package pruner implements offline pruning of the persistent state trie.
+package pruner
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Rue-Foundation/go-rue/common"
+	"github.com/Rue-Foundation/go-rue/core"
+	"github.com/Rue-Foundation/go-rue/core/state"
+	"github.com/Rue-Foundation/go-rue/core/types"
+	"github.com/Rue-Foundation/go-rue/crypto"
+	"github.com/Rue-Foundation/go-rue/log"
+	"github.com/Rue-Foundation/go-rue/rlp"
+	"github.com/Rue-Foundation/go-rue/ruedb"
+	"github.com/Rue-Foundation/go-rue/trie"
+)
+
+const (
+	// BloomFileName is the name of the file holding the state bloom filter while
+	// pruning is in progress. Its presence on startup signals an interrupted run.
+	BloomFileName = "statepruning.bloom"
+
+	// stateRetention is the number of recent blocks below the chain head whose
+	// state might still be held only in memory by a running node, so the pruning
+	// target is picked at least this deep.
+	stateRetention = 127
+
+	// logInterval is the time between two consecutive progress reports.
+	logInterval = 8 * time.Second
+)
+
+var (
+	// emptyRoot is the known root hash of an empty trie.
+	emptyRoot = common.HexToHash("56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421")
+
+	// emptyCode is the known hash of the empty EVM bytecode.
+	emptyCode = crypto.Keccak256(nil)
+)
+
+// Pruner is an offline tool to delete the stale state trie nodes from the disk
+// database. All trie nodes and contract codes reachable from a retained state
+// root (and the genesis state) are marked in a bloom filter, after which every
+// other hash-keyed entry is swept from the database.
+//
+// The bloom filter is persisted before the sweep starts, so an interrupted run
+// can be resumed without having to regenerate it.
+type Pruner struct {
+	db        ruedb.Database
+	kvdb      ruedb.Database // Key-value store of the database, holding the state
+	bloomPath string
+	bloomSize uint64
+}
+
+// NewPruner creates a state pruner operating on the given chain database. The
+// bloom size is the amount of memory in megabytes to allocate for the filter.
+func NewPruner(db ruedb.Database, bloomPath string, bloomSize uint64) (*Pruner, error) {
+	return &Pruner{
+		db:        db,
+		kvdb:      core.KeyValueStore(db),
+		bloomPath: bloomPath,
+		bloomSize: bloomSize,
+	}, nil
+}
+
+// Prune deletes all the state not reachable from the given root or from the
+// genesis state. If the root is empty, the most recent state at least 127
+// blocks below the current head is retained. An interrupted previous run is
+// finished first instead of starting a new one.
+func (p *Pruner) Prune(root common.Hash) error {
+	if _, err := os.Stat(p.bloomPath); err == nil {
+		log.Info("Resuming interrupted state pruning", "bloom", p.bloomPath)
+		return RecoverPruning(p.bloomPath, p.kvdb)
+	}
+	// Resolve the state to retain if none was explicitly requested
+	if root == (common.Hash{}) {
+		header, err := p.pickTarget()
+		if err != nil {
+			return err
+		}
+		root = header.Root
+		log.Info("Selected state for pruning", "number", header.Number, "hash", header.Hash(), "root", root)
+	} else if ok, _ := p.db.Has(root[:]); !ok {
+		return fmt.Errorf("missing state %x", root)
+	}
+	genesis := core.GetHeader(p.db, core.GetCanonicalHash(p.db, 0), 0)
+	if genesis == nil {
+		return errors.New("missing genesis header")
+	}
+	// Mark all the retained state into the bloom filter
+	bloom, err := newStateBloomWithSize(p.bloomSize)
+	if err != nil {
+		return err
+	}
+	start := time.Now()
+	for _, r := range []common.Hash{root, genesis.Root} {
+		if err := markState(p.db, bloom, r); err != nil {
+			return err
+		}
+	}
+	log.Info("Marked retained state", "nodes", bloom.items, "fpr", fmt.Sprintf("%.6f", bloom.falsePositiveRate()), "elapsed", common.PrettyDuration(time.Since(start)))
+
+	// Persist the filter so the sweep can be resumed if interrupted, then sweep
+	if err := bloom.commit(p.bloomPath, root); err != nil {
+		return err
+	}
+	return prune(p.kvdb, bloom, p.bloomPath, root, start)
+}
+
+// pickTarget retrieves the header of the most recent canonical block at least
+// stateRetention blocks below the head whose state is available on disk.
+func (p *Pruner) pickTarget() (*types.Header, error) {
+	head := core.GetHeadBlockHash(p.db)
+	if head == (common.Hash{}) {
+		return nil, errors.New("empty database")
+	}
+	current := core.GetHeader(p.db, head, core.GetBlockNumber(p.db, head))
+	if current == nil {
+		return nil, fmt.Errorf("missing head header %x", head)
+	}
+	number := current.Number.Uint64()
+	if number < stateRetention {
+		return nil, fmt.Errorf("chain too short to prune: %d blocks", number)
+	}
+	for n := number - stateRetention; ; n-- {
+		if header := core.GetHeader(p.db, core.GetCanonicalHash(p.db, n), n); header != nil {
+			if ok, _ := p.db.Has(header.Root[:]); ok {
+				return header, nil
+			}
+		}
+		if n == 0 {
+			return nil, errors.New("no state available to retain")
+		}
+	}
+}
+
+// markState iterates over the entire state referenced by root, adding the hash
+// of every trie node and contract code to the bloom filter.
+func markState(db ruedb.Database, bloom *stateBloom, root common.Hash) error {
+	triedb := trie.NewDatabase(db)
+
+	accTrie, err := trie.New(root, triedb)
+	if err != nil {
+		return err
+	}
+	var (
+		accounts int
+		logged   = time.Now()
+	)
+	it := accTrie.NodeIterator(nil)
+	for it.Next(true) {
+		if hash := it.Hash(); hash != (common.Hash{}) {
+			bloom.add(hash[:])
+		}
+		if !it.Leaf() {
+			continue
+		}
+		var acc state.Account
+		if err := rlp.Decode(bytes.NewReader(it.LeafBlob()), &acc); err != nil {
+			return err
+		}
+		if !bytes.Equal(acc.CodeHash, emptyCode) {
+			bloom.add(acc.CodeHash)
+		}
+		if acc.Root != emptyRoot {
+			storageTrie, err := trie.New(acc.Root, triedb)
+			if err != nil {
+				return err
+			}
+			sit := storageTrie.NodeIterator(nil)
+			for sit.Next(true) {
+				if hash := sit.Hash(); hash != (common.Hash{}) {
+					bloom.add(hash[:])
+				}
+			}
+			if sit.Error() != nil {
+				return sit.Error()
+			}
+		}
+		accounts++
+		if time.Since(logged) > logInterval {
+			log.Info("Marking state in progress", "root", root, "accounts", accounts, "nodes", bloom.items)
+			logged = time.Now()
+		}
+	}
+	return it.Error()
+}
+
+// RecoverPruning finishes an interrupted pruning run if the bloom filter file
+// left behind by it is found. It is meant to be called on startup before any
+// new state is written to the database, otherwise the freshly written nodes
+// unknown to the filter would be deleted too.
+func RecoverPruning(bloomPath string, db ruedb.Database) error {
+	if _, err := os.Stat(bloomPath); os.IsNotExist(err) {
+		return nil
+	}
+	bloom, root, err := loadStateBloom(bloomPath)
+	if err != nil {
+		return err
+	}
+	log.Info("Resuming state pruning", "root", root)
+
+	// Only the state is swept, which never moves into the ancient store
+	return prune(core.KeyValueStore(db), bloom, bloomPath, root, time.Now())
+}
+
+// prune sweeps the database, deleting every trie node and contract code not
+// contained in the bloom filter. Once done, the filter file is removed and the
+// database compacted to reclaim the freed space.
+func prune(db ruedb.Database, bloom *stateBloom, bloomPath string, root common.Hash, start time.Time) error {
+	var (
+		count  int
+		size   common.StorageSize
+		batch  = db.NewBatch()
+		logged = time.Now()
+		sweep  = time.Now()
+	)
+	it := db.NewIterator(nil, nil)
+	for it.Next() {
+		key := it.Key()
+
+		// Only hash-keyed entries (trie nodes and contract codes) are considered
+		if len(key) != common.HashLength || bloom.contain(key) {
+			continue
+		}
+		count++
+		size += common.StorageSize(len(key) + len(it.Value()))
+		batch.Delete(common.CopyBytes(key))
+
+		if batch.ValueSize() >= ruedb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				it.Release()
+				return err
+			}
+			batch.Reset()
+		}
+		if time.Since(logged) > logInterval {
+			var eta time.Duration
+			if done := binary.BigEndian.Uint64(key[:8]); done > 0 {
+				elapsed := time.Since(sweep)
+				eta = time.Duration(float64(elapsed) * (float64(^uint64(0))/float64(done) - 1))
+			}
+			log.Info("Pruning state data", "nodes", count, "size", size, "elapsed", common.PrettyDuration(time.Since(start)), "eta", common.PrettyDuration(eta))
+			logged = time.Now()
+		}
+	}
+	it.Release()
+	if err := it.Error(); err != nil {
+		return err
+	}
+	if batch.ValueSize() > 0 {
+		if err := batch.Write(); err != nil {
+			return err
+		}
+	}
+	// Sweep done, the filter is not needed any more for recovery
+	if err := os.Remove(bloomPath); err != nil {
+		return err
+	}
+	log.Info("Pruned state data", "root", root, "nodes", count, "size", size, "elapsed", common.PrettyDuration(time.Since(start)))
+
+	cstart := time.Now()
+	log.Info("Compacting database, this may take a while")
+	if err := db.Compact(nil, nil); err != nil {
+		return err
+	}
+	log.Info("Database compaction finished", "elapsed", common.PrettyDuration(time.Since(cstart)))
+	return nil
+}
@@ -0,0 +1,179 @@
+// Copyright 2018 The go-ruereum Authors
+// This file is part of the go-ruereum library.
+//
+// The go-ruereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ruereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ruereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package pruner
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Rue-Foundation/go-rue/common"
+	"github.com/Rue-Foundation/go-rue/consensus/ruehash"
+	"github.com/Rue-Foundation/go-rue/core"
+	"github.com/Rue-Foundation/go-rue/core/state"
+	"github.com/Rue-Foundation/go-rue/core/types"
+	"github.com/Rue-Foundation/go-rue/crypto"
+	"github.com/Rue-Foundation/go-rue/params"
+	"github.com/Rue-Foundation/go-rue/ruedb"
+)
+
+// newTestChain creates a temporary leveldb database with a canonical chain of
+// the given length, every block crediting a fresh coinbase to mutate the state.
+func newTestChain(t *testing.T, n int) (*ruedb.LDBDatabase, []*types.Block, string) {
+	dir, err := ioutil.TempDir("", "pruner-test")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	db, err := ruedb.NewLDBDatabase(filepath.Join(dir, "chaindata"), 0, 0)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("failed to create database: %v", err)
+	}
+	gspec := &core.Genesis{
+		Config: params.TestChainConfig,
+		Alloc:  core.GenesisAlloc{common.Address{0x01}: {Balance: big.NewInt(1000000)}},
+	}
+	genesis := gspec.MustCommit(db)
+
+	blocks, _ := core.GenerateChain(params.TestChainConfig, genesis, ruehash.NewFaker(), db, n, func(i int, b *core.BlockGen) {
+		b.SetCoinbase(common.BigToAddress(big.NewInt(int64(i + 0x100))))
+	})
+	for _, block := range blocks {
+		core.WriteBlock(db, block)
+		core.WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+	}
+	core.WriteHeadBlockHash(db, blocks[len(blocks)-1].Hash())
+
+	return db, append([]*types.Block{genesis}, blocks...), dir
+}
+
+// hasState checks whether the entire state referenced by a root is available.
+func hasState(db ruedb.Database, root common.Hash) bool {
+	statedb, err := state.New(root, state.NewDatabase(db))
+	if err != nil {
+		return false
+	}
+	it := state.NewNodeIterator(statedb)
+	for it.Next() {
+	}
+	return it.Error == nil
+}
+
+// Tests that pruning retains the target and genesis states, and deletes the rest.
+func TestPruneState(t *testing.T) {
+	db, blocks, dir := newTestChain(t, 200)
+	defer os.RemoveAll(dir)
+	defer db.Close()
+
+	pruner, err := NewPruner(db, filepath.Join(dir, BloomFileName), 16)
+	if err != nil {
+		t.Fatalf("failed to create pruner: %v", err)
+	}
+	if err := pruner.Prune(common.Hash{}); err != nil {
+		t.Fatalf("failed to prune state: %v", err)
+	}
+	target := 200 - stateRetention
+	for i, block := range blocks {
+		switch {
+		case i == 0 || i == target:
+			if !hasState(db, block.Root()) {
+				t.Errorf("block %d: retained state missing", i)
+			}
+		default:
+			if ok, _ := db.Has(block.Root().Bytes()); ok {
+				t.Errorf("block %d: stale state root not pruned", i)
+			}
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, BloomFileName)); !os.IsNotExist(err) {
+		t.Errorf("bloom filter not removed after pruning: %v", err)
+	}
+}
+
+// Tests that an interrupted pruning run is finished from the persisted bloom.
+func TestRecoverPruning(t *testing.T) {
+	db, blocks, dir := newTestChain(t, 10)
+	defer os.RemoveAll(dir)
+	defer db.Close()
+
+	target := blocks[5].Root()
+
+	bloom, _ := newStateBloomWithSize(1)
+	if err := markState(db, bloom, target); err != nil {
+		t.Fatalf("failed to mark state: %v", err)
+	}
+	path := filepath.Join(dir, BloomFileName)
+	if err := bloom.commit(path, target); err != nil {
+		t.Fatalf("failed to persist bloom: %v", err)
+	}
+	if err := RecoverPruning(path, db); err != nil {
+		t.Fatalf("failed to recover pruning: %v", err)
+	}
+	if !hasState(db, target) {
+		t.Errorf("retained state missing")
+	}
+	if ok, _ := db.Has(blocks[9].Root().Bytes()); ok {
+		t.Errorf("stale state root not pruned")
+	}
+	// With the bloom filter gone, recovery must be a noop
+	if err := RecoverPruning(path, db); err != nil {
+		t.Fatalf("failed to run empty recovery: %v", err)
+	}
+}
+
+// Tests that a state bloom filter survives a persistence roundtrip.
+func TestStateBloomPersistence(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pruner-test")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// The filter indexes on the leading key bytes, so use proper hashes as keys
+	key := func(i int) []byte {
+		return crypto.Keccak256(big.NewInt(int64(i)).Bytes())
+	}
+	bloom, _ := newStateBloomWithSize(1)
+	for i := 0; i < 1000; i++ {
+		bloom.add(key(i))
+	}
+	root := common.HexToHash("0xdeadbeef")
+	path := filepath.Join(dir, BloomFileName)
+	if err := bloom.commit(path, root); err != nil {
+		t.Fatalf("failed to persist bloom: %v", err)
+	}
+	loaded, lroot, err := loadStateBloom(path)
+	if err != nil {
+		t.Fatalf("failed to load bloom: %v", err)
+	}
+	if lroot != root {
+		t.Errorf("root mismatch: have %x, want %x", lroot, root)
+	}
+	if loaded.items != bloom.items || len(loaded.bits) != len(bloom.bits) {
+		t.Fatalf("bloom mismatch: have %d/%d, want %d/%d", loaded.items, len(loaded.bits), bloom.items, len(bloom.bits))
+	}
+	for i := 0; i < 1000; i++ {
+		if !loaded.contain(key(i)) {
+			t.Fatalf("item %d missing from loaded bloom", i)
+		}
+	}
+	if loaded.contain(key(1000)) {
+		t.Fatalf("never inserted item reported by loaded bloom")
+	}
+}
@@ -25,7 +25,6 @@ import (
 	"github.com/Rue-Foundation/go-rue/common"
 	"github.com/Rue-Foundation/go-rue/crypto"
 	"github.com/Rue-Foundation/go-rue/rlp"
-	"github.com/Rue-Foundation/go-rue/trie"
 )
 
 var emptyCodeHash = crypto.Keccak256(nil)
@@ -214,6 +213,30 @@ func (self *stateObject) setState(key, value common.Hash) {
 	}
 }
 
+// SetStorage discards the entire account storage, replacing it with the given
+// slots. Any slot not present in the new storage reads as empty afterwards.
+//
+// Note, the change is not journalled and thus cannot be reverted.
+func (self *stateObject) SetStorage(db Database, storage map[common.Hash]common.Hash) {
+	tr, err := db.OpenStorageTrie(self.addrHash, common.Hash{})
+	if err != nil {
+		self.setError(err)
+		return
+	}
+	self.trie = tr
+	self.cachedStorage = make(Storage)
+	self.dirtyStorage = make(Storage)
+
+	for key, value := range storage {
+		self.setState(key, value)
+	}
+	// Mark the object dirty even if the new storage is empty
+	if self.onDirty != nil {
+		self.onDirty(self.Address())
+		self.onDirty = nil
+	}
+}
+
 // updateTrie writes cached storage modifications into the object's storage trie.
 func (self *stateObject) updateTrie(db Database) Trie {
 	tr := self.getTrie(db)
@@ -236,14 +259,14 @@ func (self *stateObject) updateRoot(db Database) {
 	self.data.Root = self.trie.Hash()
 }
 
-// CommitTrie the storage trie of the object to dwb.
+// CommitTrie the storage trie of the object to db.
 // This updates the trie root.
-func (self *stateObject) CommitTrie(db Database, dbw trie.DatabaseWriter) error {
+func (self *stateObject) CommitTrie(db Database) error {
 	self.updateTrie(db)
 	if self.dbErr != nil {
 		return self.dbErr
 	}
-	root, err := self.trie.CommitTo(dbw)
+	root, err := self.trie.Commit(nil)
 	if err == nil {
 		self.data.Root = root
 	}
@@ -32,7 +32,9 @@ import (
 
 	"github.com/Rue-Foundation/go-rue/common"
 	"github.com/Rue-Foundation/go-rue/core/types"
+	"github.com/Rue-Foundation/go-rue/crypto"
 	"github.com/Rue-Foundation/go-rue/ruedb"
+	"github.com/Rue-Foundation/go-rue/trie"
 )
 
 // Tests that updating a state trie does not leak any database writes prior to
@@ -97,12 +99,20 @@ func TestIntermediateLeaks(t *testing.T) {
 	}
 
 	// Commit and cross check the databases.
-	if _, err := transState.CommitTo(transDb, false); err != nil {
+	transRoot, err := transState.Commit(false)
+	if err != nil {
 		t.Fatalf("failed to commit transition state: %v", err)
 	}
-	if _, err := finalState.CommitTo(finalDb, false); err != nil {
+	if err = transState.Database().TrieDB().Commit(transRoot, false); err != nil {
+		t.Errorf("can not commit trie %v to persistent database", transRoot.Hex())
+	}
+	finalRoot, err := finalState.Commit(false)
+	if err != nil {
 		t.Fatalf("failed to commit final state: %v", err)
 	}
+	if err = finalState.Database().TrieDB().Commit(finalRoot, false); err != nil {
+		t.Errorf("can not commit trie %v to persistent database", finalRoot.Hex())
+	}
 	for _, key := range finalDb.Keys() {
 		if _, err := transDb.Get(key); err != nil {
 			val, _ := finalDb.Get(key)
@@ -168,6 +178,73 @@ func TestCopy(t *testing.T) {
 	}
 }
 
+// Tests that account and storage proofs generated from a state verify against
+// the state root and the account storage root respectively.
+func TestProofs(t *testing.T) {
+	db, _ := ruedb.NewMemDatabase()
+	state, _ := New(common.Hash{}, NewDatabase(db))
+
+	addr := common.BytesToAddress([]byte{0x01})
+	state.AddBalance(addr, big.NewInt(42))
+	state.SetState(addr, common.Hash{0x02}, common.Hash{0x03})
+	root, _ := state.Commit(false)
+	state, _ = New(root, state.Database())
+
+	// Verify the account proof against the state root
+	proof, err := state.GetProof(addr)
+	if err != nil {
+		t.Fatalf("failed to prove account: %v", err)
+	}
+	if _, err, _ := trie.VerifyProof(root, crypto.Keccak256(addr.Bytes()), proofDatabase(proof)); err != nil {
+		t.Fatalf("account proof invalid: %v", err)
+	}
+	// Verify the storage proof against the account's storage root
+	if proof, err = state.GetStorageProof(addr, common.Hash{0x02}); err != nil {
+		t.Fatalf("failed to prove storage: %v", err)
+	}
+	storageRoot := state.StorageTrie(addr).Hash()
+	if value, err, _ := trie.VerifyProof(storageRoot, crypto.Keccak256(common.Hash{0x02}.Bytes()), proofDatabase(proof)); err != nil {
+		t.Fatalf("storage proof invalid: %v", err)
+	} else if len(value) == 0 {
+		t.Fatalf("storage proof missing the slot value")
+	}
+	// Non-existent accounts have no storage to prove
+	if _, err := state.GetStorageProof(common.Address{0xff}, common.Hash{}); err == nil {
+		t.Fatalf("storage proof of missing account succeeded")
+	}
+}
+
+// Tests that replacing the storage of an account discards all previous slots.
+func TestSetStorage(t *testing.T) {
+	db, _ := ruedb.NewMemDatabase()
+	state, _ := New(common.Hash{}, NewDatabase(db))
+
+	addr := common.BytesToAddress([]byte{0x01})
+	state.SetState(addr, common.Hash{0x01}, common.Hash{0x01})
+	root, _ := state.Commit(false)
+	state, _ = New(root, state.Database())
+
+	state.SetStorage(addr, map[common.Hash]common.Hash{{0x02}: {0x02}})
+	if value := state.GetState(addr, common.Hash{0x01}); value != (common.Hash{}) {
+		t.Errorf("replaced slot still present: %x", value)
+	}
+	if value := state.GetState(addr, common.Hash{0x02}); value != (common.Hash{0x02}) {
+		t.Errorf("new slot mismatch: have %x, want %x", value, common.Hash{0x02})
+	}
+	if root == state.IntermediateRoot(false) {
+		t.Errorf("state root unchanged after storage replacement")
+	}
+}
+
+// proofDatabase indexes a list of proof nodes by their hashes.
+func proofDatabase(proof [][]byte) *ruedb.MemDatabase {
+	db, _ := ruedb.NewMemDatabase()
+	for _, node := range proof {
+		db.Put(crypto.Keccak256(node), node)
+	}
+	return db
+}
+
 func TestSnapshotRandom(t *testing.T) {
 	config := &quick.Config{MaxCount: 1000}
 	err := quick.Check((*snapshotTest).run, config)
@@ -409,7 +486,7 @@ func (test *snapshotTest) checkEqual(state, checkstate *StateDB) error {
 
 func (s *StateSuite) TestTouchDelete(c *check.C) {
 	s.state.GetOrNewStateObject(common.Address{})
-	root, _ := s.state.CommitTo(s.db, false)
+	root, _ := s.state.Commit(false)
 	s.state.Reset(root)
 
 	snapshot := s.state.Snapshot()
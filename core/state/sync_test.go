@@ -61,7 +61,10 @@ func makeTestState() (Database, *ruedb.MemDatabase, common.Hash, []*testAccount)
 		state.updateStateObject(obj)
 		accounts = append(accounts, acc)
 	}
-	root, _ := state.CommitTo(mem, false)
+	root, _ := state.Commit(false)
+	if err := db.TrieDB().Commit(root, false); err != nil {
+		panic(err)
+	}
 
 	// Return the generated state
 	return db, mem, root, accounts
@@ -96,7 +99,7 @@ func checkTrieConsistency(db ruedb.Database, root common.Hash) error {
 	if v, _ := db.Get(root[:]); v == nil {
 		return nil // Consider a non existent state consistent.
 	}
-	trie, err := trie.New(root, db)
+	trie, err := trie.New(root, trie.NewDatabase(db))
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,307 @@
+// Copyright 2018 The go-ruereum Authors
+// This file is part of the go-ruereum library.
+//
+// The go-ruereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ruereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ruereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Rue-Foundation/go-rue/common"
+	"github.com/Rue-Foundation/go-rue/common/hexutil"
+	"github.com/Rue-Foundation/go-rue/core/types"
+	"github.com/Rue-Foundation/go-rue/metrics"
+)
+
+// maxTxRejections is the number of recent filter rejections retained by the
+// transaction pool for inspection.
+const maxTxRejections = 256
+
+var (
+	// ErrSenderNotPermitted is returned if the sender of a transaction is denied
+	// by the admission policy of the pool.
+	ErrSenderNotPermitted = errors.New("sender not permitted")
+
+	// ErrDestinationNotPermitted is returned if the recipient of a transaction is
+	// denied by the admission policy of the pool.
+	ErrDestinationNotPermitted = errors.New("destination not permitted")
+
+	// ErrMethodNotPermitted is returned if the method selector a transaction calls
+	// is denied by the admission policy of the pool.
+	ErrMethodNotPermitted = errors.New("method not permitted")
+
+	// ErrGasAllowance is returned if a transaction's requested gas limit exceeds
+	// the maximum permitted by the admission policy of the pool.
+	ErrGasAllowance = errors.New("exceeds gas allowance")
+)
+
+// filteredTxCounter counts the transactions rejected by any of the pool filters.
+var filteredTxCounter = metrics.NewCounter("txpool/filtered")
+
+// TxPoolFilter is an admission policy consulted by the transaction pool for
+// every local and remote transaction that passed the basic validity checks.
+type TxPoolFilter interface {
+	// Name returns a short identifier of the filter, used in logs and metrics.
+	Name() string
+
+	// Check returns an error if the transaction, sent by the given account, is
+	// not permitted into the pool.
+	Check(tx *types.Transaction, from common.Address, local bool) error
+}
+
+// TxRejection is a transaction recently rejected by a pool filter.
+type TxRejection struct {
+	Hash   common.Hash
+	From   common.Address
+	Filter string
+	Err    error
+	Time   time.Time
+}
+
+// TxFilterConfig are the admission policies of the transaction pool. Empty
+// lists and zero limits disable the respective policy.
+type TxFilterConfig struct {
+	AllowSenders      []common.Address // Accounts exclusively permitted to send transactions
+	DenySenders       []common.Address // Accounts not permitted to send transactions
+	AllowDestinations []common.Address // Contracts exclusively permitted to be called (creations are exempt)
+	DenyDestinations  []common.Address // Contracts not permitted to be called
+	AllowMethods      []hexutil.Bytes  // Method selectors exclusively permitted to be called
+	DenyMethods       []hexutil.Bytes  // Method selectors not permitted to be called
+	MaxGas            uint64           // Maximum gas permitted per transaction
+}
+
+// filters assembles the built-in filters enabled by the configuration.
+func (config *TxFilterConfig) filters() ([]TxPoolFilter, error) {
+	var filters []TxPoolFilter
+	if len(config.AllowSenders) > 0 || len(config.DenySenders) > 0 {
+		filters = append(filters, NewSenderFilter(config.AllowSenders, config.DenySenders))
+	}
+	if len(config.AllowDestinations) > 0 || len(config.DenyDestinations) > 0 {
+		filters = append(filters, NewDestinationFilter(config.AllowDestinations, config.DenyDestinations))
+	}
+	if len(config.AllowMethods) > 0 || len(config.DenyMethods) > 0 {
+		filter, err := NewMethodFilter(config.AllowMethods, config.DenyMethods)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, filter)
+	}
+	if config.MaxGas > 0 {
+		filters = append(filters, NewGasFilter(config.MaxGas))
+	}
+	return filters, nil
+}
+
+// addressList is a set of accounts either exclusively permitted or denied.
+type addressList struct {
+	allow map[common.Address]struct{}
+	deny  map[common.Address]struct{}
+}
+
+func newAddressList(allow, deny []common.Address) addressList {
+	list := addressList{deny: make(map[common.Address]struct{})}
+	if len(allow) > 0 {
+		list.allow = make(map[common.Address]struct{})
+		for _, addr := range allow {
+			list.allow[addr] = struct{}{}
+		}
+	}
+	for _, addr := range deny {
+		list.deny[addr] = struct{}{}
+	}
+	return list
+}
+
+// permitted returns whether an account passes the allow and deny lists.
+func (list addressList) permitted(addr common.Address) bool {
+	if _, ok := list.deny[addr]; ok {
+		return false
+	}
+	if list.allow != nil {
+		_, ok := list.allow[addr]
+		return ok
+	}
+	return true
+}
+
+// SenderFilter admits transactions based on their sending account.
+type SenderFilter struct {
+	list addressList
+}
+
+// NewSenderFilter creates a filter rejecting transactions from the denied
+// accounts, and from all accounts not on the allow list if one is given.
+func NewSenderFilter(allow, deny []common.Address) *SenderFilter {
+	return &SenderFilter{list: newAddressList(allow, deny)}
+}
+
+// Name implements TxPoolFilter, returning the filter identifier.
+func (f *SenderFilter) Name() string { return "sender" }
+
+// Check implements TxPoolFilter, rejecting transactions of unpermitted senders.
+func (f *SenderFilter) Check(tx *types.Transaction, from common.Address, local bool) error {
+	if !f.list.permitted(from) {
+		return ErrSenderNotPermitted
+	}
+	return nil
+}
+
+// DestinationFilter admits transactions based on the account they are sent to.
+// Contract creations are not subject to it.
+type DestinationFilter struct {
+	list addressList
+}
+
+// NewDestinationFilter creates a filter rejecting transactions to the denied
+// accounts, and to all accounts not on the allow list if one is given.
+func NewDestinationFilter(allow, deny []common.Address) *DestinationFilter {
+	return &DestinationFilter{list: newAddressList(allow, deny)}
+}
+
+// Name implements TxPoolFilter, returning the filter identifier.
+func (f *DestinationFilter) Name() string { return "destination" }
+
+// Check implements TxPoolFilter, rejecting transactions to unpermitted recipients.
+func (f *DestinationFilter) Check(tx *types.Transaction, from common.Address, local bool) error {
+	if to := tx.To(); to != nil && !f.list.permitted(*to) {
+		return ErrDestinationNotPermitted
+	}
+	return nil
+}
+
+// MethodFilter admits transactions based on the 4 byte method selector of their
+// input data. Contract creations and transactions with shorter input data are
+// not subject to it.
+type MethodFilter struct {
+	allow map[[4]byte]struct{}
+	deny  map[[4]byte]struct{}
+}
+
+// NewMethodFilter creates a filter rejecting calls to the denied methods, and to
+// all methods not on the allow list if one is given.
+func NewMethodFilter(allow, deny []hexutil.Bytes) (*MethodFilter, error) {
+	filter := &MethodFilter{deny: make(map[[4]byte]struct{})}
+	if len(allow) > 0 {
+		filter.allow = make(map[[4]byte]struct{})
+	}
+	for _, set := range []struct {
+		selectors []hexutil.Bytes
+		dest      map[[4]byte]struct{}
+	}{{allow, filter.allow}, {deny, filter.deny}} {
+		for _, selector := range set.selectors {
+			if len(selector) != 4 {
+				return nil, fmt.Errorf("invalid method selector %v: want 4 bytes, have %d", selector, len(selector))
+			}
+			var id [4]byte
+			copy(id[:], selector)
+			set.dest[id] = struct{}{}
+		}
+	}
+	return filter, nil
+}
+
+// Name implements TxPoolFilter, returning the filter identifier.
+func (f *MethodFilter) Name() string { return "method" }
+
+// Check implements TxPoolFilter, rejecting calls to unpermitted methods.
+func (f *MethodFilter) Check(tx *types.Transaction, from common.Address, local bool) error {
+	data := tx.Data()
+	if tx.To() == nil || len(data) < 4 {
+		return nil
+	}
+	var id [4]byte
+	copy(id[:], data)
+
+	if _, ok := f.deny[id]; ok {
+		return ErrMethodNotPermitted
+	}
+	if f.allow != nil {
+		if _, ok := f.allow[id]; !ok {
+			return ErrMethodNotPermitted
+		}
+	}
+	return nil
+}
+
+// GasFilter admits transactions up to a maximum gas limit.
+type GasFilter struct {
+	limit uint64
+}
+
+// NewGasFilter creates a filter rejecting transactions requesting more gas than
+// the given limit.
+func NewGasFilter(limit uint64) *GasFilter {
+	return &GasFilter{limit: limit}
+}
+
+// Name implements TxPoolFilter, returning the filter identifier.
+func (f *GasFilter) Name() string { return "gas" }
+
+// Check implements TxPoolFilter, rejecting transactions over the gas limit.
+func (f *GasFilter) Check(tx *types.Transaction, from common.Address, local bool) error {
+	if gas := tx.Gas(); !gas.IsUint64() || gas.Uint64() > f.limit {
+		return ErrGasAllowance
+	}
+	return nil
+}
+
+// filterTx runs a transaction through all the admission filters of the pool,
+// recording it if any of them rejects it.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) filterTx(tx *types.Transaction, from common.Address, local bool) error {
+	for _, filter := range pool.filters {
+		if err := filter.Check(tx, from, local); err != nil {
+			filteredTxCounter.Inc(1)
+			metrics.NewCounter("txpool/filtered/" + filter.Name()).Inc(1)
+
+			if len(pool.rejections) == maxTxRejections {
+				copy(pool.rejections, pool.rejections[1:])
+				pool.rejections = pool.rejections[:maxTxRejections-1]
+			}
+			pool.rejections = append(pool.rejections, &TxRejection{
+				Hash:   tx.Hash(),
+				From:   from,
+				Filter: filter.Name(),
+				Err:    err,
+				Time:   time.Now(),
+			})
+			return err
+		}
+	}
+	return nil
+}
+
+// AddFilter installs an additional admission filter into the pool. It is only
+// applied to transactions added afterwards.
+func (pool *TxPool) AddFilter(filter TxPoolFilter) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	pool.filters = append(pool.filters, filter)
+}
+
+// Rejections retrieves the transactions most recently rejected by the pool's
+// admission filters, oldest first.
+func (pool *TxPool) Rejections() []*TxRejection {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	rejections := make([]*TxRejection, len(pool.rejections))
+	copy(rejections, pool.rejections)
+	return rejections
+}
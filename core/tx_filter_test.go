@@ -0,0 +1,115 @@
+// Copyright 2018 The go-ruereum Authors
+// This file is part of the go-ruereum library.
+//
+// The go-ruereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ruereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ruereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/Rue-Foundation/go-rue/common"
+	"github.com/Rue-Foundation/go-rue/common/hexutil"
+	"github.com/Rue-Foundation/go-rue/core/state"
+	"github.com/Rue-Foundation/go-rue/core/types"
+	"github.com/Rue-Foundation/go-rue/crypto"
+	"github.com/Rue-Foundation/go-rue/event"
+	"github.com/Rue-Foundation/go-rue/params"
+	"github.com/Rue-Foundation/go-rue/ruedb"
+)
+
+// Tests that the configured admission filters reject transactions by sender,
+// destination, method selector and gas, and that rejections are recorded.
+func TestTransactionFiltering(t *testing.T) {
+	t.Parallel()
+
+	var (
+		allowed, _ = crypto.GenerateKey()
+		denied, _  = crypto.GenerateKey()
+
+		permitted = common.Address{0x01}
+		forbidden = common.Address{0x02}
+	)
+	config := testTxPoolConfig
+	config.Filters = TxFilterConfig{
+		DenySenders:       []common.Address{crypto.PubkeyToAddress(denied.PublicKey)},
+		AllowDestinations: []common.Address{permitted},
+		DenyMethods:       []hexutil.Bytes{{0xde, 0xad, 0xbe, 0xef}},
+		MaxGas:            50000,
+	}
+	db, _ := ruedb.NewMemDatabase()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db))
+	blockchain := &testBlockChain{statedb, big.NewInt(1000000), new(event.Feed)}
+
+	pool := NewTxPool(config, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(allowed.PublicKey), big.NewInt(1000000000))
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(denied.PublicKey), big.NewInt(1000000000))
+
+	sign := func(nonce uint64, to *common.Address, gas uint64, data []byte, key *ecdsa.PrivateKey) *types.Transaction {
+		var tx *types.Transaction
+		if to == nil {
+			tx = types.NewContractCreation(nonce, big.NewInt(0), new(big.Int).SetUint64(gas), big.NewInt(1), data)
+		} else {
+			tx = types.NewTransaction(nonce, *to, big.NewInt(0), new(big.Int).SetUint64(gas), big.NewInt(1), data)
+		}
+		tx, _ = types.SignTx(tx, types.HomesteadSigner{}, key)
+		return tx
+	}
+	tests := []struct {
+		tx   *types.Transaction
+		want error
+	}{
+		{sign(0, &permitted, 30000, []byte{0x01, 0x02, 0x03, 0x04}, allowed), nil},
+		{sign(1, nil, 50000, nil, allowed), nil},
+		{sign(0, &permitted, 30000, nil, denied), ErrSenderNotPermitted},
+		{sign(2, &forbidden, 30000, nil, allowed), ErrDestinationNotPermitted},
+		{sign(2, &permitted, 30000, []byte{0xde, 0xad, 0xbe, 0xef, 0x00}, allowed), ErrMethodNotPermitted},
+		{sign(2, &permitted, 50001, nil, allowed), ErrGasAllowance},
+	}
+	for i, tt := range tests {
+		if err := pool.AddRemote(tt.tx); err != tt.want {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.want)
+		}
+	}
+	// Local transactions must be subject to the filters too
+	if err := pool.AddLocal(sign(2, &forbidden, 30000, nil, allowed)); err != ErrDestinationNotPermitted {
+		t.Errorf("local transaction error mismatch: have %v, want %v", err, ErrDestinationNotPermitted)
+	}
+	if pending, _ := pool.Stats(); pending != 2 {
+		t.Errorf("pending transactions mismatch: have %d, want %d", pending, 2)
+	}
+	rejections := pool.Rejections()
+	if len(rejections) != 5 {
+		t.Fatalf("rejection count mismatch: have %d, want %d", len(rejections), 5)
+	}
+	for i, filter := range []string{"sender", "destination", "method", "gas", "destination"} {
+		if rejections[i].Filter != filter {
+			t.Errorf("rejection %d: filter mismatch: have %s, want %s", i, rejections[i].Filter, filter)
+		}
+	}
+	if rejections[0].From != crypto.PubkeyToAddress(denied.PublicKey) || rejections[0].Hash != tests[2].tx.Hash() {
+		t.Errorf("rejection mismatch: have %x from %x", rejections[0].Hash, rejections[0].From)
+	}
+}
+
+// Tests that method filters refuse to be created with malformed selectors.
+func TestMethodFilterInvalidSelector(t *testing.T) {
+	if _, err := NewMethodFilter([]hexutil.Bytes{{0x01, 0x02}}, nil); err == nil {
+		t.Errorf("short selector accepted")
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"errors"
 	"io"
 	"os"
+	"time"
 
 	"github.com/Rue-Foundation/go-rue/common"
 	"github.com/Rue-Foundation/go-rue/core/types"
@@ -40,23 +41,45 @@ type devNull struct{}
 func (*devNull) Write(p []byte) (n int, err error) { return len(p), nil }
 func (*devNull) Close() error                      { return nil }
 
+// journalEntry is a transaction stored in a stamped journal, along with the last
+// time its sending account was seen active by the pool.
+type journalEntry struct {
+	Tx   *types.Transaction
+	Time uint64 // Unix timestamp, zero if the account was never active
+}
+
 // txJournal is a rotating log of transactions with the aim of storing locally
 // created transactions to allow non-executed ones to survive node restarts.
 type txJournal struct {
-	path   string         // Filesystem path to store the transactions at
-	writer io.WriteCloser // Output stream to write new transactions into
+	path    string         // Filesystem path to store the transactions at
+	kind    string         // Kind of transactions journaled, used for logging
+	stamped bool           // Whether entries carry the activity time of their account
+	writer  io.WriteCloser // Output stream to write new transactions into
 }
 
 // newTxJournal creates a new transaction journal to
 func newTxJournal(path string) *txJournal {
 	return &txJournal{
 		path: path,
+		kind: "local",
+	}
+}
+
+// newStampedTxJournal creates a new transaction journal storing each transaction
+// along with the activity time of its sender, allowing stale transactions to be
+// dropped when loaded.
+func newStampedTxJournal(path, kind string) *txJournal {
+	return &txJournal{
+		path:    path,
+		kind:    kind,
+		stamped: true,
 	}
 }
 
 // load parses a transaction journal dump from disk, loading its contents into
-// the specified pool.
-func (journal *txJournal) load(add func(*types.Transaction) error) error {
+// the specified pool. Transactions of unstamped journals are loaded with a zero
+// activity time.
+func (journal *txJournal) load(add func(*types.Transaction, time.Time) error) error {
 	// Skip the parsing if the journal file doens't exist at all
 	if _, err := os.Stat(journal.path); os.IsNotExist(err) {
 		return nil
@@ -79,8 +102,20 @@ func (journal *txJournal) load(add func(*types.Transaction) error) error {
 	var failure error
 	for {
 		// Parse the next transaction and terminate on error
-		tx := new(types.Transaction)
-		if err = stream.Decode(tx); err != nil {
+		var (
+			tx    = new(types.Transaction)
+			stamp time.Time
+		)
+		if journal.stamped {
+			entry := journalEntry{Tx: tx}
+			err = stream.Decode(&entry)
+			if entry.Time > 0 {
+				stamp = time.Unix(int64(entry.Time), 0)
+			}
+		} else {
+			err = stream.Decode(tx)
+		}
+		if err != nil {
 			if err != io.EOF {
 				failure = err
 			}
@@ -88,31 +123,45 @@ func (journal *txJournal) load(add func(*types.Transaction) error) error {
 		}
 		// Import the transaction and bump the appropriate progress counters
 		total++
-		if err = add(tx); err != nil {
+		if err = add(tx, stamp); err != nil {
 			log.Debug("Failed to add journaled transaction", "err", err)
 			dropped++
 			continue
 		}
 	}
-	log.Info("Loaded local transaction journal", "transactions", total, "dropped", dropped)
+	log.Info("Loaded "+journal.kind+" transaction journal", "transactions", total, "dropped", dropped)
 
 	return failure
 }
 
-// insert adds the specified transaction to the local disk journal.
-func (journal *txJournal) insert(tx *types.Transaction) error {
+// insert adds the specified transaction to the local disk journal. The stamp is
+// the activity time of the sender, ignored by unstamped journals.
+func (journal *txJournal) insert(tx *types.Transaction, stamp time.Time) error {
 	if journal.writer == nil {
 		return errNoActiveJournal
 	}
-	if err := rlp.Encode(journal.writer, tx); err != nil {
+	if err := journal.encode(journal.writer, tx, stamp); err != nil {
 		return err
 	}
 	return nil
 }
 
+// encode writes a single journal entry into the given stream.
+func (journal *txJournal) encode(w io.Writer, tx *types.Transaction, stamp time.Time) error {
+	if !journal.stamped {
+		return rlp.Encode(w, tx)
+	}
+	entry := journalEntry{Tx: tx}
+	if !stamp.IsZero() {
+		entry.Time = uint64(stamp.Unix())
+	}
+	return rlp.Encode(w, &entry)
+}
+
 // rotate regenerates the transaction journal based on the current contents of
-// the transaction pool.
-func (journal *txJournal) rotate(all map[common.Address]types.Transactions) error {
+// the transaction pool. The stamps are the activity times of the accounts,
+// ignored by unstamped journals.
+func (journal *txJournal) rotate(all map[common.Address]types.Transactions, stamps map[common.Address]time.Time) error {
 	// Close the current journal (if any is open)
 	if journal.writer != nil {
 		if err := journal.writer.Close(); err != nil {
@@ -126,9 +175,9 @@ func (journal *txJournal) rotate(all map[common.Address]types.Transactions) erro
 		return err
 	}
 	journaled := 0
-	for _, txs := range all {
+	for addr, txs := range all {
 		for _, tx := range txs {
-			if err = rlp.Encode(replacement, tx); err != nil {
+			if err = journal.encode(replacement, tx, stamps[addr]); err != nil {
 				replacement.Close()
 				return err
 			}
@@ -146,7 +195,7 @@ func (journal *txJournal) rotate(all map[common.Address]types.Transactions) erro
 		return err
 	}
 	journal.writer = sink
-	log.Info("Regenerated local transaction journal", "transactions", journaled, "accounts", len(all))
+	log.Info("Regenerated "+journal.kind+" transaction journal", "transactions", journaled, "accounts", len(all))
 
 	return nil
 }
@@ -78,6 +78,14 @@ var (
 	// than some meaningful limit a user might use. This is not a consensus error
 	// making the transaction invalid, rather a DOS protection.
 	ErrOversizedData = errors.New("oversized data")
+
+	// ErrPrivateTxExpired is returned if a private transaction is submitted with
+	// an expiry block that the chain already reached.
+	ErrPrivateTxExpired = errors.New("private transaction expired")
+
+	// errExpiredTransaction is returned if a journaled remote transaction is not
+	// restored as its sender has been inactive for longer than the pool lifetime.
+	errExpiredTransaction = errors.New("expired transaction")
 )
 
 var (
@@ -113,6 +121,32 @@ const (
 	TxStatusIncluded
 )
 
+// TxDropReason is the cause of a transaction being dropped from the pool.
+type TxDropReason string
+
+const (
+	// TxDropReplaced is reported if a transaction was superseded by another one
+	// from the same account with the same nonce.
+	TxDropReplaced TxDropReason = "replaced"
+
+	// TxDropUnderpriced is reported if a transaction was discarded to make room
+	// for better priced ones, or fell below the minimum gas price of the pool.
+	TxDropUnderpriced TxDropReason = "underpriced"
+
+	// TxDropEvicted is reported if a transaction was discarded to keep the pool
+	// within its configured slot and queue limits.
+	TxDropEvicted TxDropReason = "evicted"
+
+	// TxDropExpired is reported if a non-executable transaction was discarded as
+	// its account was inactive for longer than the configured lifetime, or if a
+	// private transaction was not included until its expiry block.
+	TxDropExpired TxDropReason = "expired"
+
+	// TxDropInvalidated is reported if a transaction became unpayable by a state
+	// change, such as a new head or a chain reorganisation.
+	TxDropInvalidated TxDropReason = "invalidated"
+)
+
 // blockChain provides the state of blockchain and current gas limit to do
 // some pre checks in tx pool and event subscribers.
 type blockChain interface {
@@ -129,6 +163,9 @@ type TxPoolConfig struct {
 	Journal   string        // Journal of local transactions to survive node restarts
 	Rejournal time.Duration // Time interval to regenerate the local transaction journal
 
+	JournalRemotes bool   // Whether remote transactions should be journaled too
+	RemoteJournal  string // Journal of remote transactions to survive node restarts
+
 	PriceLimit uint64 // Minimum gas price to enforce for acceptance into the pool
 	PriceBump  uint64 // Minimum price bump percentage to replace an already existing transaction (nonce)
 
@@ -138,6 +175,8 @@ type TxPoolConfig struct {
 	GlobalQueue  uint64 // Maximum number of non-executable transaction slots for all accounts
 
 	Lifetime time.Duration // Maximum amount of time non-executable transaction are queued
+
+	Filters TxFilterConfig // Admission policies transactions must pass to enter the pool
 }
 
 // DefaultTxPoolConfig contains the default configurations for the transaction
@@ -146,6 +185,8 @@ var DefaultTxPoolConfig = TxPoolConfig{
 	Journal:   "transactions.rlp",
 	Rejournal: time.Hour,
 
+	RemoteJournal: "remotes.rlp",
+
 	PriceLimit: 1,
 	PriceBump:  10,
 
@@ -189,6 +230,7 @@ type TxPool struct {
 	chain        blockChain
 	gasPrice     *big.Int
 	txFeed       event.Feed
+	dropFeed     event.Feed
 	scope        event.SubscriptionScope
 	chainHeadCh  chan ChainHeadEvent
 	chainHeadSub event.Subscription
@@ -201,12 +243,17 @@ type TxPool struct {
 
 	locals  *accountSet // Set of local transaction to exempt from eviction rules
 	journal *txJournal  // Journal of local transaction to back up to disk
+	remotes *txJournal  // Journal of remote transactions to back up to disk
 
 	pending map[common.Address]*txList         // All currently processable transactions
 	queue   map[common.Address]*txList         // Queued but non-processable transactions
 	beats   map[common.Address]time.Time       // Last heartbeat from each known account
 	all     map[common.Hash]*types.Transaction // All transactions to allow lookups
 	priced  *txPricedList                      // All transactions sorted by price
+	private map[common.Hash]uint64             // Expiry blocks of transactions not to be propagated
+
+	filters    []TxPoolFilter // Admission policies consulted for every new transaction
+	rejections []*TxRejection // Transactions most recently rejected by the filters
 
 	wg sync.WaitGroup // for shutdown sync
 
@@ -228,10 +275,16 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain block
 		pending:     make(map[common.Address]*txList),
 		queue:       make(map[common.Address]*txList),
 		beats:       make(map[common.Address]time.Time),
+		private:     make(map[common.Hash]uint64),
 		all:         make(map[common.Hash]*types.Transaction),
 		chainHeadCh: make(chan ChainHeadEvent, chainHeadChanSize),
 		gasPrice:    new(big.Int).SetUint64(config.PriceLimit),
 	}
+	filters, err := config.Filters.filters()
+	if err != nil {
+		log.Crit("Invalid transaction pool filters", "err", err)
+	}
+	pool.filters = filters
 	pool.locals = newAccountSet(pool.signer)
 	pool.priced = newTxPricedList(&pool.all)
 	pool.reset(nil, chain.CurrentBlock().Header())
@@ -240,13 +293,27 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain block
 	if !config.NoLocals && config.Journal != "" {
 		pool.journal = newTxJournal(config.Journal)
 
-		if err := pool.journal.load(pool.AddLocal); err != nil {
+		if err := pool.journal.load(func(tx *types.Transaction, _ time.Time) error { return pool.AddLocal(tx) }); err != nil {
 			log.Warn("Failed to load transaction journal", "err", err)
 		}
-		if err := pool.journal.rotate(pool.local()); err != nil {
+		if err := pool.journal.rotate(pool.local(), nil); err != nil {
 			log.Warn("Failed to rotate transaction journal", "err", err)
 		}
 	}
+	// If remote journaling is enabled, restore the rest of the pool from disk
+	if config.JournalRemotes && config.RemoteJournal != "" {
+		pool.remotes = newStampedTxJournal(config.RemoteJournal, "remote")
+
+		if err := pool.remotes.load(pool.addJournaledRemote); err != nil {
+			log.Warn("Failed to load remote transaction journal", "err", err)
+		}
+		pool.mu.RLock()
+		err := pool.remotes.rotate(pool.remote(), pool.beats)
+		pool.mu.RUnlock()
+		if err != nil {
+			log.Warn("Failed to rotate remote transaction journal", "err", err)
+		}
+	}
 	// Subscribe events from blockchain
 	pool.chainHeadSub = pool.chain.SubscribeChainHeadEvent(pool.chainHeadCh)
 
@@ -321,20 +388,28 @@ func (pool *TxPool) loop() {
 				if time.Since(pool.beats[addr]) > pool.config.Lifetime {
 					for _, tx := range pool.queue[addr].Flatten() {
 						pool.removeTx(tx.Hash())
+						pool.dropTx(tx, TxDropExpired, common.Hash{})
 					}
 				}
 			}
 			pool.mu.Unlock()
 
-		// Handle local transaction journal rotation
+		// Handle local and remote transaction journal rotation
 		case <-journal.C:
 			if pool.journal != nil {
 				pool.mu.Lock()
-				if err := pool.journal.rotate(pool.local()); err != nil {
+				if err := pool.journal.rotate(pool.local(), nil); err != nil {
 					log.Warn("Failed to rotate local tx journal", "err", err)
 				}
 				pool.mu.Unlock()
 			}
+			if pool.remotes != nil {
+				pool.mu.Lock()
+				if err := pool.remotes.rotate(pool.remote(), pool.beats); err != nil {
+					log.Warn("Failed to rotate remote tx journal", "err", err)
+				}
+				pool.mu.Unlock()
+			}
 		}
 	}
 }
@@ -421,6 +496,9 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 	// higher gas price)
 	pool.demoteUnexecutables()
 
+	// Drop any private transactions not included until their expiry
+	pool.expirePrivates(newHead.Number.Uint64())
+
 	// Update all accounts to the latest known pending nonce
 	for addr, list := range pool.pending {
 		txs := list.Flatten() // Heavy but will be cached and is needed by the miner anyway
@@ -443,6 +521,9 @@ func (pool *TxPool) Stop() {
 	if pool.journal != nil {
 		pool.journal.close()
 	}
+	if pool.remotes != nil {
+		pool.remotes.close()
+	}
 	log.Info("Transaction pool stopped")
 }
 
@@ -452,6 +533,12 @@ func (pool *TxPool) SubscribeTxPreEvent(ch chan<- TxPreEvent) event.Subscription
 	return pool.scope.Track(pool.txFeed.Subscribe(ch))
 }
 
+// SubscribeTxDropEvent registers a subscription of TxDropEvent and starts
+// sending event to the given channel.
+func (pool *TxPool) SubscribeTxDropEvent(ch chan<- TxDropEvent) event.Subscription {
+	return pool.scope.Track(pool.dropFeed.Subscribe(ch))
+}
+
 // GasPrice returns the current gas price enforced by the transaction pool.
 func (pool *TxPool) GasPrice() *big.Int {
 	pool.mu.RLock()
@@ -469,6 +556,7 @@ func (pool *TxPool) SetGasPrice(price *big.Int) {
 	pool.gasPrice = price
 	for _, tx := range pool.priced.Cap(price, pool.locals) {
 		pool.removeTx(tx.Hash())
+		pool.dropTx(tx, TxDropUnderpriced, common.Hash{})
 	}
 	log.Info("Transaction pool price threshold updated", "price", price)
 }
@@ -536,21 +624,55 @@ func (pool *TxPool) Pending() (map[common.Address]types.Transactions, error) {
 }
 
 // local retrieves all currently known local transactions, groupped by origin
-// account and sorted by nonce. The returned transaction set is a copy and can be
-// freely modified by calling code.
+// account and sorted by nonce. Private transactions are omitted as they are
+// never journaled. The returned transaction set is a copy and can be freely
+// modified by calling code.
 func (pool *TxPool) local() map[common.Address]types.Transactions {
 	txs := make(map[common.Address]types.Transactions)
 	for addr := range pool.locals.accounts {
 		if pending := pool.pending[addr]; pending != nil {
-			txs[addr] = append(txs[addr], pending.Flatten()...)
+			txs[addr] = append(txs[addr], pool.public(pending.Flatten())...)
 		}
 		if queued := pool.queue[addr]; queued != nil {
-			txs[addr] = append(txs[addr], queued.Flatten()...)
+			txs[addr] = append(txs[addr], pool.public(queued.Flatten())...)
 		}
 	}
 	return txs
 }
 
+// remote retrieves all currently known remote transactions, groupped by origin
+// account and sorted by nonce. Private transactions are omitted as they are
+// never journaled. The returned transaction set is a copy and can be freely
+// modified by calling code.
+func (pool *TxPool) remote() map[common.Address]types.Transactions {
+	txs := make(map[common.Address]types.Transactions)
+	for addr, pending := range pool.pending {
+		if !pool.locals.contains(addr) {
+			txs[addr] = append(txs[addr], pool.public(pending.Flatten())...)
+		}
+	}
+	for addr, queued := range pool.queue {
+		if !pool.locals.contains(addr) {
+			txs[addr] = append(txs[addr], pool.public(queued.Flatten())...)
+		}
+	}
+	return txs
+}
+
+// public filters out the private transactions from a list.
+func (pool *TxPool) public(txs types.Transactions) types.Transactions {
+	if len(pool.private) == 0 {
+		return txs
+	}
+	public := make(types.Transactions, 0, len(txs))
+	for _, tx := range txs {
+		if !pool.isPrivate(tx.Hash()) {
+			public = append(public, tx)
+		}
+	}
+	return public
+}
+
 // validateTx checks whruer a transaction is valid according to the consensus
 // rules and adheres to some heuristic limits of the local node (price and size).
 func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
@@ -614,6 +736,12 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
 		invalidTxCounter.Inc(1)
 		return false, err
 	}
+	// If the transaction is not admitted by the pool's policies, discard it
+	from, _ := types.Sender(pool.signer, tx) // already validated
+	if err := pool.filterTx(tx, from, local); err != nil {
+		log.Trace("Discarding filtered transaction", "hash", hash, "from", from, "err", err)
+		return false, err
+	}
 	// If the transaction pool is full, discard underpriced transactions
 	if uint64(len(pool.all)) >= pool.config.GlobalSlots+pool.config.GlobalQueue {
 		// If the new transaction is underpriced, don't accept it
@@ -628,10 +756,10 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
 			log.Trace("Discarding freshly underpriced transaction", "hash", tx.Hash(), "price", tx.GasPrice())
 			underpricedTxCounter.Inc(1)
 			pool.removeTx(tx.Hash())
+			pool.dropTx(tx, TxDropUnderpriced, common.Hash{})
 		}
 	}
 	// If the transaction is replacing an already pending one, do directly
-	from, _ := types.Sender(pool.signer, tx) // already validated
 	if list := pool.pending[from]; list != nil && list.Overlaps(tx) {
 		// Nonce already pending, check if required price bump is met
 		inserted, old := list.Add(tx, pool.config.PriceBump)
@@ -644,6 +772,7 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
 			delete(pool.all, old.Hash())
 			pool.priced.Removed()
 			pendingReplaceCounter.Inc(1)
+			pool.dropTx(old, TxDropReplaced, hash)
 		}
 		pool.all[tx.Hash()] = tx
 		pool.priced.Put(tx)
@@ -652,7 +781,7 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
 		log.Trace("Pooled new executable transaction", "hash", hash, "from", from, "to", tx.To())
 
 		// We've directly injected a replacement transaction, notify subsystems
-		go pool.txFeed.Send(TxPreEvent{tx})
+		go pool.txFeed.Send(TxPreEvent{Tx: tx, Private: pool.isPrivate(hash)})
 
 		return old != nil, nil
 	}
@@ -691,6 +820,7 @@ func (pool *TxPool) enqueueTx(hash common.Hash, tx *types.Transaction) (bool, er
 		delete(pool.all, old.Hash())
 		pool.priced.Removed()
 		queuedReplaceCounter.Inc(1)
+		pool.dropTx(old, TxDropReplaced, hash)
 	}
 	pool.all[hash] = tx
 	pool.priced.Put(tx)
@@ -698,17 +828,53 @@ func (pool *TxPool) enqueueTx(hash common.Hash, tx *types.Transaction) (bool, er
 }
 
 // journalTx adds the specified transaction to the local disk journal if it is
-// deemed to have been sent from a local account.
+// deemed to have been sent from a local account, or to the remote journal if
+// that is enabled.
 func (pool *TxPool) journalTx(from common.Address, tx *types.Transaction) {
-	// Only journal if it's enabled and the transaction is local
-	if pool.journal == nil || !pool.locals.contains(from) {
+	// Private transactions are never journaled, lest they be revived as public
+	if pool.isPrivate(tx.Hash()) {
 		return
 	}
-	if err := pool.journal.insert(tx); err != nil {
-		log.Warn("Failed to journal local transaction", "err", err)
+	if pool.locals.contains(from) {
+		// Only journal if it's enabled and the transaction is local
+		if pool.journal == nil {
+			return
+		}
+		if err := pool.journal.insert(tx, time.Time{}); err != nil {
+			log.Warn("Failed to journal local transaction", "err", err)
+		}
+		return
+	}
+	if pool.remotes == nil {
+		return
+	}
+	if err := pool.remotes.insert(tx, time.Now()); err != nil {
+		log.Warn("Failed to journal remote transaction", "err", err)
 	}
 }
 
+// addJournaledRemote revalidates a remote transaction loaded from the journal
+// against the current head and injects it into the pool, unless its sender has
+// been inactive for longer than the configured lifetime. The sender's heartbeat
+// is restored to the journaled one.
+func (pool *TxPool) addJournaledRemote(tx *types.Transaction, stamp time.Time) error {
+	if time.Since(stamp) > pool.config.Lifetime {
+		return errExpiredTransaction
+	}
+	if err := pool.AddRemote(tx); err != nil {
+		return err
+	}
+	from, _ := types.Sender(pool.signer, tx) // already validated
+
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	if pool.all[tx.Hash()] != nil && !pool.locals.contains(from) {
+		pool.beats[from] = stamp
+	}
+	return nil
+}
+
 // promoteTx adds a transaction to the pending (processable) list of transactions.
 //
 // Note, this method assumes the pool lock is held!
@@ -726,6 +892,7 @@ func (pool *TxPool) promoteTx(addr common.Address, hash common.Hash, tx *types.T
 		pool.priced.Removed()
 
 		pendingDiscardCounter.Inc(1)
+		pool.dropTx(tx, TxDropReplaced, list.txs.Get(tx.Nonce()).Hash())
 		return
 	}
 	// Otherwise discard any previous transaction and mark this
@@ -734,6 +901,7 @@ func (pool *TxPool) promoteTx(addr common.Address, hash common.Hash, tx *types.T
 		pool.priced.Removed()
 
 		pendingReplaceCounter.Inc(1)
+		pool.dropTx(old, TxDropReplaced, hash)
 	}
 	// Failsafe to work around direct pending inserts (tests)
 	if pool.all[hash] == nil {
@@ -744,7 +912,7 @@ func (pool *TxPool) promoteTx(addr common.Address, hash common.Hash, tx *types.T
 	pool.beats[addr] = time.Now()
 	pool.pendingState.SetNonce(addr, tx.Nonce()+1)
 
-	go pool.txFeed.Send(TxPreEvent{tx})
+	go pool.txFeed.Send(TxPreEvent{Tx: tx, Private: pool.isPrivate(hash)})
 }
 
 // AddLocal enqueues a single transaction into the pool if it is valid, marking
@@ -775,6 +943,89 @@ func (pool *TxPool) AddRemotes(txs []*types.Transaction) []error {
 	return pool.addTxs(txs, false)
 }
 
+// AddPrivate enqueues a single transaction into the pool if it is valid, marking
+// the sender as a local one, and the transaction as private: it is only offered
+// to the local miner, never propagated to the network nor journaled. If it isn't
+// included in a block up to and including the expiry block, it is dropped.
+func (pool *TxPool) AddPrivate(tx *types.Transaction, expiry uint64) error {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	if head := pool.chain.CurrentBlock().NumberU64(); head >= expiry {
+		return ErrPrivateTxExpired
+	}
+	// Mark the transaction private before insertion, so no event announces it as
+	// public, but never turn an already known transaction private
+	hash := tx.Hash()
+	if pool.all[hash] != nil {
+		log.Trace("Discarding already known transaction", "hash", hash)
+		return fmt.Errorf("known transaction: %x", hash)
+	}
+	pool.private[hash] = expiry
+
+	replace, err := pool.add(tx, !pool.config.NoLocals)
+	if err != nil {
+		delete(pool.private, hash)
+		return err
+	}
+	if !replace {
+		from, _ := types.Sender(pool.signer, tx) // already validated
+		pool.promoteExecutables([]common.Address{from})
+	}
+	return nil
+}
+
+// IsPrivate returns whether a transaction submitted as private must not be
+// propagated to the network, also after it left the pool, until its expiry.
+func (pool *TxPool) IsPrivate(hash common.Hash) bool {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	return pool.isPrivate(hash)
+}
+
+// isPrivate returns whether a transaction must not be propagated to the network.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) isPrivate(hash common.Hash) bool {
+	_, ok := pool.private[hash]
+	return ok
+}
+
+// PrivateStats retrieves the number of private transactions in the pool.
+func (pool *TxPool) PrivateStats() int {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	count := 0
+	for hash := range pool.private {
+		if pool.all[hash] != nil {
+			count++
+		}
+	}
+	return count
+}
+
+// expirePrivates drops the private transactions that weren't included in a block
+// up to their expiry. The private marks of transactions that left the pool, e.g.
+// by being included, are retained until the expiry too, so that a reorg
+// reinjecting them doesn't turn them public.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) expirePrivates(head uint64) {
+	for hash, expiry := range pool.private {
+		if head < expiry {
+			continue
+		}
+		if tx := pool.all[hash]; tx != nil {
+			log.Trace("Removed expired private transaction", "hash", hash, "expiry", expiry)
+			pool.removeTx(hash)
+			pool.dropTx(tx, TxDropExpired, common.Hash{})
+		}
+		delete(pool.private, hash)
+	}
+}
+
 // addTx enqueues a single transaction into the pool if it is valid.
 func (pool *TxPool) addTx(tx *types.Transaction, local bool) error {
 	pool.mu.Lock()
@@ -857,6 +1108,12 @@ func (pool *TxPool) Get(hash common.Hash) *types.Transaction {
 	return pool.all[hash]
 }
 
+// dropTx notifies any subsystems that a transaction was dropped from the pool
+// for the given reason.
+func (pool *TxPool) dropTx(tx *types.Transaction, reason TxDropReason, replacement common.Hash) {
+	go pool.dropFeed.Send(TxDropEvent{Tx: tx, Reason: reason, Replacement: replacement})
+}
+
 // removeTx removes a single transaction from the queue, moving all subsequent
 // transactions back to the future queue.
 func (pool *TxPool) removeTx(hash common.Hash) {
@@ -932,6 +1189,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 			delete(pool.all, hash)
 			pool.priced.Removed()
 			queuedNofundsCounter.Inc(1)
+			pool.dropTx(tx, TxDropInvalidated, common.Hash{})
 		}
 		// Gather all executable transactions and promote them
 		for _, tx := range list.Ready(pool.pendingState.GetNonce(addr)) {
@@ -947,6 +1205,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 				pool.priced.Removed()
 				queuedRateLimitCounter.Inc(1)
 				log.Trace("Removed cap-exceeding queued transaction", "hash", hash)
+				pool.dropTx(tx, TxDropEvicted, common.Hash{})
 			}
 		}
 		// Delete the entire queue entry if it became empty.
@@ -996,6 +1255,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 								pool.pendingState.SetNonce(offenders[i], nonce)
 							}
 							log.Trace("Removed fairness-exceeding pending transaction", "hash", hash)
+							pool.dropTx(tx, TxDropEvicted, common.Hash{})
 						}
 						pending--
 					}
@@ -1018,6 +1278,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 							pool.pendingState.SetNonce(addr, nonce)
 						}
 						log.Trace("Removed fairness-exceeding pending transaction", "hash", hash)
+						pool.dropTx(tx, TxDropEvicted, common.Hash{})
 					}
 					pending--
 				}
@@ -1051,6 +1312,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 			if size := uint64(list.Len()); size <= drop {
 				for _, tx := range list.Flatten() {
 					pool.removeTx(tx.Hash())
+					pool.dropTx(tx, TxDropEvicted, common.Hash{})
 				}
 				drop -= size
 				queuedRateLimitCounter.Inc(int64(size))
@@ -1060,6 +1322,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 			txs := list.Flatten()
 			for i := len(txs) - 1; i >= 0 && drop > 0; i-- {
 				pool.removeTx(txs[i].Hash())
+				pool.dropTx(txs[i], TxDropEvicted, common.Hash{})
 				drop--
 				queuedRateLimitCounter.Inc(1)
 			}
@@ -1090,6 +1353,7 @@ func (pool *TxPool) demoteUnexecutables() {
 			delete(pool.all, hash)
 			pool.priced.Removed()
 			pendingNofundsCounter.Inc(1)
+			pool.dropTx(tx, TxDropInvalidated, common.Hash{})
 		}
 		for _, tx := range invalids {
 			hash := tx.Hash()
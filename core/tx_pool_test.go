@@ -1449,6 +1449,71 @@ func TestTransactionReplacement(t *testing.T) {
 	}
 }
 
+// Tests that transactions dropped from the pool are announced along with the
+// reason of the drop and, if replaced, the replacing transaction.
+func TestTransactionDropEvents(t *testing.T) {
+	t.Parallel()
+
+	db, _ := ruedb.NewMemDatabase()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db))
+	blockchain := &testBlockChain{statedb, big.NewInt(1000000), new(event.Feed)}
+
+	config := testTxPoolConfig
+	config.AccountQueue = 1
+
+	pool := NewTxPool(config, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	drops := make(chan TxDropEvent, 32)
+	sub := pool.SubscribeTxDropEvent(drops)
+	defer sub.Unsubscribe()
+
+	replacer, _ := crypto.GenerateKey()
+	spender, _ := crypto.GenerateKey()
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(replacer.PublicKey), big.NewInt(1000000000))
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(spender.PublicKey), big.NewInt(1000000000))
+
+	var (
+		replaced    = pricedTransaction(0, big.NewInt(100000), big.NewInt(1), replacer)
+		replacement = pricedTransaction(0, big.NewInt(100000), big.NewInt(2), replacer)
+		kept        = pricedTransaction(2, big.NewInt(100000), big.NewInt(1), replacer)
+		capped      = pricedTransaction(3, big.NewInt(100000), big.NewInt(1), replacer)
+		unpayable   = pricedTransaction(0, big.NewInt(100000), big.NewInt(1), spender)
+		cheap       = pricedTransaction(1, big.NewInt(100000), big.NewInt(2), replacer)
+	)
+	for _, tx := range []*types.Transaction{replaced, replacement, kept, capped, unpayable, cheap} {
+		pool.AddRemote(tx)
+	}
+	// Drain the spender's funds and raise the minimum price to drop the rest
+	statedb.SetBalance(crypto.PubkeyToAddress(spender.PublicKey), new(big.Int))
+	pool.lockedReset(nil, nil)
+	pool.SetGasPrice(big.NewInt(3))
+
+	want := map[common.Hash]TxDropEvent{
+		replaced.Hash():    {Tx: replaced, Reason: TxDropReplaced, Replacement: replacement.Hash()},
+		capped.Hash():      {Tx: capped, Reason: TxDropEvicted},
+		unpayable.Hash():   {Tx: unpayable, Reason: TxDropInvalidated},
+		replacement.Hash(): {Tx: replacement, Reason: TxDropUnderpriced},
+		cheap.Hash():       {Tx: cheap, Reason: TxDropUnderpriced},
+		kept.Hash():        {Tx: kept, Reason: TxDropUnderpriced},
+	}
+	for len(want) > 0 {
+		select {
+		case ev := <-drops:
+			expect, ok := want[ev.Tx.Hash()]
+			if !ok {
+				t.Fatalf("unexpected drop event: %x (%s)", ev.Tx.Hash(), ev.Reason)
+			}
+			if ev.Reason != expect.Reason || ev.Replacement != expect.Replacement {
+				t.Errorf("drop event mismatch for %x: have %s/%x, want %s/%x", ev.Tx.Hash(), ev.Reason, ev.Replacement, expect.Reason, expect.Replacement)
+			}
+			delete(want, ev.Tx.Hash())
+		case <-time.After(time.Second):
+			t.Fatalf("missing drop events: %d", len(want))
+		}
+	}
+}
+
 // Tests that local transactions are journaled to disk, but remote transactions
 // get discarded between restarts.
 func TestTransactionJournaling(t *testing.T)         { testTransactionJournaling(t, false) }
@@ -1563,6 +1628,252 @@ func testTransactionJournaling(t *testing.T, nolocals bool) {
 	pool.Stop()
 }
 
+// Tests that private transactions are announced as such, are never journaled,
+// are pending for inclusion like any other and get dropped once expired.
+func TestTransactionPrivate(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	events := make(chan TxPreEvent, 8)
+	sub := pool.SubscribeTxPreEvent(events)
+	defer sub.Unsubscribe()
+
+	drops := make(chan TxDropEvent, 8)
+	dropSub := pool.SubscribeTxDropEvent(drops)
+	defer dropSub.Unsubscribe()
+
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	pool.currentState.AddBalance(from, big.NewInt(1000000000))
+
+	// Private transactions expiring at or before the current head are rejected
+	tx := transaction(0, big.NewInt(100000), key)
+	if err := pool.AddPrivate(tx, 0); err != ErrPrivateTxExpired {
+		t.Fatalf("expired transaction error mismatch: have %v, want %v", err, ErrPrivateTxExpired)
+	}
+	if err := pool.AddPrivate(tx, 5); err != nil {
+		t.Fatalf("failed to add private transaction: %v", err)
+	}
+	if err := pool.AddPrivate(tx, 5); err == nil {
+		t.Fatalf("known transaction re-added")
+	}
+	select {
+	case ev := <-events:
+		if ev.Tx.Hash() != tx.Hash() || !ev.Private {
+			t.Fatalf("event mismatch: have %x (private %v), want %x (private)", ev.Tx.Hash(), ev.Private, tx.Hash())
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("private transaction not announced")
+	}
+	if !pool.IsPrivate(tx.Hash()) {
+		t.Fatalf("transaction not marked private")
+	}
+	if count := pool.PrivateStats(); count != 1 {
+		t.Fatalf("private transaction count mismatch: have %d, want %d", count, 1)
+	}
+	// Private transactions are minable, but never journaled
+	if pending, _ := pool.Pending(); len(pending[from]) != 1 {
+		t.Fatalf("private transaction not pending")
+	}
+	if local := pool.local(); len(local[from]) != 0 {
+		t.Fatalf("private transaction scheduled for journaling")
+	}
+	// Advance the chain up to the expiry and check that the transaction is dropped
+	pool.lockedReset(nil, &types.Header{Number: big.NewInt(4), GasLimit: big.NewInt(1000000)})
+	if !pool.IsPrivate(tx.Hash()) || pool.Get(tx.Hash()) == nil {
+		t.Fatalf("private transaction dropped before expiry")
+	}
+	pool.lockedReset(nil, &types.Header{Number: big.NewInt(5), GasLimit: big.NewInt(1000000)})
+	if pool.IsPrivate(tx.Hash()) || pool.Get(tx.Hash()) != nil {
+		t.Fatalf("expired private transaction not dropped")
+	}
+	if count := pool.PrivateStats(); count != 0 {
+		t.Fatalf("private transaction count mismatch: have %d, want %d", count, 0)
+	}
+	select {
+	case ev := <-drops:
+		if ev.Tx.Hash() != tx.Hash() || ev.Reason != TxDropExpired {
+			t.Fatalf("drop event mismatch: have %x/%s, want %x/%s", ev.Tx.Hash(), ev.Reason, tx.Hash(), TxDropExpired)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("expired private transaction drop not announced")
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// reorgBlockChain is a test chain resolving blocks by hash, so that the pool can
+// walk the old and new branches of a reorg.
+type reorgBlockChain struct {
+	*testBlockChain
+	blocks map[common.Hash]*types.Block
+}
+
+func (bc *reorgBlockChain) GetBlock(hash common.Hash, number uint64) *types.Block {
+	return bc.blocks[hash]
+}
+
+// Tests that private transactions included in a block which is then reorged out
+// are reinjected into the pool still marked private, and only forgotten once
+// their expiry passes.
+func TestTransactionPrivateReorg(t *testing.T) {
+	t.Parallel()
+
+	db, _ := ruedb.NewMemDatabase()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db))
+	chain := &reorgBlockChain{
+		testBlockChain: &testBlockChain{statedb, big.NewInt(1000000), new(event.Feed)},
+		blocks:         make(map[common.Hash]*types.Block),
+	}
+	pool := NewTxPool(testTxPoolConfig, params.TestChainConfig, chain)
+	defer pool.Stop()
+
+	key, _ := crypto.GenerateKey()
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	pool.currentState.AddBalance(from, big.NewInt(1000000000))
+
+	tx := transaction(0, big.NewInt(100000), key)
+	if err := pool.AddPrivate(tx, 5); err != nil {
+		t.Fatalf("failed to add private transaction: %v", err)
+	}
+	events := make(chan TxPreEvent, 8)
+	sub := pool.SubscribeTxPreEvent(events)
+	defer sub.Unsubscribe()
+
+	// Create a block including the transaction and a competing longer branch
+	block := func(parent *types.Block, extra byte, txs types.Transactions) *types.Block {
+		header := &types.Header{
+			ParentHash: parent.Hash(),
+			Number:     new(big.Int).Add(parent.Number(), common.Big1),
+			GasLimit:   big.NewInt(1000000),
+			Extra:      []byte{extra},
+		}
+		b := types.NewBlock(header, txs, nil, nil)
+		chain.blocks[b.Hash()] = b
+		return b
+	}
+	genesis := chain.CurrentBlock()
+	chain.blocks[genesis.Hash()] = genesis
+
+	mined := block(genesis, 'a', types.Transactions{tx})
+	side := block(block(genesis, 'b', nil), 'b', nil)
+
+	// Include the transaction, it should leave the pool but stay private
+	statedb.SetNonce(from, 1)
+	pool.lockedReset(genesis.Header(), mined.Header())
+	if pool.Get(tx.Hash()) != nil {
+		t.Fatalf("included private transaction still pooled")
+	}
+	if !pool.IsPrivate(tx.Hash()) {
+		t.Fatalf("included private transaction forgotten before expiry")
+	}
+	if count := pool.PrivateStats(); count != 0 {
+		t.Fatalf("private transaction count mismatch: have %d, want %d", count, 0)
+	}
+	// Reorg the including block out and check the transaction is private again
+	statedb.SetNonce(from, 0)
+	pool.lockedReset(mined.Header(), side.Header())
+	if pool.Get(tx.Hash()) == nil {
+		t.Fatalf("reorged private transaction not reinjected")
+	}
+	if !pool.IsPrivate(tx.Hash()) {
+		t.Fatalf("reorged private transaction turned public")
+	}
+	select {
+	case ev := <-events:
+		if ev.Tx.Hash() != tx.Hash() || !ev.Private {
+			t.Fatalf("event mismatch: have %x (private %v), want %x (private)", ev.Tx.Hash(), ev.Private, tx.Hash())
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("reinjected transaction not announced")
+	}
+	// Once the expiry passes, the transaction is dropped and forgotten
+	pool.lockedReset(nil, &types.Header{Number: big.NewInt(5), GasLimit: big.NewInt(1000000)})
+	if pool.IsPrivate(tx.Hash()) || pool.Get(tx.Hash()) != nil {
+		t.Fatalf("expired private transaction not dropped")
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that remote transactions are journaled if requested, restored after a
+// restart unless their senders have been inactive for longer than the lifetime,
+// and that they are revalidated against the current state when loaded.
+func TestTransactionRemoteJournaling(t *testing.T) {
+	t.Parallel()
+
+	// Create a temporary file for the journal
+	file, err := ioutil.TempFile("", "")
+	if err != nil {
+		t.Fatalf("failed to create temporary journal: %v", err)
+	}
+	journal := file.Name()
+	defer os.Remove(journal)
+
+	// Clean up the temporary file, we only need the path for now
+	file.Close()
+	os.Remove(journal)
+
+	// Create the original pool to inject transaction into the journal
+	db, _ := ruedb.NewMemDatabase()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db))
+	blockchain := &testBlockChain{statedb, big.NewInt(1000000), new(event.Feed)}
+
+	config := testTxPoolConfig
+	config.JournalRemotes = true
+	config.RemoteJournal = journal
+
+	pool := NewTxPool(config, params.TestChainConfig, blockchain)
+
+	local, _ := crypto.GenerateKey()
+	remote, _ := crypto.GenerateKey()
+
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(local.PublicKey), big.NewInt(1000000000))
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(remote.PublicKey), big.NewInt(1000000000))
+
+	// Add a local and three remote transactions, the last one non-executable
+	if err := pool.AddLocal(pricedTransaction(0, big.NewInt(100000), big.NewInt(1), local)); err != nil {
+		t.Fatalf("failed to add local transaction: %v", err)
+	}
+	for _, nonce := range []uint64{0, 1, 3} {
+		if err := pool.AddRemote(pricedTransaction(nonce, big.NewInt(100000), big.NewInt(1), remote)); err != nil {
+			t.Fatalf("failed to add remote transaction %d: %v", nonce, err)
+		}
+	}
+	pool.Stop()
+
+	// Include the first remote transaction, restart the pool and ensure the
+	// others survive while the local one is not taken by the remote journal
+	statedb.SetNonce(crypto.PubkeyToAddress(remote.PublicKey), 1)
+	blockchain = &testBlockChain{statedb, big.NewInt(1000000), new(event.Feed)}
+
+	pool = NewTxPool(config, params.TestChainConfig, blockchain)
+
+	pending, queued := pool.Stats()
+	if pending != 1 {
+		t.Fatalf("pending transactions mismatched: have %d, want %d", pending, 1)
+	}
+	if queued != 1 {
+		t.Fatalf("queued transactions mismatched: have %d, want %d", queued, 1)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+	pool.Stop()
+
+	// Restart with a lifetime already exceeded and ensure everything is dropped
+	config.Lifetime = time.Nanosecond
+	pool = NewTxPool(config, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	if pending, queued := pool.Stats(); pending+queued != 0 {
+		t.Fatalf("expired transactions restored: %d pending, %d queued", pending, queued)
+	}
+}
+
 // TestTransactionStatusCheck tests that the pool can correctly retrieve the
 // pending status of individual transactions.
 func TestTransactionStatusCheck(t *testing.T) {
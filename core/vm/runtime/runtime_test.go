@@ -25,6 +25,8 @@ import (
 	"github.com/Rue-Foundation/go-rue/common"
 	"github.com/Rue-Foundation/go-rue/core/state"
 	"github.com/Rue-Foundation/go-rue/core/vm"
+	"github.com/Rue-Foundation/go-rue/crypto"
+	"github.com/Rue-Foundation/go-rue/params"
 	"github.com/Rue-Foundation/go-rue/ruedb"
 )
 
@@ -54,6 +56,9 @@ func TestDefaults(t *testing.T) {
 	if cfg.BlockNumber == nil {
 		t.Error("expected block number to be non nil")
 	}
+	if !cfg.ChainConfig.IsConstantinople(cfg.BlockNumber) {
+		t.Error("expected constantinople to be active")
+	}
 }
 
 func TestEVM(t *testing.T) {
@@ -117,6 +122,74 @@ func TestCall(t *testing.T) {
 	}
 }
 
+// Tests that CREATE2 deploys to the salted address and EXTCODEHASH reports the
+// code hash of the new contract, but only once Constantinople is activated.
+func TestConstantinopleCreate2ExtCodeHash(t *testing.T) {
+	code := []byte{
+		byte(vm.PUSH1), 0x2a, // salt
+		byte(vm.PUSH1), 0, // size
+		byte(vm.PUSH1), 0, // offset
+		byte(vm.PUSH1), 0, // value
+		byte(vm.CREATE2),
+		byte(vm.DUP1),
+		byte(vm.EXTCODEHASH),
+		byte(vm.PUSH1), 32,
+		byte(vm.MSTORE),
+		byte(vm.PUSH1), 0,
+		byte(vm.MSTORE),
+		byte(vm.PUSH1), 64,
+		byte(vm.PUSH1), 0,
+		byte(vm.RETURN),
+	}
+	config := &params.ChainConfig{
+		ChainId:        big.NewInt(1),
+		HomesteadBlock: new(big.Int),
+		EIP150Block:    new(big.Int),
+		EIP155Block:    new(big.Int),
+		EIP158Block:    new(big.Int),
+		ByzantiumBlock: new(big.Int),
+	}
+	if _, _, err := Execute(code, nil, &Config{ChainConfig: config}); err == nil {
+		t.Fatalf("constantinople opcodes executed before the fork")
+	}
+	config.ConstantinopleBlock = new(big.Int)
+
+	ret, _, err := Execute(code, nil, &Config{ChainConfig: config})
+	if err != nil {
+		t.Fatal("didn't expect error", err)
+	}
+	want := crypto.CreateAddress2(common.StringToAddress("contract"), common.BigToHash(big.NewInt(0x2a)), nil)
+	if addr := common.BytesToAddress(ret[:32]); addr != want {
+		t.Errorf("contract address mismatch: have %x, want %x", addr, want)
+	}
+	if hash := common.BytesToHash(ret[32:]); hash != crypto.Keccak256Hash(nil) {
+		t.Errorf("code hash mismatch: have %x, want %x", hash, crypto.Keccak256Hash(nil))
+	}
+	// Existing but empty accounts hash to zero, just as non-existent ones
+	db, _ := ruedb.NewMemDatabase()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db))
+	statedb.CreateAccount(common.HexToAddress("0x0a"))
+
+	for _, addr := range []byte{0x0a, 0x0b} {
+		code := []byte{
+			byte(vm.PUSH1), addr,
+			byte(vm.EXTCODEHASH),
+			byte(vm.PUSH1), 0,
+			byte(vm.MSTORE),
+			byte(vm.PUSH1), 32,
+			byte(vm.PUSH1), 0,
+			byte(vm.RETURN),
+		}
+		ret, _, err := Execute(code, nil, &Config{ChainConfig: config, State: statedb})
+		if err != nil {
+			t.Fatal("didn't expect error", err)
+		}
+		if hash := common.BytesToHash(ret); hash != (common.Hash{}) {
+			t.Errorf("code hash of empty account %#x mismatch: have %x, want zero", addr, hash)
+		}
+	}
+}
+
 func BenchmarkCall(b *testing.B) {
 	var definition = `[{"constant":true,"inputs":[],"name":"seller","outputs":[{"name":"","type":"address"}],"type":"function"},{"constant":false,"inputs":[],"name":"abort","outputs":[],"type":"function"},{"constant":true,"inputs":[],"name":"value","outputs":[{"name":"","type":"uint256"}],"type":"function"},{"constant":false,"inputs":[],"name":"refund","outputs":[],"type":"function"},{"constant":true,"inputs":[],"name":"buyer","outputs":[{"name":"","type":"address"}],"type":"function"},{"constant":false,"inputs":[],"name":"confirmReceived","outputs":[],"type":"function"},{"constant":true,"inputs":[],"name":"state","outputs":[{"name":"","type":"uint8"}],"type":"function"},{"constant":false,"inputs":[],"name":"confirmPurchase","outputs":[],"type":"function"},{"inputs":[],"type":"constructor"},{"anonymous":false,"inputs":[],"name":"Aborted","type":"event"},{"anonymous":false,"inputs":[],"name":"PurchaseConfirmed","type":"event"},{"anonymous":false,"inputs":[],"name":"ItemReceived","type":"event"},{"anonymous":false,"inputs":[],"name":"Refunded","type":"event"}]`
 
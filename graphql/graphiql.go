@@ -0,0 +1,104 @@
+// Copyright 2018 The go-ruereum Authors
+// This file is part of the go-ruereum library.
+//
+// The go-ruereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ruereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ruereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package graphql
+
+import (
+	"net/http"
+)
+
+// GraphiQL is an in-browser IDE for exploring GraphQL APIs.
+// This handler returns GraphiQL when requested.
+//
+// For more information, see https://github.com/graphql/graphiql.
+type GraphiQL struct{}
+
+func respond(w http.ResponseWriter, body []byte, code int) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(code)
+	w.Write(body)
+}
+
+func errorJSON(msg string) []byte {
+	return []byte(`{"error": "` + msg + `"}`)
+}
+
+func (h GraphiQL) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respond(w, errorJSON("only GET requests are supported"), http.StatusMethodNotAllowed)
+		return
+	}
+	if r.URL.Path != "/" {
+		respond(w, errorJSON("not found"), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html")
+	w.Write(graphiql)
+}
+
+var graphiql = []byte(`
+<!DOCTYPE html>
+<html>
+  <head>
+    <title>GraphiQL</title>
+    <link href="https://unpkg.com/graphiql@0.12.0/graphiql.css" rel="stylesheet" />
+    <style>
+      body {
+        height: 100%;
+        margin: 0;
+        width: 100%;
+        overflow: hidden;
+      }
+      #graphiql {
+        height: 100vh;
+      }
+    </style>
+    <script src="https://unpkg.com/es6-promise@4.1.1/dist/es6-promise.auto.min.js"></script>
+    <script src="https://unpkg.com/whatwg-fetch@2.0.3/fetch.js"></script>
+    <script src="https://unpkg.com/react@16.2.0/umd/react.production.min.js"></script>
+    <script src="https://unpkg.com/react-dom@16.2.0/umd/react-dom.production.min.js"></script>
+    <script src="https://unpkg.com/graphiql@0.12.0/graphiql.js"></script>
+  </head>
+  <body>
+    <div id="graphiql">Loading...</div>
+    <script>
+      function graphQLFetcher(graphQLParams) {
+        return fetch("/graphql", {
+          method: "post",
+          body: JSON.stringify(graphQLParams),
+          credentials: "include",
+          headers: {
+            "Content-Type": "application/json"
+          }
+        }).then(function (response) {
+          return response.text();
+        }).then(function (responseBody) {
+          try {
+            return JSON.parse(responseBody);
+          } catch (error) {
+            return responseBody;
+          }
+        });
+      }
+      ReactDOM.render(
+        React.createElement(GraphiQL, {fetcher: graphQLFetcher}),
+        document.getElementById("graphiql")
+      );
+    </script>
+  </body>
+</html>
+`)
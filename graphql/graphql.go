@@ -0,0 +1,1010 @@
+// Copyright 2018 The go-ruereum Authors
+// This file is part of the go-ruereum library.
+//
+// The go-ruereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ruereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ruereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package graphql provides a GraphQL interface to Ruereum node data.
+package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/Rue-Foundation/go-rue/common"
+	"github.com/Rue-Foundation/go-rue/common/hexutil"
+	"github.com/Rue-Foundation/go-rue/core"
+	"github.com/Rue-Foundation/go-rue/core/state"
+	"github.com/Rue-Foundation/go-rue/core/types"
+	"github.com/Rue-Foundation/go-rue/core/vm"
+	"github.com/Rue-Foundation/go-rue/internal/rueapi"
+	"github.com/Rue-Foundation/go-rue/rlp"
+	"github.com/Rue-Foundation/go-rue/rpc"
+	"github.com/Rue-Foundation/go-rue/rue/filters"
+)
+
+var (
+	errBlockInvariant   = errors.New("block objects must be instantiated with at least one of num or hash")
+	errBlockSelector    = errors.New("only one of number or hash may be specified")
+	errStateNotFound    = errors.New("state not found")
+	errFilterNotSupport = errors.New("log filtering not supported by the backend")
+	errBlockRange       = errors.New("invalid block range")
+	errBlockRangeLimit  = fmt.Errorf("block range exceeds %d blocks", maxBlockRange)
+)
+
+// maxBlockRange is the maximum number of blocks a single blocks query may return.
+const maxBlockRange = 1024
+
+// Long is a 64 bit integer, sidestepping the 32 bit limit of GraphQL's native
+// Int type for block numbers and nonces.
+type Long int64
+
+// ImplementsGraphQLType returns true if Long implements the provided GraphQL type.
+func (b Long) ImplementsGraphQLType(name string) bool { return name == "Long" }
+
+// UnmarshalGraphQL unmarshals the provided GraphQL query data. Strings may be
+// either 0x-prefixed hexadecimal or decimal.
+func (b *Long) UnmarshalGraphQL(input interface{}) error {
+	switch input := input.(type) {
+	case string:
+		var (
+			value uint64
+			err   error
+		)
+		if len(input) >= 2 && input[0] == '0' && (input[1] == 'x' || input[1] == 'X') {
+			value, err = hexutil.DecodeUint64(input)
+		} else {
+			value, err = strconv.ParseUint(input, 10, 63)
+		}
+		if err != nil {
+			return err
+		}
+		*b = Long(value)
+	case int32:
+		*b = Long(input)
+	case float64:
+		*b = Long(input)
+	default:
+		return fmt.Errorf("unexpected type %T for Long", input)
+	}
+	return nil
+}
+
+// Account represents a Ruereum account at a particular block.
+type Account struct {
+	backend     rueapi.Backend
+	address     common.Address
+	blockNumber rpc.BlockNumber
+}
+
+// getState fetches the state of the block the account is looked up in.
+func (a *Account) getState(ctx context.Context) (*state.StateDB, error) {
+	state, _, err := a.backend.StateAndHeaderByNumber(ctx, a.blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	if state == nil {
+		return nil, errStateNotFound
+	}
+	return state, nil
+}
+
+func (a *Account) Address(ctx context.Context) (common.Address, error) {
+	return a.address, nil
+}
+
+func (a *Account) Balance(ctx context.Context) (hexutil.Big, error) {
+	state, err := a.getState(ctx)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*state.GetBalance(a.address)), nil
+}
+
+func (a *Account) TransactionCount(ctx context.Context) (Long, error) {
+	state, err := a.getState(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return Long(state.GetNonce(a.address)), nil
+}
+
+func (a *Account) Code(ctx context.Context) (hexutil.Bytes, error) {
+	state, err := a.getState(ctx)
+	if err != nil {
+		return hexutil.Bytes{}, err
+	}
+	return hexutil.Bytes(state.GetCode(a.address)), nil
+}
+
+func (a *Account) Storage(ctx context.Context, args struct{ Slot common.Hash }) (common.Hash, error) {
+	state, err := a.getState(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return state.GetState(a.address, args.Slot), nil
+}
+
+// Log represents an individual log message. All arguments are mandatory.
+type Log struct {
+	backend     rueapi.Backend
+	transaction *Transaction
+	log         *types.Log
+}
+
+func (l *Log) Transaction(ctx context.Context) *Transaction {
+	return l.transaction
+}
+
+func (l *Log) Account(ctx context.Context, args BlockNumberArgs) *Account {
+	return &Account{
+		backend:     l.backend,
+		address:     l.log.Address,
+		blockNumber: args.Number(),
+	}
+}
+
+func (l *Log) Index(ctx context.Context) int32 {
+	return int32(l.log.Index)
+}
+
+func (l *Log) Topics(ctx context.Context) []common.Hash {
+	return l.log.Topics
+}
+
+func (l *Log) Data(ctx context.Context) hexutil.Bytes {
+	return hexutil.Bytes(l.log.Data)
+}
+
+// Transaction represents a Ruereum transaction. Only the hash is mandatory, all
+// other fields are lazily resolved on demand.
+type Transaction struct {
+	backend rueapi.Backend
+	hash    common.Hash
+	tx      *types.Transaction
+	block   *Block
+	index   uint64
+}
+
+// resolve returns the internal transaction object, fetching it if needed.
+func (t *Transaction) resolve(ctx context.Context) (*types.Transaction, error) {
+	if t.tx == nil {
+		if tx, blockHash, _, index := core.GetTransaction(t.backend.ChainDb(), t.hash); tx != nil {
+			t.tx = tx
+			t.block = &Block{backend: t.backend, hash: blockHash}
+			t.index = index
+		} else {
+			t.tx = t.backend.GetPoolTransaction(t.hash)
+		}
+	}
+	return t.tx, nil
+}
+
+func (t *Transaction) Hash(ctx context.Context) common.Hash {
+	return t.hash
+}
+
+func (t *Transaction) InputData(ctx context.Context) (hexutil.Bytes, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return hexutil.Bytes{}, err
+	}
+	return hexutil.Bytes(tx.Data()), nil
+}
+
+func (t *Transaction) Gas(ctx context.Context) (hexutil.Big, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*tx.Gas()), nil
+}
+
+func (t *Transaction) GasPrice(ctx context.Context) (hexutil.Big, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*tx.GasPrice()), nil
+}
+
+func (t *Transaction) Value(ctx context.Context) (hexutil.Big, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*tx.Value()), nil
+}
+
+func (t *Transaction) Nonce(ctx context.Context) (Long, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return 0, err
+	}
+	return Long(tx.Nonce()), nil
+}
+
+func (t *Transaction) To(ctx context.Context, args BlockNumberArgs) (*Account, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return nil, err
+	}
+	to := tx.To()
+	if to == nil {
+		return nil, nil
+	}
+	return &Account{
+		backend:     t.backend,
+		address:     *to,
+		blockNumber: args.Number(),
+	}, nil
+}
+
+func (t *Transaction) From(ctx context.Context, args BlockNumberArgs) (*Account, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return nil, err
+	}
+	// Derive the sender with the signer of the including block, or the current
+	// one if the transaction is still pending
+	number := t.backend.CurrentBlock().Number()
+	if t.block != nil {
+		block, err := t.block.resolve(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if block != nil {
+			number = block.Number()
+		}
+	}
+	from, err := types.Sender(types.MakeSigner(t.backend.ChainConfig(), number), tx)
+	if err != nil {
+		return nil, err
+	}
+	return &Account{
+		backend:     t.backend,
+		address:     from,
+		blockNumber: args.Number(),
+	}, nil
+}
+
+func (t *Transaction) Block(ctx context.Context) (*Block, error) {
+	if _, err := t.resolve(ctx); err != nil {
+		return nil, err
+	}
+	return t.block, nil
+}
+
+func (t *Transaction) Index(ctx context.Context) (*int32, error) {
+	if _, err := t.resolve(ctx); err != nil {
+		return nil, err
+	}
+	if t.block == nil {
+		return nil, nil
+	}
+	index := int32(t.index)
+	return &index, nil
+}
+
+// getReceipt returns the receipt associated with this transaction, if any.
+func (t *Transaction) getReceipt(ctx context.Context) (*types.Receipt, error) {
+	if _, err := t.resolve(ctx); err != nil {
+		return nil, err
+	}
+	if t.block == nil {
+		return nil, nil
+	}
+	receipts, err := t.block.resolveReceipts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if t.index >= uint64(len(receipts)) {
+		return nil, nil
+	}
+	return receipts[t.index], nil
+}
+
+func (t *Transaction) Status(ctx context.Context) (*Long, error) {
+	receipt, err := t.getReceipt(ctx)
+	if err != nil || receipt == nil {
+		return nil, err
+	}
+	status := Long(receipt.Status)
+	return &status, nil
+}
+
+func (t *Transaction) GasUsed(ctx context.Context) (*hexutil.Big, error) {
+	receipt, err := t.getReceipt(ctx)
+	if err != nil || receipt == nil {
+		return nil, err
+	}
+	return (*hexutil.Big)(receipt.GasUsed), nil
+}
+
+func (t *Transaction) CumulativeGasUsed(ctx context.Context) (*hexutil.Big, error) {
+	receipt, err := t.getReceipt(ctx)
+	if err != nil || receipt == nil {
+		return nil, err
+	}
+	return (*hexutil.Big)(receipt.CumulativeGasUsed), nil
+}
+
+func (t *Transaction) CreatedContract(ctx context.Context, args BlockNumberArgs) (*Account, error) {
+	receipt, err := t.getReceipt(ctx)
+	if err != nil || receipt == nil || receipt.ContractAddress == (common.Address{}) {
+		return nil, err
+	}
+	return &Account{
+		backend:     t.backend,
+		address:     receipt.ContractAddress,
+		blockNumber: args.Number(),
+	}, nil
+}
+
+func (t *Transaction) Logs(ctx context.Context) (*[]*Log, error) {
+	receipt, err := t.getReceipt(ctx)
+	if err != nil || receipt == nil {
+		return nil, err
+	}
+	ret := make([]*Log, 0, len(receipt.Logs))
+	for _, log := range receipt.Logs {
+		ret = append(ret, &Log{
+			backend:     t.backend,
+			transaction: t,
+			log:         log,
+		})
+	}
+	return &ret, nil
+}
+
+// Block represents a Ruereum block. At least one of num or hash must be set, all
+// other fields are lazily resolved on demand.
+type Block struct {
+	backend  rueapi.Backend
+	num      *rpc.BlockNumber
+	hash     common.Hash
+	block    *types.Block
+	receipts types.Receipts
+}
+
+// resolve returns the internal block object representing this block, fetching
+// it if necessary.
+func (b *Block) resolve(ctx context.Context) (*types.Block, error) {
+	if b.block != nil {
+		return b.block, nil
+	}
+	var err error
+	switch {
+	case b.hash != (common.Hash{}):
+		b.block, err = b.backend.GetBlock(ctx, b.hash)
+	case b.num != nil:
+		b.block, err = b.backend.BlockByNumber(ctx, *b.num)
+	default:
+		return nil, errBlockInvariant
+	}
+	if b.block != nil && b.num == nil {
+		num := rpc.BlockNumber(b.block.NumberU64())
+		b.num = &num
+	}
+	return b.block, err
+}
+
+// resolveReceipts returns the list of receipts for this block, fetching them
+// if necessary.
+func (b *Block) resolveReceipts(ctx context.Context) (types.Receipts, error) {
+	if b.receipts == nil {
+		block, err := b.resolve(ctx)
+		if err != nil || block == nil {
+			return nil, err
+		}
+		receipts, err := b.backend.GetReceipts(ctx, block.Hash())
+		if err != nil {
+			return nil, err
+		}
+		b.receipts = receipts
+	}
+	return b.receipts, nil
+}
+
+// number returns the number of the block to resolve state queries against.
+func (b *Block) number(ctx context.Context) (rpc.BlockNumber, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if block == nil {
+		return 0, errors.New("block not found")
+	}
+	return *b.num, nil
+}
+
+func (b *Block) Number(ctx context.Context) (Long, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return 0, err
+	}
+	return Long(block.NumberU64()), nil
+}
+
+func (b *Block) Hash(ctx context.Context) (common.Hash, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return common.Hash{}, err
+	}
+	return block.Hash(), nil
+}
+
+func (b *Block) GasLimit(ctx context.Context) (hexutil.Big, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*block.GasLimit()), nil
+}
+
+func (b *Block) GasUsed(ctx context.Context) (hexutil.Big, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*block.GasUsed()), nil
+}
+
+func (b *Block) Parent(ctx context.Context) (*Block, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil || block.NumberU64() == 0 {
+		return nil, err
+	}
+	return &Block{
+		backend: b.backend,
+		hash:    block.ParentHash(),
+	}, nil
+}
+
+func (b *Block) Difficulty(ctx context.Context) (hexutil.Big, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*block.Difficulty()), nil
+}
+
+func (b *Block) Timestamp(ctx context.Context) (hexutil.Big, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*block.Time()), nil
+}
+
+func (b *Block) Nonce(ctx context.Context) (hexutil.Bytes, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return hexutil.Bytes{}, err
+	}
+	nonce := block.Header().Nonce
+	return hexutil.Bytes(nonce[:]), nil
+}
+
+func (b *Block) MixHash(ctx context.Context) (common.Hash, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return common.Hash{}, err
+	}
+	return block.MixDigest(), nil
+}
+
+func (b *Block) TransactionsRoot(ctx context.Context) (common.Hash, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return common.Hash{}, err
+	}
+	return block.TxHash(), nil
+}
+
+func (b *Block) StateRoot(ctx context.Context) (common.Hash, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return common.Hash{}, err
+	}
+	return block.Root(), nil
+}
+
+func (b *Block) ReceiptsRoot(ctx context.Context) (common.Hash, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return common.Hash{}, err
+	}
+	return block.ReceiptHash(), nil
+}
+
+func (b *Block) OmmerHash(ctx context.Context) (common.Hash, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return common.Hash{}, err
+	}
+	return block.UncleHash(), nil
+}
+
+func (b *Block) OmmerCount(ctx context.Context) (int32, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return 0, err
+	}
+	return int32(len(block.Uncles())), nil
+}
+
+func (b *Block) Ommers(ctx context.Context) ([]*Block, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return nil, err
+	}
+	ret := make([]*Block, 0, len(block.Uncles()))
+	for _, uncle := range block.Uncles() {
+		ret = append(ret, b.ommer(uncle))
+	}
+	return ret, nil
+}
+
+func (b *Block) OmmerAt(ctx context.Context, args struct{ Index int32 }) (*Block, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return nil, err
+	}
+	uncles := block.Uncles()
+	if args.Index < 0 || int(args.Index) >= len(uncles) {
+		return nil, nil
+	}
+	return b.ommer(uncles[args.Index]), nil
+}
+
+// ommer wraps an uncle header of the block into a header-only block resolver.
+func (b *Block) ommer(uncle *types.Header) *Block {
+	num := rpc.BlockNumber(uncle.Number.Uint64())
+	return &Block{
+		backend: b.backend,
+		num:     &num,
+		hash:    uncle.Hash(),
+		block:   types.NewBlockWithHeader(uncle),
+	}
+}
+
+func (b *Block) ExtraData(ctx context.Context) (hexutil.Bytes, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return hexutil.Bytes{}, err
+	}
+	return hexutil.Bytes(block.Extra()), nil
+}
+
+func (b *Block) LogsBloom(ctx context.Context) (hexutil.Bytes, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return hexutil.Bytes{}, err
+	}
+	return hexutil.Bytes(block.Bloom().Bytes()), nil
+}
+
+func (b *Block) TotalDifficulty(ctx context.Context) (hexutil.Big, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return hexutil.Big{}, err
+	}
+	td := b.backend.GetTd(block.Hash())
+	if td == nil {
+		return hexutil.Big{}, fmt.Errorf("total difficulty not found for block %x", block.Hash())
+	}
+	return hexutil.Big(*td), nil
+}
+
+// BlockNumberArgs encapsulates arguments to accessors that specify a block number.
+type BlockNumberArgs struct {
+	Block *Long
+}
+
+// Number returns the provided block number, or rpc.LatestBlockNumber if none
+// was provided.
+func (a BlockNumberArgs) Number() rpc.BlockNumber {
+	if a.Block != nil {
+		return rpc.BlockNumber(*a.Block)
+	}
+	return rpc.LatestBlockNumber
+}
+
+func (b *Block) Miner(ctx context.Context, args BlockNumberArgs) (*Account, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return nil, err
+	}
+	return &Account{
+		backend:     b.backend,
+		address:     block.Coinbase(),
+		blockNumber: args.Number(),
+	}, nil
+}
+
+func (b *Block) TransactionCount(ctx context.Context) (int32, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return 0, err
+	}
+	return int32(len(block.Transactions())), nil
+}
+
+func (b *Block) Transactions(ctx context.Context) ([]*Transaction, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return nil, err
+	}
+	ret := make([]*Transaction, 0, len(block.Transactions()))
+	for i, tx := range block.Transactions() {
+		ret = append(ret, &Transaction{
+			backend: b.backend,
+			hash:    tx.Hash(),
+			tx:      tx,
+			block:   b,
+			index:   uint64(i),
+		})
+	}
+	return ret, nil
+}
+
+func (b *Block) TransactionAt(ctx context.Context, args struct{ Index int32 }) (*Transaction, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return nil, err
+	}
+	txs := block.Transactions()
+	if args.Index < 0 || int(args.Index) >= len(txs) {
+		return nil, nil
+	}
+	tx := txs[args.Index]
+	return &Transaction{
+		backend: b.backend,
+		hash:    tx.Hash(),
+		tx:      tx,
+		block:   b,
+		index:   uint64(args.Index),
+	}, nil
+}
+
+// BlockFilterCriteria encapsulates criteria passed to a `logs` accessor inside
+// a block.
+type BlockFilterCriteria struct {
+	Addresses *[]common.Address // restricts matches to events created by specific contracts
+
+	// The Topic list restricts matches to particular event topics. Each event has a list
+	// of topics. Topics matches a prefix of that list. An empty element slice matches any
+	// topic. Non-empty elements represent an alternative that matches any of the
+	// contained topics.
+	//
+	// Examples:
+	// {} or nil          matches any topic list
+	// {{A}}              matches topic A in first position
+	// {{}, {B}}          matches any topic in first position, B in second position
+	// {{A}, {B}}         matches topic A in first position, B in second position
+	// {{A, B}}, {C, D}}  matches topic (A OR B) in first position, (C OR D) in second position
+	Topics *[][]common.Hash
+}
+
+// runFilter retrieves the logs matching the given criteria between two block
+// numbers, inclusive.
+func runFilter(ctx context.Context, backend rueapi.Backend, begin, end int64, addresses *[]common.Address, topics *[][]common.Hash) ([]*Log, error) {
+	fb, ok := backend.(filters.Backend)
+	if !ok {
+		return nil, errFilterNotSupport
+	}
+	var (
+		addrs  []common.Address
+		topicz [][]common.Hash
+	)
+	if addresses != nil {
+		addrs = *addresses
+	}
+	if topics != nil {
+		topicz = *topics
+	}
+	logs, err := filters.New(fb, begin, end, addrs, topicz).Logs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*Log, 0, len(logs))
+	for _, log := range logs {
+		ret = append(ret, &Log{
+			backend:     backend,
+			transaction: &Transaction{backend: backend, hash: log.TxHash},
+			log:         log,
+		})
+	}
+	return ret, nil
+}
+
+func (b *Block) Logs(ctx context.Context, args struct{ Filter BlockFilterCriteria }) ([]*Log, error) {
+	number, err := b.number(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return runFilter(ctx, b.backend, int64(number), int64(number), args.Filter.Addresses, args.Filter.Topics)
+}
+
+func (b *Block) Account(ctx context.Context, args struct{ Address common.Address }) (*Account, error) {
+	number, err := b.number(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Account{
+		backend:     b.backend,
+		address:     args.Address,
+		blockNumber: number,
+	}, nil
+}
+
+// CallData encapsulates arguments to `call` or `estimateGas`.
+// All arguments are optional.
+type CallData struct {
+	From     *common.Address // The Ruereum address the call is from.
+	To       *common.Address // The Ruereum address the call is to.
+	Gas      *hexutil.Big    // The amount of gas provided for the call.
+	GasPrice *hexutil.Big    // The price of each unit of gas, in wei.
+	Value    *hexutil.Big    // The value sent along with the call.
+	Data     *hexutil.Bytes  // Any data sent with the call.
+}
+
+// toCallArgs converts the GraphQL call data into the RPC API's call arguments.
+func (d CallData) toCallArgs() rueapi.CallArgs {
+	var args rueapi.CallArgs
+	if d.From != nil {
+		args.From = *d.From
+	}
+	args.To = d.To
+	if d.Gas != nil {
+		args.Gas = hexutil.Big(*new(big.Int).Set(d.Gas.ToInt()))
+	}
+	if d.GasPrice != nil {
+		args.GasPrice = *d.GasPrice
+	}
+	if d.Value != nil {
+		args.Value = *d.Value
+	}
+	if d.Data != nil {
+		args.Data = *d.Data
+	}
+	return args
+}
+
+// CallResult encapsulates the result of an invocation of the `call` accessor.
+type CallResult struct {
+	data    hexutil.Bytes // The return data from the call
+	gasUsed hexutil.Big   // The amount of gas used
+	status  Long          // The return status of the call - 0 for failure or 1 for success.
+}
+
+func (c *CallResult) Data() hexutil.Bytes {
+	return c.data
+}
+
+func (c *CallResult) GasUsed() hexutil.Big {
+	return c.gasUsed
+}
+
+func (c *CallResult) Status() Long {
+	return c.status
+}
+
+// doCall executes a local call against the state of the given block.
+func doCall(ctx context.Context, backend rueapi.Backend, data CallData, number rpc.BlockNumber) (*CallResult, error) {
+	result, gas, failed, err := rueapi.DoCall(ctx, backend, data.toCallArgs(), number, nil, vm.Config{})
+	if err != nil {
+		return nil, err
+	}
+	status := Long(1)
+	if failed {
+		status = 0
+	}
+	return &CallResult{
+		data:    hexutil.Bytes(result),
+		gasUsed: hexutil.Big(*gas),
+		status:  status,
+	}, nil
+}
+
+func (b *Block) Call(ctx context.Context, args struct{ Data CallData }) (*CallResult, error) {
+	number, err := b.number(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return doCall(ctx, b.backend, args.Data, number)
+}
+
+func (b *Block) EstimateGas(ctx context.Context, args struct{ Data CallData }) (hexutil.Big, error) {
+	number, err := b.number(ctx)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	gas, err := rueapi.DoEstimateGas(ctx, b.backend, args.Data.toCallArgs(), number, nil)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return *gas, nil
+}
+
+// Pending represents the current pending state of the node.
+type Pending struct {
+	backend rueapi.Backend
+}
+
+func (p *Pending) TransactionCount(ctx context.Context) (int32, error) {
+	txs, err := p.backend.GetPoolTransactions()
+	return int32(len(txs)), err
+}
+
+func (p *Pending) Transactions(ctx context.Context) ([]*Transaction, error) {
+	txs, err := p.backend.GetPoolTransactions()
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*Transaction, 0, len(txs))
+	for _, tx := range txs {
+		ret = append(ret, &Transaction{
+			backend: p.backend,
+			hash:    tx.Hash(),
+			tx:      tx,
+		})
+	}
+	return ret, nil
+}
+
+func (p *Pending) Account(ctx context.Context, args struct{ Address common.Address }) *Account {
+	return &Account{
+		backend:     p.backend,
+		address:     args.Address,
+		blockNumber: rpc.PendingBlockNumber,
+	}
+}
+
+func (p *Pending) Call(ctx context.Context, args struct{ Data CallData }) (*CallResult, error) {
+	return doCall(ctx, p.backend, args.Data, rpc.PendingBlockNumber)
+}
+
+func (p *Pending) EstimateGas(ctx context.Context, args struct{ Data CallData }) (hexutil.Big, error) {
+	gas, err := rueapi.DoEstimateGas(ctx, p.backend, args.Data.toCallArgs(), rpc.PendingBlockNumber, nil)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return *gas, nil
+}
+
+// Resolver is the top-level object in the GraphQL hierarchy.
+type Resolver struct {
+	backend rueapi.Backend
+}
+
+func (r *Resolver) Block(ctx context.Context, args struct {
+	Number *Long
+	Hash   *common.Hash
+}) (*Block, error) {
+	var block *Block
+	switch {
+	case args.Number != nil && args.Hash != nil:
+		return nil, errBlockSelector
+	case args.Number != nil:
+		number := rpc.BlockNumber(*args.Number)
+		block = &Block{backend: r.backend, num: &number}
+	case args.Hash != nil:
+		block = &Block{backend: r.backend, hash: *args.Hash}
+	default:
+		number := rpc.LatestBlockNumber
+		block = &Block{backend: r.backend, num: &number}
+	}
+	// Resolve the block, returning nil if it doesn't exist
+	b, err := block.resolve(ctx)
+	if err != nil || b == nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+func (r *Resolver) Blocks(ctx context.Context, args struct {
+	From Long
+	To   *Long
+}) ([]*Block, error) {
+	from := rpc.BlockNumber(args.From)
+	if from < 0 {
+		return nil, errBlockRange
+	}
+	// Clamp the range to the current head, no blocks exist beyond it
+	to := rpc.BlockNumber(r.backend.CurrentBlock().NumberU64())
+	if args.To != nil && rpc.BlockNumber(*args.To) < to {
+		to = rpc.BlockNumber(*args.To)
+	}
+	if to < from {
+		return []*Block{}, nil
+	}
+	if to-from >= maxBlockRange {
+		return nil, errBlockRangeLimit
+	}
+	ret := make([]*Block, 0, to-from+1)
+	for i := from; i <= to; i++ {
+		number := i
+		block := &Block{backend: r.backend, num: &number}
+		b, err := block.resolve(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if b == nil {
+			break
+		}
+		ret = append(ret, block)
+	}
+	return ret, nil
+}
+
+func (r *Resolver) Pending(ctx context.Context) *Pending {
+	return &Pending{r.backend}
+}
+
+func (r *Resolver) Transaction(ctx context.Context, args struct{ Hash common.Hash }) (*Transaction, error) {
+	tx := &Transaction{
+		backend: r.backend,
+		hash:    args.Hash,
+	}
+	// Resolve the transaction; if it doesn't exist, return nil.
+	t, err := tx.resolve(ctx)
+	if err != nil || t == nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+func (r *Resolver) SendRawTransaction(ctx context.Context, args struct{ Data hexutil.Bytes }) (common.Hash, error) {
+	tx := new(types.Transaction)
+	if err := rlp.DecodeBytes(args.Data, tx); err != nil {
+		return common.Hash{}, err
+	}
+	return rueapi.SubmitTransaction(ctx, r.backend, tx)
+}
+
+// FilterCriteria encapsulates the arguments to `logs` on the root resolver object.
+type FilterCriteria struct {
+	FromBlock *Long             // beginning of the queried range, nil means latest block
+	ToBlock   *Long             // end of the range, nil means latest block
+	Addresses *[]common.Address // restricts matches to events created by specific contracts
+	Topics    *[][]common.Hash  // restricts matches to particular event topics, see BlockFilterCriteria
+}
+
+func (r *Resolver) Logs(ctx context.Context, args struct{ Filter FilterCriteria }) ([]*Log, error) {
+	begin := rpc.LatestBlockNumber.Int64()
+	if args.Filter.FromBlock != nil {
+		begin = int64(*args.Filter.FromBlock)
+	}
+	end := rpc.LatestBlockNumber.Int64()
+	if args.Filter.ToBlock != nil {
+		end = int64(*args.Filter.ToBlock)
+	}
+	return runFilter(ctx, r.backend, begin, end, args.Filter.Addresses, args.Filter.Topics)
+}
+
+func (r *Resolver) GasPrice(ctx context.Context) (hexutil.Big, error) {
+	price, err := r.backend.SuggestPrice(ctx)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*price), nil
+}
+
+func (r *Resolver) ProtocolVersion(ctx context.Context) (int32, error) {
+	return int32(r.backend.ProtocolVersion()), nil
+}
@@ -0,0 +1,231 @@
+// Copyright 2018 The go-ruereum Authors
+// This file is part of the go-ruereum library.
+//
+// The go-ruereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ruereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ruereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Rue-Foundation/go-rue/common"
+	"github.com/Rue-Foundation/go-rue/consensus/ruehash"
+	"github.com/Rue-Foundation/go-rue/core"
+	"github.com/Rue-Foundation/go-rue/core/state"
+	"github.com/Rue-Foundation/go-rue/core/types"
+	"github.com/Rue-Foundation/go-rue/core/vm"
+	"github.com/Rue-Foundation/go-rue/crypto"
+	"github.com/Rue-Foundation/go-rue/internal/rueapi"
+	"github.com/Rue-Foundation/go-rue/params"
+	"github.com/Rue-Foundation/go-rue/rpc"
+	"github.com/Rue-Foundation/go-rue/ruedb"
+)
+
+var (
+	testKey, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	testAddr   = crypto.PubkeyToAddress(testKey.PublicKey)
+)
+
+// testBackend is a chain backed rueapi.Backend implementing only the methods
+// needed by the GraphQL resolvers.
+type testBackend struct {
+	rueapi.Backend
+
+	db    ruedb.Database
+	chain *core.BlockChain
+}
+
+func (b *testBackend) ChainDb() ruedb.Database                           { return b.db }
+func (b *testBackend) ChainConfig() *params.ChainConfig                  { return b.chain.Config() }
+func (b *testBackend) CurrentBlock() *types.Block                        { return b.chain.CurrentBlock() }
+func (b *testBackend) GetTd(hash common.Hash) *big.Int                   { return b.chain.GetTdByHash(hash) }
+func (b *testBackend) GetPoolTransaction(common.Hash) *types.Transaction { return nil }
+
+func (b *testBackend) BlockByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Block, error) {
+	if number == rpc.LatestBlockNumber || number == rpc.PendingBlockNumber {
+		return b.chain.CurrentBlock(), nil
+	}
+	return b.chain.GetBlockByNumber(uint64(number)), nil
+}
+
+func (b *testBackend) GetBlock(ctx context.Context, hash common.Hash) (*types.Block, error) {
+	return b.chain.GetBlockByHash(hash), nil
+}
+
+func (b *testBackend) GetReceipts(ctx context.Context, hash common.Hash) (types.Receipts, error) {
+	return core.GetBlockReceipts(b.db, hash, core.GetBlockNumber(b.db, hash)), nil
+}
+
+func (b *testBackend) StateAndHeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*state.StateDB, *types.Header, error) {
+	block, _ := b.BlockByNumber(ctx, number)
+	if block == nil {
+		return nil, nil, nil
+	}
+	statedb, err := b.chain.StateAt(block.Root())
+	return statedb, block.Header(), err
+}
+
+// newTestBackend creates a chain with a single block containing a value transfer.
+func newTestBackend(t *testing.T) (*testBackend, *types.Transaction) {
+	var (
+		db, _ = ruedb.NewMemDatabase()
+		gspec = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  core.GenesisAlloc{testAddr: {Balance: big.NewInt(1000000)}},
+		}
+		genesis = gspec.MustCommit(db)
+		engine  = ruehash.NewFaker()
+	)
+	var transfer *types.Transaction
+	blocks, _ := core.GenerateChain(gspec.Config, genesis, engine, db, 1, func(i int, block *core.BlockGen) {
+		block.SetCoinbase(common.Address{0xc0})
+
+		transfer, _ = types.SignTx(types.NewTransaction(0, common.Address{0x01}, big.NewInt(1000), big.NewInt(21000), new(big.Int), nil), types.HomesteadSigner{}, testKey)
+		block.AddTx(transfer)
+	})
+	chain, _ := core.NewBlockChain(db, nil, gspec.Config, engine, vm.Config{})
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	return &testBackend{db: db, chain: chain}, transfer
+}
+
+// query executes a GraphQL query against the handler, returning the decoded data
+// and errors fields of the response.
+func query(t *testing.T, handler http.Handler, q string) (map[string]interface{}, []interface{}) {
+	body, _ := json.Marshal(map[string]string{"query": q})
+	req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(string(body)))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	var res struct {
+		Data   map[string]interface{} `json:"data"`
+		Errors []interface{}          `json:"errors"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+		t.Fatalf("failed to decode response %q: %v", rec.Body.String(), err)
+	}
+	return res.Data, res.Errors
+}
+
+// Tests that blocks, transactions and accounts can be queried through GraphQL.
+func TestGraphQLQueries(t *testing.T) {
+	backend, transfer := newTestBackend(t)
+	defer backend.chain.Stop()
+
+	handler, err := newHandler(backend)
+	if err != nil {
+		t.Fatalf("failed to create handler: %v", err)
+	}
+	// Query the head block along with its transaction
+	data, errs := query(t, handler, `{
+		block {
+			number
+			transactionCount
+			miner { address }
+			parent { number }
+			transactions { hash nonce index from { address } value gas status gasUsed }
+		}
+	}`)
+	if len(errs) > 0 {
+		t.Fatalf("block query failed: %v", errs)
+	}
+	block := data["block"].(map[string]interface{})
+	if block["number"].(float64) != 1 || block["transactionCount"].(float64) != 1 {
+		t.Errorf("block mismatch: %v", block)
+	}
+	if miner := block["miner"].(map[string]interface{})["address"].(string); !strings.EqualFold(miner, (common.Address{0xc0}).Hex()) {
+		t.Errorf("miner mismatch: have %v", miner)
+	}
+	if parent := block["parent"].(map[string]interface{}); parent["number"].(float64) != 0 {
+		t.Errorf("parent mismatch: %v", parent)
+	}
+	tx := block["transactions"].([]interface{})[0].(map[string]interface{})
+	if tx["hash"] != transfer.Hash().Hex() {
+		t.Errorf("transaction hash mismatch: have %v, want %v", tx["hash"], transfer.Hash().Hex())
+	}
+	if from := tx["from"].(map[string]interface{})["address"].(string); !strings.EqualFold(from, testAddr.Hex()) {
+		t.Errorf("sender mismatch: have %v, want %v", from, testAddr.Hex())
+	}
+	if tx["value"] != "0x3e8" || tx["gas"] != "0x5208" || tx["gasUsed"] != "0x5208" || tx["status"].(float64) != 1 || tx["index"].(float64) != 0 {
+		t.Errorf("transaction mismatch: %v", tx)
+	}
+	// Query the transaction directly by hash and the accounts it touched
+	data, errs = query(t, handler, `{
+		transaction(hash: "`+transfer.Hash().Hex()+`") { block { number } to { balance } }
+		block(number: 0) { account(address: "`+testAddr.Hex()+`") { balance transactionCount } }
+	}`)
+	if len(errs) > 0 {
+		t.Fatalf("transaction query failed: %v", errs)
+	}
+	txn := data["transaction"].(map[string]interface{})
+	if number := txn["block"].(map[string]interface{})["number"].(float64); number != 1 {
+		t.Errorf("transaction block mismatch: have %v, want 1", number)
+	}
+	if balance := txn["to"].(map[string]interface{})["balance"]; balance != "0x3e8" {
+		t.Errorf("recipient balance mismatch: have %v, want 0x3e8", balance)
+	}
+	account := data["block"].(map[string]interface{})["account"].(map[string]interface{})
+	if account["balance"] != "0xf4240" || account["transactionCount"].(float64) != 0 {
+		t.Errorf("historical account mismatch: %v", account)
+	}
+	// Unknown blocks and transactions should resolve to null
+	data, errs = query(t, handler, `{ block(number: 10) { hash } transaction(hash: "0x0000000000000000000000000000000000000000000000000000000000000001") { hash } }`)
+	if len(errs) > 0 {
+		t.Fatalf("missing item query failed: %v", errs)
+	}
+	if data["block"] != nil || data["transaction"] != nil {
+		t.Errorf("missing items resolved: %v", data)
+	}
+	// Block ranges should be clamped to the head and limited in size
+	data, errs = query(t, handler, `{ blocks(from: 0, to: "9223372036854775807") { number } }`)
+	if len(errs) > 0 {
+		t.Fatalf("block range query failed: %v", errs)
+	}
+	if blocks := data["blocks"].([]interface{}); len(blocks) != 2 {
+		t.Errorf("block range length mismatch: have %d, want %d", len(blocks), 2)
+	}
+	if _, errs = query(t, handler, `{ blocks(from: -1) { number } }`); len(errs) == 0 {
+		t.Errorf("negative block range accepted")
+	}
+	// Ambiguous block selectors should be rejected
+	if _, errs = query(t, handler, `{ block(number: 1, hash: "`+transfer.Hash().Hex()+`") { number } }`); len(errs) == 0 {
+		t.Errorf("ambiguous block selector accepted")
+	}
+}
+
+// Tests that the GraphiQL explorer is served on the root path.
+func TestGraphiQL(t *testing.T) {
+	backend, _ := newTestBackend(t)
+	defer backend.chain.Stop()
+
+	handler, err := newHandler(backend)
+	if err != nil {
+		t.Fatalf("failed to create handler: %v", err)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	blob, _ := ioutil.ReadAll(rec.Body)
+	if rec.Code != http.StatusOK || !strings.Contains(string(blob), "graphiql") {
+		t.Errorf("explorer not served: code %d, body %q", rec.Code, blob)
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2018 The go-ruereum Authors
+// This file is part of the go-ruereum library.
+//
+// The go-ruereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ruereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ruereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package graphql
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/Rue-Foundation/go-rue/internal/rueapi"
+	"github.com/Rue-Foundation/go-rue/log"
+	"github.com/Rue-Foundation/go-rue/p2p"
+	"github.com/Rue-Foundation/go-rue/rpc"
+	graphqlgo "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+)
+
+// Service encapsulates a GraphQL service.
+type Service struct {
+	endpoint string         // The host:port endpoint for this service.
+	cors     []string       // Allowed CORS domains
+	vhosts   []string       // Recognised vhosts
+	backend  rueapi.Backend // The backend that queries will operate on.
+	handler  http.Handler   // The `http.Handler` used to answer queries.
+	listener net.Listener   // The listening socket.
+}
+
+// New constructs a new GraphQL service instance, serving queries against the
+// given backend on the provided host:port endpoint.
+func New(backend rueapi.Backend, endpoint string, cors, vhosts []string) (*Service, error) {
+	return &Service{
+		endpoint: endpoint,
+		cors:     cors,
+		vhosts:   vhosts,
+		backend:  backend,
+	}, nil
+}
+
+// Protocols returns the list of protocols exported by this service.
+func (s *Service) Protocols() []p2p.Protocol { return nil }
+
+// APIs returns the list of APIs exported by this service.
+func (s *Service) APIs() []rpc.API { return nil }
+
+// Start is called after all services have been constructed and the networking
+// layer was also initialized to spawn any goroutines required by the service.
+func (s *Service) Start(server *p2p.Server) error {
+	var err error
+	if s.handler, err = newHandler(s.backend); err != nil {
+		return err
+	}
+	if s.listener, err = net.Listen("tcp", s.endpoint); err != nil {
+		return err
+	}
+	go http.Serve(s.listener, rpc.NewHTTPHandlerStack(s.handler, s.cors, s.vhosts))
+	log.Info("GraphQL endpoint opened", "url", fmt.Sprintf("http://%s/graphql", s.endpoint))
+	return nil
+}
+
+// Stop terminates all goroutines belonging to the service, blocking until they
+// are all terminated.
+func (s *Service) Stop() error {
+	if s.listener != nil {
+		s.listener.Close()
+		s.listener = nil
+		log.Info("GraphQL endpoint closed", "url", fmt.Sprintf("http://%s/graphql", s.endpoint))
+	}
+	return nil
+}
+
+// newHandler creates the HTTP handler answering GraphQL queries on /graphql and
+// serving the GraphiQL explorer on the root path.
+func newHandler(backend rueapi.Backend) (http.Handler, error) {
+	q := Resolver{backend}
+
+	s, err := graphqlgo.ParseSchema(schema, &q)
+	if err != nil {
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/", GraphiQL{})
+	mux.Handle("/graphql", &relay.Handler{Schema: s})
+	mux.Handle("/graphql/", &relay.Handler{Schema: s})
+	return mux, nil
+}
@@ -32,6 +32,7 @@ import (
 	"github.com/Rue-Foundation/go-rue/common/math"
 	"github.com/Rue-Foundation/go-rue/consensus/ruehash"
 	"github.com/Rue-Foundation/go-rue/core"
+	"github.com/Rue-Foundation/go-rue/core/state"
 	"github.com/Rue-Foundation/go-rue/core/types"
 	"github.com/Rue-Foundation/go-rue/core/vm"
 	"github.com/Rue-Foundation/go-rue/crypto"
@@ -131,21 +132,25 @@ func (s *PublicTxPoolAPI) Content() map[string]map[string]map[string]*RPCTransac
 	return content
 }
 
-// Status returns the number of pending and queued transaction in the pool.
+// Status returns the number of pending and queued transaction in the pool, along
+// with how many of them are private ones withheld from the network.
 func (s *PublicTxPoolAPI) Status() map[string]hexutil.Uint {
 	pending, queue := s.b.Stats()
 	return map[string]hexutil.Uint{
 		"pending": hexutil.Uint(pending),
 		"queued":  hexutil.Uint(queue),
+		"private": hexutil.Uint(s.b.PrivateStats()),
 	}
 }
 
 // Inspect retrieves the content of the transaction pool and flattens it into an
-// easily inspectable list.
+// easily inspectable list. Transactions recently rejected by the pool's admission
+// filters are listed by hash.
 func (s *PublicTxPoolAPI) Inspect() map[string]map[string]map[string]string {
 	content := map[string]map[string]map[string]string{
-		"pending": make(map[string]map[string]string),
-		"queued":  make(map[string]map[string]string),
+		"pending":  make(map[string]map[string]string),
+		"queued":   make(map[string]map[string]string),
+		"rejected": make(map[string]map[string]string),
 	}
 	pending, queue := s.b.TxPoolContent()
 
@@ -172,6 +177,15 @@ func (s *PublicTxPoolAPI) Inspect() map[string]map[string]map[string]string {
 		}
 		content["queued"][account.Hex()] = dump
 	}
+	// Flatten the filtered transactions
+	for _, rejection := range s.b.TxPoolRejections() {
+		dump := content["rejected"][rejection.From.Hex()]
+		if dump == nil {
+			dump = make(map[string]string)
+			content["rejected"][rejection.From.Hex()] = dump
+		}
+		dump[rejection.Hash.Hex()] = fmt.Sprintf("%s filter: %v", rejection.Filter, rejection.Err)
+	}
 	return content
 }
 
@@ -368,7 +382,7 @@ func (s *PrivateAccountAPI) SendTransaction(ctx context.Context, args SendTxArgs
 	if err != nil {
 		return common.Hash{}, err
 	}
-	return submitTransaction(ctx, s.b, signed)
+	return SubmitTransaction(ctx, s.b, signed)
 }
 
 // signHash is a helper function that calculates a hash for the given message that can be
@@ -472,6 +486,80 @@ func (s *PublicBlockChainAPI) GetBalance(ctx context.Context, address common.Add
 	return b, state.Error()
 }
 
+// AccountResult is the result of an account proof query, as defined by EIP-1186.
+type AccountResult struct {
+	Address      common.Address  `json:"address"`
+	AccountProof []string        `json:"accountProof"`
+	Balance      *hexutil.Big    `json:"balance"`
+	CodeHash     common.Hash     `json:"codeHash"`
+	Nonce        hexutil.Uint64  `json:"nonce"`
+	StorageHash  common.Hash     `json:"storageHash"`
+	StorageProof []StorageResult `json:"storageProof"`
+}
+
+// StorageResult is the proof of a single storage slot within an AccountResult.
+type StorageResult struct {
+	Key   string       `json:"key"`
+	Value *hexutil.Big `json:"value"`
+	Proof []string     `json:"proof"`
+}
+
+// GetProof returns the Merkle-proof for a given account and optionally some
+// storage keys in the state of the given block number.
+func (s *PublicBlockChainAPI) GetProof(ctx context.Context, address common.Address, storageKeys []string, blockNr rpc.BlockNumber) (*AccountResult, error) {
+	state, _, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	// Prove the requested storage slots if the account has any storage
+	storageTrie := state.StorageTrie(address)
+	storageHash := types.EmptyRootHash
+	codeHash := state.GetCodeHash(address)
+	storageProof := make([]StorageResult, len(storageKeys))
+
+	if storageTrie != nil {
+		storageHash = storageTrie.Hash()
+	} else {
+		// No storage trie means the account does not exist, so the codeHash is
+		// the hash of an empty bytearray.
+		codeHash = crypto.Keccak256Hash(nil)
+	}
+	for i, key := range storageKeys {
+		if storageTrie != nil {
+			proof, err := state.GetStorageProof(address, common.HexToHash(key))
+			if err != nil {
+				return nil, err
+			}
+			storageProof[i] = StorageResult{key, (*hexutil.Big)(state.GetState(address, common.HexToHash(key)).Big()), toHexSlice(proof)}
+		} else {
+			storageProof[i] = StorageResult{key, &hexutil.Big{}, []string{}}
+		}
+	}
+	// Create the account proof
+	accountProof, err := state.GetProof(address)
+	if err != nil {
+		return nil, err
+	}
+	return &AccountResult{
+		Address:      address,
+		AccountProof: toHexSlice(accountProof),
+		Balance:      (*hexutil.Big)(state.GetBalance(address)),
+		CodeHash:     codeHash,
+		Nonce:        hexutil.Uint64(state.GetNonce(address)),
+		StorageHash:  storageHash,
+		StorageProof: storageProof,
+	}, state.Error()
+}
+
+// toHexSlice creates a slice of hex-strings based on []byte.
+func toHexSlice(b [][]byte) []string {
+	r := make([]string, len(b))
+	for i := range b {
+		r[i] = hexutil.Encode(b[i])
+	}
+	return r
+}
+
 // GetBlockByNumber returns the requested block. When blockNr is -1 the chain head is returned. When fullTx is true all
 // transactions in the block are returned in full detail, otherwise only the transaction hash is returned.
 func (s *PublicBlockChainAPI) GetBlockByNumber(ctx context.Context, blockNr rpc.BlockNumber, fullTx bool) (map[string]interface{}, error) {
@@ -581,17 +669,66 @@ type CallArgs struct {
 	Data     hexutil.Bytes   `json:"data"`
 }
 
-func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockNr rpc.BlockNumber, vmCfg vm.Config) ([]byte, *big.Int, bool, error) {
+// OverrideAccount specifies the fields of an account to override during the
+// execution of a message call. Only one of State and StateDiff may be set: State
+// replaces the entire account storage, StateDiff patches individual slots.
+type OverrideAccount struct {
+	Nonce     *hexutil.Uint64              `json:"nonce"`
+	Code      *hexutil.Bytes               `json:"code"`
+	Balance   **hexutil.Big                `json:"balance"`
+	State     *map[common.Hash]common.Hash `json:"state"`
+	StateDiff *map[common.Hash]common.Hash `json:"stateDiff"`
+}
+
+// StateOverride is the collection of overridden accounts.
+type StateOverride map[common.Address]OverrideAccount
+
+// Apply overrides the fields of the specified accounts in the given state.
+func (diff *StateOverride) Apply(state *state.StateDB) error {
+	if diff == nil {
+		return nil
+	}
+	for addr, account := range *diff {
+		if account.State != nil && account.StateDiff != nil {
+			return fmt.Errorf("account %s has both 'state' and 'stateDiff'", addr.Hex())
+		}
+		if account.Nonce != nil {
+			state.SetNonce(addr, uint64(*account.Nonce))
+		}
+		if account.Code != nil {
+			state.SetCode(addr, *account.Code)
+		}
+		if account.Balance != nil {
+			state.SetBalance(addr, (*big.Int)(*account.Balance))
+		}
+		if account.State != nil {
+			state.SetStorage(addr, *account.State)
+		}
+		if account.StateDiff != nil {
+			for key, value := range *account.StateDiff {
+				state.SetState(addr, key, value)
+			}
+		}
+	}
+	return state.Error()
+}
+
+// DoCall executes the given message call on top of the state of the requested
+// block, returning the result, the gas used and whether the execution failed.
+func DoCall(ctx context.Context, b Backend, args CallArgs, blockNr rpc.BlockNumber, overrides *StateOverride, vmCfg vm.Config) ([]byte, *big.Int, bool, error) {
 	defer func(start time.Time) { log.Debug("Executing EVM call finished", "runtime", time.Since(start)) }(time.Now())
 
-	state, header, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
+	state, header, err := b.StateAndHeaderByNumber(ctx, blockNr)
 	if state == nil || err != nil {
 		return nil, common.Big0, false, err
 	}
+	if err := overrides.Apply(state); err != nil {
+		return nil, common.Big0, false, err
+	}
 	// Set sender address or use a default if none specified
 	addr := args.From
 	if addr == (common.Address{}) {
-		if wallets := s.b.AccountManager().Wallets(); len(wallets) > 0 {
+		if wallets := b.AccountManager().Wallets(); len(wallets) > 0 {
 			if accounts := wallets[0].Accounts(); len(accounts) > 0 {
 				addr = accounts[0].Address
 			}
@@ -622,7 +759,7 @@ func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockNr
 	defer func() { cancel() }()
 
 	// Get a new instance of the EVM.
-	evm, vmError, err := s.b.GetEVM(ctx, msg, state, header, vmCfg)
+	evm, vmError, err := b.GetEVM(ctx, msg, state, header, vmCfg)
 	if err != nil {
 		return nil, common.Big0, false, err
 	}
@@ -645,14 +782,24 @@ func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockNr
 
 // Call executes the given transaction on the state for the given block number.
 // It doesn't make and changes in the state/blockchain and is useful to execute and retrieve values.
-func (s *PublicBlockChainAPI) Call(ctx context.Context, args CallArgs, blockNr rpc.BlockNumber) (hexutil.Bytes, error) {
-	result, _, _, err := s.doCall(ctx, args, blockNr, vm.Config{DisableGasMetering: true})
+//
+// Additionally, the caller can specify a batch of accounts to override in the
+// state before executing the call.
+func (s *PublicBlockChainAPI) Call(ctx context.Context, args CallArgs, blockNr rpc.BlockNumber, overrides *StateOverride) (hexutil.Bytes, error) {
+	result, _, _, err := DoCall(ctx, s.b, args, blockNr, overrides, vm.Config{DisableGasMetering: true})
 	return (hexutil.Bytes)(result), err
 }
 
 // EstimateGas returns an estimate of the amount of gas needed to execute the
-// given transaction against the current pending block.
-func (s *PublicBlockChainAPI) EstimateGas(ctx context.Context, args CallArgs) (*hexutil.Big, error) {
+// given transaction against the current pending block, optionally with some
+// accounts overridden in the state.
+func (s *PublicBlockChainAPI) EstimateGas(ctx context.Context, args CallArgs, overrides *StateOverride) (*hexutil.Big, error) {
+	return DoEstimateGas(ctx, s.b, args, rpc.PendingBlockNumber, overrides)
+}
+
+// DoEstimateGas binary searches the lowest gas allowance the given transaction
+// executes successfully with on top of the state of the requested block.
+func DoEstimateGas(ctx context.Context, b Backend, args CallArgs, blockNr rpc.BlockNumber, overrides *StateOverride) (*hexutil.Big, error) {
 	// Determine the lowest and highest possible gas limits to binary search in between
 	var (
 		lo  uint64 = params.TxGas - 1
@@ -662,8 +809,8 @@ func (s *PublicBlockChainAPI) EstimateGas(ctx context.Context, args CallArgs) (*
 	if (*big.Int)(&args.Gas).Uint64() >= params.TxGas {
 		hi = (*big.Int)(&args.Gas).Uint64()
 	} else {
-		// Retrieve the current pending block to act as the gas ceiling
-		block, err := s.b.BlockByNumber(ctx, rpc.PendingBlockNumber)
+		// Retrieve the requested block to act as the gas ceiling
+		block, err := b.BlockByNumber(ctx, blockNr)
 		if err != nil {
 			return nil, err
 		}
@@ -674,7 +821,7 @@ func (s *PublicBlockChainAPI) EstimateGas(ctx context.Context, args CallArgs) (*
 	// Create a helper to check if a gas allowance results in an executable transaction
 	executable := func(gas uint64) bool {
 		(*big.Int)(&args.Gas).SetUint64(gas)
-		_, _, failed, err := s.doCall(ctx, args, rpc.PendingBlockNumber, vm.Config{})
+		_, _, failed, err := DoCall(ctx, b, args, blockNr, overrides, vm.Config{})
 		if err != nil || failed {
 			return false
 		}
@@ -1047,6 +1194,128 @@ func (s *PublicTransactionPoolAPI) GetTransactionReceipt(hash common.Hash) (map[
 	return fields, nil
 }
 
+const (
+	// defaultAddressTxLimit is the number of transactions returned by an address
+	// history query if no limit is requested.
+	defaultAddressTxLimit = 100
+
+	// maxAddressTxLimit is the maximum number of transactions returned by a single
+	// address history query.
+	maxAddressTxLimit = 1000
+)
+
+// AddressTxCursor is the position of a transaction in the canonical chain, used
+// to resume a paginated address history query.
+type AddressTxCursor struct {
+	BlockNumber      hexutil.Uint64 `json:"blockNumber"`
+	TransactionIndex hexutil.Uint   `json:"transactionIndex"`
+}
+
+// AddressTransactions is a single page of an address history query.
+type AddressTransactions struct {
+	Transactions []*RPCTransaction `json:"transactions"`
+	Next         *AddressTxCursor  `json:"next"` // Position to resume the query from, nil if exhausted
+}
+
+// GetTransactionsByAddress returns the canonical transactions sent from, sent to
+// or creating the given address within the given block range, in chain order.
+// At most limit transactions are returned at once, along with a cursor to pass
+// in to retrieve the next page. The address index is used for the sections it
+// covers, whilst the remaining blocks are scanned one by one.
+func (s *PublicTransactionPoolAPI) GetTransactionsByAddress(ctx context.Context, address common.Address, fromBlock, toBlock rpc.BlockNumber, cursor *AddressTxCursor, limit *hexutil.Uint64) (*AddressTransactions, error) {
+	// Resolve the block range and the position to start from
+	header, err := s.b.HeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if header == nil || err != nil {
+		return nil, err
+	}
+	resolve := func(number rpc.BlockNumber) uint64 {
+		if number < 0 || uint64(number) > header.Number.Uint64() {
+			return header.Number.Uint64()
+		}
+		return uint64(number)
+	}
+	begin, end := core.TxPosition{Block: resolve(fromBlock)}, resolve(toBlock)
+	if cursor != nil && uint64(cursor.BlockNumber) >= begin.Block {
+		begin = core.TxPosition{Block: uint64(cursor.BlockNumber), Index: uint32(cursor.TransactionIndex)}
+	}
+	count := uint64(defaultAddressTxLimit)
+	if limit != nil {
+		count = uint64(*limit)
+	}
+	if count == 0 || count > maxAddressTxLimit {
+		return nil, fmt.Errorf("limit must be between 1 and %d", maxAddressTxLimit)
+	}
+	// Only use the address index if it's consistent with the canonical chain
+	db := s.b.ChainDb()
+
+	size, sections, head := s.b.AddressIndexStatus()
+	if sections > 0 && core.GetCanonicalHash(db, sections*size-1) != head {
+		sections = 0
+	}
+	// Gather one transaction more than requested to know where to resume from
+	var positions []core.TxPosition
+
+	for number := begin.Block; number <= end && uint64(len(positions)) <= count; {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if size > 0 && number/size < sections {
+			section := number / size
+			entries, err := core.GetAddrTxIndex(db, address, section, core.GetCanonicalHash(db, (section+1)*size-1))
+			if err != nil {
+				return nil, err
+			}
+			for _, pos := range entries {
+				if pos.Block <= end && (pos.Block > begin.Block || pos.Block == begin.Block && pos.Index >= begin.Index) {
+					positions = append(positions, pos)
+				}
+			}
+			number = (section + 1) * size
+			continue
+		}
+		block, err := s.b.BlockByNumber(ctx, rpc.BlockNumber(number))
+		if block == nil || err != nil {
+			return nil, err
+		}
+		signer := types.MakeSigner(s.b.ChainConfig(), block.Number())
+		for i, tx := range block.Transactions() {
+			if number == begin.Block && uint32(i) < begin.Index {
+				continue
+			}
+			addresses, err := core.TxAddresses(signer, tx)
+			if err != nil {
+				return nil, err
+			}
+			for _, addr := range addresses {
+				if addr == address {
+					positions = append(positions, core.TxPosition{Block: number, Index: uint32(i)})
+					break
+				}
+			}
+		}
+		number++
+	}
+	// Assemble the requested page of transactions
+	result := &AddressTransactions{Transactions: []*RPCTransaction{}}
+	if uint64(len(positions)) > count {
+		next := positions[count]
+		result.Next = &AddressTxCursor{BlockNumber: hexutil.Uint64(next.Block), TransactionIndex: hexutil.Uint(next.Index)}
+		positions = positions[:count]
+	}
+	var block *types.Block
+	for _, pos := range positions {
+		if block == nil || block.NumberU64() != pos.Block {
+			if block, err = s.b.BlockByNumber(ctx, rpc.BlockNumber(pos.Block)); block == nil || err != nil {
+				return nil, err
+			}
+		}
+		if tx := newRPCTransactionFromBlockIndex(block, uint64(pos.Index)); tx != nil {
+			result.Transactions = append(result.Transactions, tx)
+		}
+	}
+	return result, nil
+}
+
 // sign is a helper function that signs a transaction with the private key of the given address.
 func (s *PublicTransactionPoolAPI) sign(addr common.Address, tx *types.Transaction) (*types.Transaction, error) {
 	// Look up the wallet containing the requested signer
@@ -1119,8 +1388,8 @@ func (args *SendTxArgs) toTransaction() *types.Transaction {
 	return types.NewTransaction(uint64(*args.Nonce), *args.To, (*big.Int)(args.Value), (*big.Int)(args.Gas), (*big.Int)(args.GasPrice), input)
 }
 
-// submitTransaction is a helper function that submits tx to txPool and logs a message.
-func submitTransaction(ctx context.Context, b Backend, tx *types.Transaction) (common.Hash, error) {
+// SubmitTransaction is a helper function that submits tx to txPool and logs a message.
+func SubmitTransaction(ctx context.Context, b Backend, tx *types.Transaction) (common.Hash, error) {
 	if err := b.SendTx(ctx, tx); err != nil {
 		return common.Hash{}, err
 	}
@@ -1172,7 +1441,7 @@ func (s *PublicTransactionPoolAPI) SendTransaction(ctx context.Context, args Sen
 	if err != nil {
 		return common.Hash{}, err
 	}
-	return submitTransaction(ctx, s.b, signed)
+	return SubmitTransaction(ctx, s.b, signed)
 }
 
 // SendRawTransaction will add the signed transaction to the transaction pool.
@@ -1182,7 +1451,22 @@ func (s *PublicTransactionPoolAPI) SendRawTransaction(ctx context.Context, encod
 	if err := rlp.DecodeBytes(encodedTx, tx); err != nil {
 		return common.Hash{}, err
 	}
-	return submitTransaction(ctx, s.b, tx)
+	return SubmitTransaction(ctx, s.b, tx)
+}
+
+// SendPrivateRawTransaction will add the signed transaction to the transaction pool
+// without ever propagating it to the network, so only the local miner may include
+// it. If not included by the expiry block number, the transaction is dropped.
+func (s *PublicTransactionPoolAPI) SendPrivateRawTransaction(ctx context.Context, encodedTx hexutil.Bytes, expiry hexutil.Uint64) (common.Hash, error) {
+	tx := new(types.Transaction)
+	if err := rlp.DecodeBytes(encodedTx, tx); err != nil {
+		return common.Hash{}, err
+	}
+	if err := s.b.SendPrivateTx(ctx, tx, uint64(expiry)); err != nil {
+		return common.Hash{}, err
+	}
+	log.Info("Submitted private transaction", "fullhash", tx.Hash().Hex(), "expiry", uint64(expiry))
+	return tx.Hash(), nil
 }
 
 // Sign calculates an ECDSA signature for:
@@ -1363,7 +1647,7 @@ func NewPrivateDebugAPI(b Backend) *PrivateDebugAPI {
 
 // ChaindbProperty returns leveldb properties of the chain database.
 func (api *PrivateDebugAPI) ChaindbProperty(property string) (string, error) {
-	ldb, ok := api.b.ChainDb().(interface {
+	ldb, ok := core.KeyValueStore(api.b.ChainDb()).(interface {
 		LDB() *leveldb.DB
 	})
 	if !ok {
@@ -1378,7 +1662,7 @@ func (api *PrivateDebugAPI) ChaindbProperty(property string) (string, error) {
 }
 
 func (api *PrivateDebugAPI) ChaindbCompact() error {
-	ldb, ok := api.b.ChainDb().(interface {
+	ldb, ok := core.KeyValueStore(api.b.ChainDb()).(interface {
 		LDB() *leveldb.DB
 	})
 	if !ok {
@@ -29,6 +29,7 @@ var Modules = map[string]string{
 	"rpc":        RPC_JS,
 	"shh":        Shh_JS,
 	"swarmfs":    SWARMFS_JS,
+	"trace":      Trace_JS,
 	"txpool":     TxPool_JS,
 }
 
@@ -142,8 +143,8 @@ web3._extend({
 		new web3._extend.method({
 			name: 'startRPC',
 			call: 'admin_startRPC',
-			params: 4,
-			inputFormatter: [null, null, null, null]
+			params: 5,
+			inputFormatter: [null, null, null, null, null]
 		}),
 		new web3._extend.method({
 			name: 'stopRPC',
@@ -342,6 +343,12 @@ web3._extend({
 			params: 2,
 			inputFormatter: [null, null]
 		}),
+		new web3._extend.method({
+			name: 'simulateBundle',
+			call: 'debug_simulateBundle',
+			params: 3,
+			inputFormatter: [null, web3._extend.formatters.inputBlockNumberFormatter, null]
+		}),
 		new web3._extend.method({
 			name: 'preimage',
 			call: 'debug_preimage',
@@ -385,6 +392,19 @@ web3._extend({
 			params: 2,
 			inputFormatter: [web3._extend.formatters.inputAddressFormatter, null]
 		}),
+		new web3._extend.method({
+			name: 'call',
+			call: 'rue_call',
+			params: 3,
+			inputFormatter: [web3._extend.formatters.inputCallFormatter, web3._extend.formatters.inputDefaultBlockNumberFormatter, null]
+		}),
+		new web3._extend.method({
+			name: 'estimateGas',
+			call: 'rue_estimateGas',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputCallFormatter, null],
+			outputFormatter: web3._extend.utils.toDecimal
+		}),
 		new web3._extend.method({
 			name: 'resend',
 			call: 'rue_resend',
@@ -403,11 +423,29 @@ web3._extend({
 			params: 1,
 			inputFormatter: [web3._extend.formatters.inputTransactionFormatter]
 		}),
+		new web3._extend.method({
+			name: 'sendPrivateRawTransaction',
+			call: 'rue_sendPrivateRawTransaction',
+			params: 2,
+			inputFormatter: [null, web3._extend.utils.fromDecimal]
+		}),
 		new web3._extend.method({
 			name: 'getRawTransaction',
 			call: 'rue_getRawTransactionByHash',
 			params: 1
 		}),
+		new web3._extend.method({
+			name: 'getProof',
+			call: 'rue_getProof',
+			params: 3,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, null, web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.method({
+			name: 'getTransactionsByAddress',
+			call: 'rue_getTransactionsByAddress',
+			params: 5,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, web3._extend.formatters.inputBlockNumberFormatter, web3._extend.formatters.inputBlockNumberFormatter, null, null]
+		}),
 		new web3._extend.method({
 			name: 'getRawTransactionFromBlock',
 			call: function(args) {
@@ -584,6 +622,36 @@ web3._extend({
 });
 `
 
+const Trace_JS = `
+web3._extend({
+	property: 'trace',
+	methods: [
+		new web3._extend.method({
+			name: 'block',
+			call: 'trace_block',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.method({
+			name: 'transaction',
+			call: 'trace_transaction',
+			params: 1
+		}),
+		new web3._extend.method({
+			name: 'filter',
+			call: 'trace_filter',
+			params: 1
+		}),
+		new web3._extend.method({
+			name: 'replayTransaction',
+			call: 'trace_replayTransaction',
+			params: 2
+		}),
+	],
+	properties: []
+});
+`
+
 const TxPool_JS = `
 web3._extend({
 	property: 'txpool',
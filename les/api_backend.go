@@ -18,6 +18,7 @@ package les
 
 import (
 	"context"
+	"errors"
 	"math/big"
 
 	"github.com/Rue-Foundation/go-rue/accounts"
@@ -37,6 +38,10 @@ import (
 	"github.com/Rue-Foundation/go-rue/rpc"
 )
 
+// errPrivateTxUnsupported is returned if a private transaction is submitted to
+// a light client, which can't keep it from the network since it doesn't mine.
+var errPrivateTxUnsupported = errors.New("private transactions not supported by light clients")
+
 type LesApiBackend struct {
 	rue *LightRuereum
 	gpo *gasprice.Oracle
@@ -101,6 +106,10 @@ func (b *LesApiBackend) SendTx(ctx context.Context, signedTx *types.Transaction)
 	return b.rue.txPool.Add(ctx, signedTx)
 }
 
+func (b *LesApiBackend) SendPrivateTx(ctx context.Context, signedTx *types.Transaction, expiry uint64) error {
+	return errPrivateTxUnsupported
+}
+
 func (b *LesApiBackend) RemoveTx(txHash common.Hash) {
 	b.rue.txPool.RemoveTx(txHash)
 }
@@ -121,10 +130,26 @@ func (b *LesApiBackend) Stats() (pending int, queued int) {
 	return b.rue.txPool.Stats(), 0
 }
 
+func (b *LesApiBackend) PrivateStats() int {
+	return 0
+}
+
 func (b *LesApiBackend) TxPoolContent() (map[common.Address]types.Transactions, map[common.Address]types.Transactions) {
 	return b.rue.txPool.Content()
 }
 
+func (b *LesApiBackend) SubscribeTxDropEvent(ch chan<- core.TxDropEvent) event.Subscription {
+	// The light transaction pool doesn't drop transactions on its own
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		<-quit
+		return nil
+	})
+}
+
+func (b *LesApiBackend) TxPoolRejections() []*core.TxRejection {
+	return nil
+}
+
 func (b *LesApiBackend) SubscribeTxPreEvent(ch chan<- core.TxPreEvent) event.Subscription {
 	return b.rue.txPool.SubscribeTxPreEvent(ch)
 }
@@ -181,6 +206,14 @@ func (b *LesApiBackend) BloomStatus() (uint64, uint64) {
 	return light.BloomTrieFrequency, sections
 }
 
+func (b *LesApiBackend) LogIndexStatus() (uint64, uint64) {
+	return 0, 0 // light clients don't have the receipts to maintain a log index
+}
+
+func (b *LesApiBackend) AddressIndexStatus() (uint64, uint64, common.Hash) {
+	return 0, 0, common.Hash{} // light clients don't have the block bodies to maintain an address index
+}
+
 func (b *LesApiBackend) ServiceFilter(ctx context.Context, session *bloombits.MatcherSession) {
 	for i := 0; i < bloomFilterThreads; i++ {
 		go session.Multiplex(bloomRetrievalBatch, bloomRetrievalWait, b.rue.bloomRequests)
@@ -101,6 +101,7 @@ type ProtocolManager struct {
 	chainConfig *params.ChainConfig
 	blockchain  BlockChain
 	chainDb     ruedb.Database
+	trieDb      *trie.Database // State trie database, retaining recent tries in memory on servers
 	odr         *LesOdr
 	server      *LesServer
 	serverPool  *serverPool
@@ -150,6 +151,13 @@ func NewProtocolManager(chainConfig *params.ChainConfig, lightSync bool, protoco
 		manager.retriever = odr.retriever
 		manager.reqDist = odr.retriever.dist
 	}
+	// Serve state from the chain's own trie database if available, as the most
+	// recent tries are only flushed to disk periodically
+	if bc, ok := blockchain.(*core.BlockChain); ok {
+		manager.trieDb = bc.StateCache().TrieDB()
+	} else {
+		manager.trieDb = trie.NewDatabase(chainDb)
+	}
 
 	// Initiate a sub-protocol for every implemented version we can handle
 	manager.SubProtocols = make([]p2p.Protocol, 0, len(protocolVersions))
@@ -569,11 +577,11 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 		for _, req := range req.Reqs {
 			// Retrieve the requested state entry, stopping if enough was found
 			if header := core.GetHeader(pm.chainDb, req.BHash, core.GetBlockNumber(pm.chainDb, req.BHash)); header != nil {
-				if trie, _ := trie.New(header.Root, pm.chainDb); trie != nil {
+				if trie, _ := trie.New(header.Root, pm.trieDb); trie != nil {
 					sdata := trie.Get(req.AccKey)
 					var acc state.Account
 					if err := rlp.DecodeBytes(sdata, &acc); err == nil {
-						entry, _ := pm.chainDb.Get(acc.CodeHash)
+						entry, _ := pm.trieDb.Node(common.BytesToHash(acc.CodeHash))
 						if bytes+len(entry) >= softResponseLimit {
 							break
 						}
@@ -696,13 +704,13 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 			}
 			// Retrieve the requested state entry, stopping if enough was found
 			if header := core.GetHeader(pm.chainDb, req.BHash, core.GetBlockNumber(pm.chainDb, req.BHash)); header != nil {
-				if tr, _ := trie.New(header.Root, pm.chainDb); tr != nil {
+				if tr, _ := trie.New(header.Root, pm.trieDb); tr != nil {
 					if len(req.AccKey) > 0 {
 						sdata := tr.Get(req.AccKey)
 						tr = nil
 						var acc state.Account
 						if err := rlp.DecodeBytes(sdata, &acc); err == nil {
-							tr, _ = trie.New(acc.Root, pm.chainDb)
+							tr, _ = trie.New(acc.Root, pm.trieDb)
 						}
 					}
 					if tr != nil {
@@ -747,7 +755,7 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 			}
 			if tr == nil || req.BHash != lastBHash {
 				if header := core.GetHeader(pm.chainDb, req.BHash, core.GetBlockNumber(pm.chainDb, req.BHash)); header != nil {
-					tr, _ = trie.New(header.Root, pm.chainDb)
+					tr, _ = trie.New(header.Root, pm.trieDb)
 				} else {
 					tr = nil
 				}
@@ -761,7 +769,7 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 						str = nil
 						var acc state.Account
 						if err := rlp.DecodeBytes(sdata, &acc); err == nil {
-							str, _ = trie.New(acc.Root, pm.chainDb)
+							str, _ = trie.New(acc.Root, pm.trieDb)
 						}
 						lastAccKey = common.CopyBytes(req.AccKey)
 					}
@@ -839,7 +847,7 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 		if reject(uint64(reqCnt), MaxHelperTrieProofsFetch) {
 			return errResp(ErrRequestRejected, "")
 		}
-		trieDb := ruedb.NewTable(pm.chainDb, light.ChtTablePrefix)
+		trieDb := trie.NewDatabase(ruedb.NewTable(pm.chainDb, light.ChtTablePrefix))
 		for _, req := range req.Reqs {
 			if bytes >= softResponseLimit {
 				break
@@ -900,7 +908,7 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 				var prefix string
 				root, prefix = pm.getHelperTrie(req.HelperTrieType, req.TrieIdx)
 				if root != (common.Hash{}) {
-					if t, err := trie.New(root, ruedb.NewTable(pm.chainDb, prefix)); err == nil {
+					if t, err := trie.New(root, trie.NewDatabase(ruedb.NewTable(pm.chainDb, prefix))); err == nil {
 						tr = t
 					}
 				}
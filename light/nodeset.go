@@ -23,11 +23,11 @@ import (
 	"github.com/Rue-Foundation/go-rue/common"
 	"github.com/Rue-Foundation/go-rue/crypto"
 	"github.com/Rue-Foundation/go-rue/rlp"
-	"github.com/Rue-Foundation/go-rue/trie"
+	"github.com/Rue-Foundation/go-rue/ruedb"
 )
 
-// NodeSet stores a set of trie nodes. It implements trie.Database and can also
-// act as a cache for another trie.Database.
+// NodeSet stores a set of trie nodes. It implements trie.DatabaseReader and
+// ruedb.Putter and can also act as a cache for another trie.Database.
 type NodeSet struct {
 	db       map[string][]byte
 	dataSize int
@@ -99,7 +99,7 @@ func (db *NodeSet) NodeList() NodeList {
 }
 
 // Store writes the contents of the set to the given database
-func (db *NodeSet) Store(target trie.Database) {
+func (db *NodeSet) Store(target ruedb.Putter) {
 	db.lock.RLock()
 	defer db.lock.RUnlock()
 
@@ -108,11 +108,11 @@ func (db *NodeSet) Store(target trie.Database) {
 	}
 }
 
-// NodeList stores an ordered list of trie nodes. It implements trie.DatabaseWriter.
+// NodeList stores an ordered list of trie nodes. It implements ruedb.Putter.
 type NodeList []rlp.RawValue
 
 // Store writes the contents of the list to the given database
-func (n NodeList) Store(db trie.Database) {
+func (n NodeList) Store(db ruedb.Putter) {
 	for _, node := range n {
 		db.Put(crypto.Keccak256(node), node)
 	}
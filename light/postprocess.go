@@ -114,6 +114,7 @@ func StoreChtRoot(db ruedb.Database, sectionIdx uint64, sectionHead, root common
 // ChtIndexerBackend implements core.ChainIndexerBackend
 type ChtIndexerBackend struct {
 	db, cdb              ruedb.Database
+	triedb               *trie.Database
 	section, sectionSize uint64
 	lastHash             common.Hash
 	trie                 *trie.Trie
@@ -131,7 +132,7 @@ func NewChtIndexer(db ruedb.Database, clientMode bool) *core.ChainIndexer {
 		sectionSize = ChtV1Frequency
 		confirmReq = HelperTrieProcessConfirmations
 	}
-	return core.NewChainIndexer(db, idb, &ChtIndexerBackend{db: db, cdb: cdb, sectionSize: sectionSize}, sectionSize, confirmReq, time.Millisecond*100, "cht")
+	return core.NewChainIndexer(db, idb, &ChtIndexerBackend{db: db, cdb: cdb, triedb: trie.NewDatabase(cdb), sectionSize: sectionSize}, sectionSize, confirmReq, time.Millisecond*100, "cht")
 }
 
 // Reset implements core.ChainIndexerBackend
@@ -141,7 +142,7 @@ func (c *ChtIndexerBackend) Reset(section uint64, lastSectionHead common.Hash) e
 		root = GetChtRoot(c.db, section-1, lastSectionHead)
 	}
 	var err error
-	c.trie, err = trie.New(root, c.cdb)
+	c.trie, err = trie.New(root, c.triedb)
 	c.section = section
 	return err
 }
@@ -163,12 +164,13 @@ func (c *ChtIndexerBackend) Process(header *types.Header) {
 
 // Commit implements core.ChainIndexerBackend
 func (c *ChtIndexerBackend) Commit() error {
-	batch := c.cdb.NewBatch()
-	root, err := c.trie.CommitTo(batch)
+	root, err := c.trie.Commit(nil)
 	if err != nil {
 		return err
 	} else {
-		batch.Write()
+		if err := c.triedb.Commit(root, false); err != nil {
+			return err
+		}
 		if ((c.section+1)*c.sectionSize)%ChtFrequency == 0 {
 			log.Info("Storing CHT", "idx", c.section*c.sectionSize/ChtFrequency, "sectionHead", fmt.Sprintf("%064x", c.lastHash), "root", fmt.Sprintf("%064x", root))
 		}
@@ -206,6 +208,7 @@ func StoreBloomTrieRoot(db ruedb.Database, sectionIdx uint64, sectionHead, root
 // BloomTrieIndexerBackend implements core.ChainIndexerBackend
 type BloomTrieIndexerBackend struct {
 	db, cdb                                    ruedb.Database
+	triedb                                     *trie.Database
 	section, parentSectionSize, bloomTrieRatio uint64
 	trie                                       *trie.Trie
 	sectionHeads                               []common.Hash
@@ -215,7 +218,7 @@ type BloomTrieIndexerBackend struct {
 func NewBloomTrieIndexer(db ruedb.Database, clientMode bool) *core.ChainIndexer {
 	cdb := ruedb.NewTable(db, BloomTrieTablePrefix)
 	idb := ruedb.NewTable(db, "bltIndex-")
-	backend := &BloomTrieIndexerBackend{db: db, cdb: cdb}
+	backend := &BloomTrieIndexerBackend{db: db, cdb: cdb, triedb: trie.NewDatabase(cdb)}
 	var confirmReq uint64
 	if clientMode {
 		backend.parentSectionSize = BloomTrieFrequency
@@ -236,7 +239,7 @@ func (b *BloomTrieIndexerBackend) Reset(section uint64, lastSectionHead common.H
 		root = GetBloomTrieRoot(b.db, section-1, lastSectionHead)
 	}
 	var err error
-	b.trie, err = trie.New(root, b.cdb)
+	b.trie, err = trie.New(root, b.triedb)
 	b.section = section
 	return err
 }
@@ -280,12 +283,13 @@ func (b *BloomTrieIndexerBackend) Commit() error {
 		}
 	}
 
-	batch := b.cdb.NewBatch()
-	root, err := b.trie.CommitTo(batch)
+	root, err := b.trie.Commit(nil)
 	if err != nil {
 		return err
 	} else {
-		batch.Write()
+		if err := b.triedb.Commit(root, false); err != nil {
+			return err
+		}
 		sectionHead := b.sectionHeads[b.bloomTrieRatio-1]
 		log.Info("Storing BloomTrie", "section", b.section, "sectionHead", fmt.Sprintf("%064x", sectionHead), "root", fmt.Sprintf("%064x", root), "compression ratio", float64(compSize)/float64(decompSize))
 		StoreBloomTrieRoot(b.db, b.section, sectionHead, root)
@@ -18,6 +18,7 @@ package node
 
 import (
 	"crypto/ecdsa"
+	"encoding/hex"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -33,6 +34,8 @@ import (
 	"github.com/Rue-Foundation/go-rue/log"
 	"github.com/Rue-Foundation/go-rue/p2p"
 	"github.com/Rue-Foundation/go-rue/p2p/discover"
+	"github.com/Rue-Foundation/go-rue/rpc"
+	"github.com/Rue-Foundation/go-rue/ruedb"
 )
 
 const (
@@ -41,6 +44,7 @@ const (
 	datadirStaticNodes     = "static-nodes.json"  // Path within the datadir to the static node list
 	datadirTrustedNodes    = "trusted-nodes.json" // Path within the datadir to the trusted node list
 	datadirNodeDatabase    = "nodes"              // Path within the datadir to store the node infos
+	datadirDatabaseEngine  = "dbengine"           // Path within the datadir to the database engine marker
 )
 
 // Config represents a small collection of configuration values to fine tune the
@@ -66,6 +70,12 @@ type Config struct {
 	// in memory.
 	DataDir string
 
+	// DBEngine is the backend engine of the persistent databases, one of the
+	// engines registered in ruedb. The engine is recorded in the data directory
+	// when its databases are first created and can't be changed afterwards. If
+	// empty, the recorded engine, or else leveldb, is used.
+	DBEngine string `toml:",omitempty"`
+
 	// Configuration of peer-to-peer networking.
 	P2P p2p.Config
 
@@ -105,6 +115,15 @@ type Config struct {
 	// useless for custom HTTP clients.
 	HTTPCors []string `toml:",omitempty"`
 
+	// HTTPVirtualHosts is the list of virtual hostnames which are allowed on incoming
+	// requests. This is by default {'localhost'}. Using this prevents attacks like
+	// DNS rebinding, which bypasses SOP by simply masquerading as being within the
+	// same origin. These attacks do not utilize CORS, since they are not cross-domain.
+	// By explicitly checking the Host header, the server will not allow requests
+	// made against the server with a malicious host domain. Requests using an IP
+	// address directly are not affected.
+	HTTPVirtualHosts []string `toml:",omitempty"`
+
 	// HTTPModules is a list of API modules to expose via the HTTP RPC interface.
 	// If the module list is empty, all RPC API endpoints designated public will be
 	// exposed.
@@ -136,8 +155,38 @@ type Config struct {
 	// private APIs to untrusted users is a major security risk.
 	WSExposeAll bool `toml:",omitempty"`
 
+	// JWTSecret is the path of a file containing the hex encoded secret to verify
+	// HS256 JWT bearer tokens with on the HTTP and websocket RPC interfaces. If the
+	// field is empty, the interfaces are served without authentication.
+	JWTSecret string `toml:",omitempty"`
+
+	// JWTPublic is the list of API namespaces and methods which may be invoked
+	// without a bearer token when JWT authentication is enabled.
+	JWTPublic []string `toml:",omitempty"`
+
+	// RPCLimits are the request rate limits and resource quotas enforced on the
+	// HTTP and websocket RPC interfaces.
+	RPCLimits rpc.Limits `toml:",omitempty"`
+
+	// GraphQLHost is the host interface on which to start the GraphQL server. If
+	// this field is empty, no GraphQL endpoint will be started.
+	GraphQLHost string `toml:",omitempty"`
+
+	// GraphQLPort is the TCP port number on which to start the GraphQL server. The
+	// default zero value is/ valid and will pick a port number randomly (useful
+	// for ephemeral nodes).
+	GraphQLPort int `toml:",omitempty"`
+
+	// GraphQLCors is the Cross-Origin Resource Sharing header to send to requesting
+	// clients of the GraphQL server.
+	GraphQLCors []string `toml:",omitempty"`
+
+	// GraphQLVirtualHosts is the list of virtual hostnames which are allowed on
+	// incoming requests to the GraphQL server. See HTTPVirtualHosts for details.
+	GraphQLVirtualHosts []string `toml:",omitempty"`
+
 	// Logger is a custom logger to use with the p2p.Server.
-	Logger log.Logger
+	Logger log.Logger `toml:",omitempty"`
 }
 
 // IPCEndpoint resolves an IPC endpoint based on a configured value, taking into
@@ -200,6 +249,15 @@ func DefaultHTTPEndpoint() string {
 	return config.HTTPEndpoint()
 }
 
+// GraphQLEndpoint resolves a GraphQL endpoint based on the configured host
+// interface and port parameters.
+func (c *Config) GraphQLEndpoint() string {
+	if c.GraphQLHost == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d", c.GraphQLHost, c.GraphQLPort)
+}
+
 // WSEndpoint resolves an websocket endpoint based on the configured host interface
 // and port parameters.
 func (c *Config) WSEndpoint() string {
@@ -283,6 +341,71 @@ func (c *Config) instanceDir() string {
 	return filepath.Join(c.DataDir, c.name())
 }
 
+// DatabaseEngine returns the backend engine of the persistent databases in the
+// instance directory. The engine recorded in the directory takes precedence, a
+// conflicting configured engine is an error. Instance directories populated
+// before engines were recorded hold leveldb databases.
+func (c *Config) DatabaseEngine() (string, error) {
+	blob, err := ioutil.ReadFile(c.resolvePath(datadirDatabaseEngine))
+	switch {
+	case err == nil:
+		engine := strings.TrimSpace(string(blob))
+		if c.DBEngine != "" && c.DBEngine != engine {
+			return "", fmt.Errorf("data directory uses database engine %q, %q requested", engine, c.DBEngine)
+		}
+		return engine, nil
+
+	case os.IsNotExist(err):
+		if common.FileExist(c.resolvePath("chaindata")) || common.FileExist(c.resolvePath("lightchaindata")) {
+			if c.DBEngine != "" && c.DBEngine != ruedb.DefaultEngine {
+				return "", fmt.Errorf("data directory uses database engine %q, %q requested", ruedb.DefaultEngine, c.DBEngine)
+			}
+			return ruedb.DefaultEngine, nil
+		}
+		if c.DBEngine != "" {
+			return c.DBEngine, nil
+		}
+		return ruedb.DefaultEngine, nil
+
+	default:
+		return "", err
+	}
+}
+
+// SetDatabaseEngine records the backend engine of the persistent databases in
+// the instance directory. The marker is replaced atomically, so it names either
+// the previous or the new engine even if writing fails midway.
+func (c *Config) SetDatabaseEngine(engine string) error {
+	path := c.resolvePath(datadirDatabaseEngine)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(path+".tmp", []byte(engine+"\n"), 0644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// openDatabase opens a persistent database in the instance directory with the
+// configured backend engine, recording the engine on first use.
+func (c *Config) openDatabase(name string, cache, handles int) (ruedb.Database, error) {
+	engine, err := c.DatabaseEngine()
+	if err != nil {
+		return nil, err
+	}
+	db, err := ruedb.Open(engine, c.resolvePath(name), cache, handles)
+	if err != nil {
+		return nil, err
+	}
+	if !common.FileExist(c.resolvePath(datadirDatabaseEngine)) {
+		if err := c.SetDatabaseEngine(engine); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+	return db, nil
+}
+
 // NodeKey retrieves the currently configured private key of the node, checking
 // first any manually set key, falling back to the one found in the configured
 // data folder. If no key can be found, a new one is generated.
@@ -321,6 +444,26 @@ func (c *Config) NodeKey() *ecdsa.PrivateKey {
 	return key
 }
 
+// jwtSecret loads the secret to verify RPC bearer tokens with, or nil if JWT
+// authentication isn't enabled.
+func (c *Config) jwtSecret() ([]byte, error) {
+	if c.JWTSecret == "" {
+		return nil, nil
+	}
+	blob, err := ioutil.ReadFile(c.JWTSecret)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(string(blob)), "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT secret: %v", err)
+	}
+	if len(secret) < 32 {
+		return nil, fmt.Errorf("JWT secret too short: have %d bytes, want at least 32", len(secret))
+	}
+	return secret, nil
+}
+
 // StaticNodes returns a list of node enode URLs configured as static nodes.
 func (c *Config) StaticNodes() []*discover.Node {
 	return c.parsePersistentNodes(c.resolvePath(datadirStaticNodes))
@@ -26,8 +26,20 @@ import (
 
 	"github.com/Rue-Foundation/go-rue/crypto"
 	"github.com/Rue-Foundation/go-rue/p2p"
+	"github.com/Rue-Foundation/go-rue/ruedb"
 )
 
+func init() {
+	// Register an engine distinct from the default one to test the recording
+	ruedb.Register("testdb", func(file string, cache int, handles int) (ruedb.Database, error) {
+		db, err := ruedb.NewMemDatabase()
+		if err != nil {
+			return nil, err
+		}
+		return db, nil
+	})
+}
+
 // Tests that datadirs can be successfully created, be them manually configured
 // ones or automatically generated temporary ones.
 func TestDatadirCreation(t *testing.T) {
@@ -148,3 +160,34 @@ func TestNodeKeyPersistency(t *testing.T) {
 		t.Fatalf("ephemeral node key persisted to disk")
 	}
 }
+
+// Tests that the database engine is recorded in the instance directory when the
+// first database is created, and that a conflicting engine is refused later.
+func TestDatabaseEngineRecording(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatalf("failed to create temporary data directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	config := &Config{Name: "unit-test", DataDir: dir, DBEngine: "testdb"}
+	db, err := config.openDatabase("chaindata", 0, 0)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	db.Close()
+
+	if blob, err := ioutil.ReadFile(filepath.Join(dir, "unit-test", datadirDatabaseEngine)); err != nil || string(bytes.TrimSpace(blob)) != "testdb" {
+		t.Fatalf("engine not recorded: have %q, err %v", blob, err)
+	}
+	// Reopening without an explicit engine should use the recorded one
+	config = &Config{Name: "unit-test", DataDir: dir}
+	if engine, err := config.DatabaseEngine(); err != nil || engine != "testdb" {
+		t.Fatalf("recorded engine mismatch: have %q, err %v", engine, err)
+	}
+	// Requesting a different engine should be refused
+	config = &Config{Name: "unit-test", DataDir: dir, DBEngine: "leveldb"}
+	if _, err := config.openDatabase("chaindata", 0, 0); err == nil {
+		t.Fatalf("conflicting engine accepted")
+	}
+}
@@ -263,7 +263,7 @@ func (n *Node) startRPC(services map[reflect.Type]Service) error {
 		n.stopInProc()
 		return err
 	}
-	if err := n.startHTTP(n.httpEndpoint, apis, n.config.HTTPModules, n.config.HTTPCors); err != nil {
+	if err := n.startHTTP(n.httpEndpoint, apis, n.config.HTTPModules, n.config.HTTPCors, n.config.HTTPVirtualHosts); err != nil {
 		n.stopIPC()
 		n.stopInProc()
 		return err
@@ -365,7 +365,7 @@ func (n *Node) stopIPC() {
 }
 
 // startHTTP initializes and starts the HTTP RPC endpoint.
-func (n *Node) startHTTP(endpoint string, apis []rpc.API, modules []string, cors []string) error {
+func (n *Node) startHTTP(endpoint string, apis []rpc.API, modules []string, cors []string, vhosts []string) error {
 	// Short circuit if the HTTP endpoint isn't being exposed
 	if endpoint == "" {
 		return nil
@@ -385,6 +385,10 @@ func (n *Node) startHTTP(endpoint string, apis []rpc.API, modules []string, cors
 			n.log.Debug(fmt.Sprintf("HTTP registered %T under '%s'", api.Service, api.Namespace))
 		}
 	}
+	handler.SetLimits(n.config.RPCLimits)
+	if err := n.setupAuthentication(handler); err != nil {
+		return err
+	}
 	// All APIs registered, start the HTTP listener
 	var (
 		listener net.Listener
@@ -393,7 +397,7 @@ func (n *Node) startHTTP(endpoint string, apis []rpc.API, modules []string, cors
 	if listener, err = net.Listen("tcp", endpoint); err != nil {
 		return err
 	}
-	go rpc.NewHTTPServer(cors, handler).Serve(listener)
+	go rpc.NewHTTPServer(cors, vhosts, handler).Serve(listener)
 	n.log.Info(fmt.Sprintf("HTTP endpoint opened: http://%s", endpoint))
 
 	// All listeners booted successfully
@@ -418,6 +422,17 @@ func (n *Node) stopHTTP() {
 	}
 }
 
+// setupAuthentication enables JWT authentication on an RPC handler serving HTTP
+// or websocket connections, if a secret is configured.
+func (n *Node) setupAuthentication(handler *rpc.Server) error {
+	secret, err := n.config.jwtSecret()
+	if err != nil || secret == nil {
+		return err
+	}
+	handler.SetAuthenticator(rpc.NewAuthenticator(secret, n.config.JWTPublic))
+	return nil
+}
+
 // startWS initializes and starts the websocket RPC endpoint.
 func (n *Node) startWS(endpoint string, apis []rpc.API, modules []string, wsOrigins []string, exposeAll bool) error {
 	// Short circuit if the WS endpoint isn't being exposed
@@ -439,6 +454,10 @@ func (n *Node) startWS(endpoint string, apis []rpc.API, modules []string, wsOrig
 			n.log.Debug(fmt.Sprintf("WebSocket registered %T under '%s'", api.Service, api.Namespace))
 		}
 	}
+	handler.SetLimits(n.config.RPCLimits)
+	if err := n.setupAuthentication(handler); err != nil {
+		return err
+	}
 	// All APIs registered, start the HTTP listener
 	var (
 		listener net.Listener
@@ -646,7 +665,7 @@ func (n *Node) OpenDatabase(name string, cache, handles int) (ruedb.Database, er
 	if n.config.DataDir == "" {
 		return ruedb.NewMemDatabase()
 	}
-	return ruedb.NewLDBDatabase(n.config.resolvePath(name), cache, handles)
+	return n.config.openDatabase(name, cache, handles)
 }
 
 // ResolvePath returns the absolute path of a resource in the instance directory.
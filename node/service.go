@@ -43,11 +43,7 @@ func (ctx *ServiceContext) OpenDatabase(name string, cache int, handles int) (ru
 	if ctx.config.DataDir == "" {
 		return ruedb.NewMemDatabase()
 	}
-	db, err := ruedb.NewLDBDatabase(ctx.config.resolvePath(name), cache, handles)
-	if err != nil {
-		return nil, err
-	}
-	return db, nil
+	return ctx.config.openDatabase(name, cache, handles)
 }
 
 // ResolvePath resolves a user path into the data directory if that was relative
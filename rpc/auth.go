@@ -0,0 +1,137 @@
+// Copyright 2018 The go-ruereum Authors
+// This file is part of the go-ruereum library.
+//
+// The go-ruereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ruereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ruereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Rue-Foundation/go-rue/log"
+	jwt "github.com/dgrijalva/jwt-go"
+)
+
+// AuthClaims are the JWT claims accepted by an Authenticator. Allow is the list
+// of namespaces (e.g. "admin"), namespace wildcards ("admin_*"), single methods
+// ("admin_peers") or everything ("*") the bearer of the token may invoke. Tokens
+// without an allow-list grant access to every method served by the endpoint.
+type AuthClaims struct {
+	jwt.StandardClaims
+	Allow []string `json:"allow,omitempty"`
+}
+
+// Authenticator verifies the HS256 JWT bearer tokens of HTTP and websocket RPC
+// requests, and enforces the methods each caller is allowed to invoke.
+type Authenticator struct {
+	secret []byte   // Shared secret to verify token signatures with
+	public []string // Namespaces and methods callable without a token
+	log    log.Logger
+}
+
+// NewAuthenticator creates an authenticator verifying tokens signed with the given
+// secret. Requests without a token may only invoke the public namespaces and
+// methods, formatted the same way as token allow-lists.
+func NewAuthenticator(secret []byte, public []string) *Authenticator {
+	return &Authenticator{
+		secret: secret,
+		public: public,
+		log:    log.New("module", "rpc-auth"),
+	}
+}
+
+// authInfo is the outcome of authenticating a connection, consulted for each of
+// the calls made through it.
+type authInfo struct {
+	auth     *Authenticator
+	identity string   // Subject of the token, empty for anonymous requests
+	remote   string   // Remote address of the connection
+	allow    []string // Methods the caller may invoke, nil for all
+	err      error    // Reason the presented token was rejected
+}
+
+// authKey is the context key of the authentication info of a connection.
+type authKey struct{}
+
+// authenticate verifies the bearer token of an HTTP request, if any, and returns
+// the context to serve the requests arriving through its connection with.
+func (a *Authenticator) authenticate(ctx context.Context, r *http.Request) context.Context {
+	info := &authInfo{auth: a, remote: r.RemoteAddr, allow: a.public}
+
+	if header := r.Header.Get("Authorization"); header != "" {
+		if !strings.HasPrefix(header, "Bearer ") {
+			info.err = fmt.Errorf("unsupported authorization scheme")
+		} else {
+			claims := new(AuthClaims)
+			parser := &jwt.Parser{ValidMethods: []string{jwt.SigningMethodHS256.Alg()}}
+			_, err := parser.ParseWithClaims(strings.TrimPrefix(header, "Bearer "), claims, func(*jwt.Token) (interface{}, error) {
+				return a.secret, nil
+			})
+			if err != nil {
+				info.err = fmt.Errorf("invalid token: %v", err)
+			} else {
+				info.identity, info.allow = claims.Subject, claims.Allow
+				if info.identity == "" {
+					info.identity = "unnamed"
+				}
+			}
+		}
+	}
+	return context.WithValue(ctx, authKey{}, info)
+}
+
+// authorize checks whether the caller of a connection may invoke the given method,
+// logging the decision. Connections without authentication info are not subject
+// to access control.
+func authorize(ctx context.Context, service, method string) Error {
+	info, ok := ctx.Value(authKey{}).(*authInfo)
+	if !ok || service == MetadataApi {
+		return nil
+	}
+	name := service + serviceMethodSeparator + method
+
+	if info.err != nil {
+		info.auth.log.Warn("Rejected RPC call with invalid credentials", "method", name, "remote", info.remote, "err", info.err)
+		return &unauthorizedError{info.err.Error()}
+	}
+	if info.identity != "" && info.allow == nil {
+		info.auth.log.Info("Accepted RPC call", "method", name, "identity", info.identity, "remote", info.remote)
+		return nil
+	}
+	for _, entry := range info.allow {
+		if entry == "*" || entry == service || entry == service+serviceMethodSeparator+"*" || entry == name {
+			if info.identity == "" {
+				info.auth.log.Debug("Accepted anonymous RPC call", "method", name, "remote", info.remote)
+			} else {
+				info.auth.log.Info("Accepted RPC call", "method", name, "identity", info.identity, "remote", info.remote)
+			}
+			return nil
+		}
+	}
+	if info.identity == "" {
+		info.auth.log.Warn("Rejected anonymous RPC call", "method", name, "remote", info.remote)
+		return &unauthorizedError{fmt.Sprintf("authentication required for %s", name)}
+	}
+	info.auth.log.Warn("Rejected RPC call", "method", name, "identity", info.identity, "remote", info.remote)
+	return &forbiddenError{name}
+}
+
+// SetAuthenticator enables bearer token authentication and access control for
+// the requests served over HTTP and websocket connections.
+func (s *Server) SetAuthenticator(auth *Authenticator) {
+	s.auth = auth
+}
@@ -0,0 +1,92 @@
+// Copyright 2018 The go-ruereum Authors
+// This file is part of the go-ruereum library.
+//
+// The go-ruereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ruereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ruereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+)
+
+// Tests that HTTP requests are authenticated using JWT bearer tokens and that
+// the namespaces and methods callable are restricted by token and anonymously.
+func TestHTTPAuthentication(t *testing.T) {
+	secret := []byte("0123456789abcdef0123456789abcdef")
+
+	server := NewServer()
+	defer server.Stop()
+
+	if err := server.RegisterName("test", new(Service)); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.RegisterName("admin", new(Service)); err != nil {
+		t.Fatal(err)
+	}
+	server.SetAuthenticator(NewAuthenticator(secret, []string{"test_rets"}))
+
+	sign := func(key []byte, claims *AuthClaims) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(key)
+		if err != nil {
+			t.Fatalf("failed to sign token: %v", err)
+		}
+		return token
+	}
+	var (
+		full    = sign(secret, &AuthClaims{StandardClaims: jwt.StandardClaims{Subject: "ops"}})
+		limited = sign(secret, &AuthClaims{StandardClaims: jwt.StandardClaims{Subject: "tool"}, Allow: []string{"admin_*"}})
+		expired = sign(secret, &AuthClaims{StandardClaims: jwt.StandardClaims{ExpiresAt: time.Now().Add(-time.Hour).Unix()}})
+		forged  = sign([]byte("not the secret, not the secret!!"), &AuthClaims{})
+	)
+	tests := []struct {
+		header string
+		method string
+		code   int
+	}{
+		{"", "test_rets", 0},
+		{"", "rpc_modules", 0},
+		{"", "admin_rets", -32001},
+		{"Bearer " + full, "admin_rets", 0},
+		{"Bearer " + full, "test_rets", 0},
+		{"Bearer " + limited, "admin_rets", 0},
+		{"Bearer " + limited, "test_rets", -32002},
+		{"Bearer " + expired, "test_rets", -32001},
+		{"Bearer " + forged, "test_rets", -32001},
+		{"Basic Zm9vOmJhcg==", "test_rets", -32001},
+	}
+	for i, tt := range tests {
+		body := `{"jsonrpc":"2.0","id":1,"method":"` + tt.method + `","params":[]}`
+		request := httptest.NewRequest("POST", "http://url.com", strings.NewReader(body))
+		request.Header.Set("content-type", contentType)
+		if tt.header != "" {
+			request.Header.Set("Authorization", tt.header)
+		}
+		recorder := httptest.NewRecorder()
+		server.ServeHTTP(recorder, request)
+
+		var response jsonErrResponse
+		if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+			t.Fatalf("test %d: failed to decode response: %v", i, err)
+		}
+		if response.Error.Code != tt.code {
+			t.Errorf("test %d: error code mismatch: have %d, want %d (%s)", i, response.Error.Code, tt.code, response.Error.Message)
+		}
+	}
+}
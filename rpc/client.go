@@ -125,13 +125,19 @@ type Client struct {
 	sendDone    chan error                     // signals write completion, releases write lock
 	respWait    map[string]*requestOp          // active requests
 	subs        map[string]*ClientSubscription // active subscriptions
+
+	// for resilient mode, see EnableResilience
+	minBackoff time.Duration   // initial delay between reconnect attempts, zero if disabled
+	maxBackoff time.Duration   // upper bound of the exponentially growing delay
+	recoverOp  chan *recoverOp // hands lost subscriptions from dispatch to recover
 }
 
 type requestOp struct {
-	ids  []json.RawMessage
-	err  error
-	resp chan *jsonrpcMessage // receives up to len(ids) responses
-	sub  *ClientSubscription  // only set for EthSubscribe requests
+	ids         []json.RawMessage
+	err         error
+	resp        chan *jsonrpcMessage // receives up to len(ids) responses
+	sub         *ClientSubscription  // only set for EthSubscribe requests
+	resubscribe bool                 // whether sub is re-issued after a reconnect
 }
 
 func (op *requestOp) wait(ctx context.Context) (*jsonrpcMessage, error) {
@@ -198,6 +204,7 @@ func newClient(initctx context.Context, connectFunc func(context.Context) (net.C
 		sendDone:    make(chan error, 1),
 		respWait:    make(map[string]*requestOp),
 		subs:        make(map[string]*ClientSubscription),
+		recoverOp:   make(chan *recoverOp),
 	}
 	if !isHTTP {
 		go c.dispatch(conn)
@@ -391,7 +398,7 @@ func (c *Client) Subscribe(ctx context.Context, namespace string, channel interf
 	op := &requestOp{
 		ids:  []json.RawMessage{msg.ID},
 		resp: make(chan *jsonrpcMessage),
-		sub:  newClientSubscription(c, namespace, chanVal),
+		sub:  newClientSubscription(c, namespace, chanVal, msg.Params),
 	}
 
 	// Send the subscription request.
@@ -402,6 +409,12 @@ func (c *Client) Subscribe(ctx context.Context, namespace string, channel interf
 	if _, err := op.wait(ctx); err != nil {
 		return nil, err
 	}
+	// In resilient mode, remember where the stream starts to backfill from
+	if c.minBackoff > 0 && op.sub.kind != "" {
+		if err := c.markSubscription(ctx, op.sub); err != nil {
+			log.Debug("Failed to mark subscription start", "err", err)
+		}
+	}
 	return op.sub, nil
 }
 
@@ -479,10 +492,31 @@ func (c *Client) dispatch(conn net.Conn) {
 		lastOp        *requestOp    // tracks last send operation
 		requestOpLock = c.requestOp // nil while the send lock is held
 		reading       = true        // if true, a read loop is running
+
+		lost       []*ClientSubscription // subscriptions waiting to be re-issued
+		recovering bool                  // if true, a recover loop is running
 	)
+	// suspend detaches the subscriptions of a dead connection to re-issue them
+	// on a new one, if running in resilient mode.
+	suspend := func() {
+		if c.minBackoff == 0 {
+			return
+		}
+		for id, sub := range c.subs {
+			delete(c.subs, id)
+			lost = append(lost, sub)
+		}
+		if len(lost) > 0 && !recovering {
+			recovering = true
+			go c.recover()
+		}
+	}
 	defer close(c.didQuit)
 	defer func() {
 		c.closeRequestOps(ErrClientQuit)
+		for _, sub := range lost {
+			sub.quitWithError(ErrClientQuit, false)
+		}
 		conn.Close()
 		if reading {
 			// Empty read channels until read is dead.
@@ -525,6 +559,7 @@ func (c *Client) dispatch(conn net.Conn) {
 
 		case err := <-c.readErr:
 			log.Debug(fmt.Sprintf("<-readErr: %v", err))
+			suspend()
 			c.closeRequestOps(err)
 			conn.Close()
 			reading = false
@@ -535,11 +570,22 @@ func (c *Client) dispatch(conn net.Conn) {
 				// Wait for the previous read loop to exit. This is a rare case.
 				conn.Close()
 				<-c.readErr
+				suspend()
 			}
 			go c.read(newconn)
 			reading = true
 			conn = newconn
 
+		case op := <-c.recoverOp:
+			// Hand the lost subscriptions over to the recover loop, or stop it
+			// if there are none left.
+			lost = append(lost, op.failed...)
+			op.resp <- lost
+			if len(lost) == 0 {
+				recovering = false
+			}
+			lost = nil
+
 		// Send path.
 		case op := <-requestOpLock:
 			// Stop listening for further send ops until the current one is done.
@@ -623,9 +669,13 @@ func (c *Client) handleResponse(msg *jsonrpcMessage) {
 		op.err = msg.Error
 		return
 	}
-	if op.err = json.Unmarshal(msg.Result, &op.sub.subid); op.err == nil {
-		go op.sub.start()
-		c.subs[op.sub.subid] = op.sub
+	var subid string
+	if op.err = json.Unmarshal(msg.Result, &subid); op.err == nil {
+		op.sub.setID(subid)
+		if !op.resubscribe {
+			go op.sub.start()
+		}
+		c.subs[subid] = op.sub
 	}
 }
 
@@ -668,19 +718,29 @@ type ClientSubscription struct {
 	etype     reflect.Type
 	channel   reflect.Value
 	namespace string
-	subid     string
+	params    json.RawMessage // parameters of the subscribe call, to re-issue it with
+	kind      string          // subscription name if its events can be backfilled
 	in        chan json.RawMessage
 
+	mu        sync.Mutex        // guards the fields below
+	subid     string            // server side identifier, changes on resubscription
+	paused    bool              // whether notifications are held back for backfilling
+	held      []json.RawMessage // notifications arrived while paused
+	nextBlock uint64            // first block not delivered yet, zero if unknown
+	nextIndex uint64            // first log index within nextBlock not delivered yet
+
 	quitOnce sync.Once     // ensures quit is closed once
 	quit     chan struct{} // quit is closed when the subscription exits
 	errOnce  sync.Once     // ensures err is closed once
 	err      chan error
 }
 
-func newClientSubscription(c *Client, namespace string, channel reflect.Value) *ClientSubscription {
+func newClientSubscription(c *Client, namespace string, channel reflect.Value, params json.RawMessage) *ClientSubscription {
 	sub := &ClientSubscription{
 		client:    c,
 		namespace: namespace,
+		params:    params,
+		kind:      backfillKind(params),
 		etype:     channel.Type().Elem(),
 		channel:   channel,
 		quit:      make(chan struct{}),
@@ -728,14 +788,37 @@ func (sub *ClientSubscription) quitWithError(err error, unsubscribeServer bool)
 }
 
 func (sub *ClientSubscription) deliver(result json.RawMessage) (ok bool) {
+	sub.mu.Lock()
+	if sub.paused {
+		sub.held = append(sub.held, result)
+		sub.mu.Unlock()
+		return true
+	}
+	sub.mu.Unlock()
+	return sub.push(result)
+}
+
+// push forwards a notification to the subscriber, tracking the position of the
+// stream for backfilling.
+func (sub *ClientSubscription) push(result json.RawMessage) bool {
 	select {
 	case sub.in <- result:
+		if sub.kind != "" && sub.client.minBackoff > 0 {
+			sub.track(result)
+		}
 		return true
 	case <-sub.quit:
 		return false
 	}
 }
 
+// setID updates the server side identifier of the subscription.
+func (sub *ClientSubscription) setID(id string) {
+	sub.mu.Lock()
+	sub.subid = id
+	sub.mu.Unlock()
+}
+
 func (sub *ClientSubscription) start() {
 	sub.quitWithError(sub.forward())
 }
@@ -786,6 +869,10 @@ func (sub *ClientSubscription) unmarshal(result json.RawMessage) (interface{}, e
 }
 
 func (sub *ClientSubscription) requestUnsubscribe() error {
+	sub.mu.Lock()
+	id := sub.subid
+	sub.mu.Unlock()
+
 	var result interface{}
-	return sub.client.Call(&result, sub.namespace+unsubscribeMethodSuffix, sub.subid)
+	return sub.client.Call(&result, sub.namespace+unsubscribeMethodSuffix, id)
 }
@@ -0,0 +1,383 @@
+// Copyright 2018 The go-ruereum Authors
+// This file is part of the go-ruereum library.
+//
+// The go-ruereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ruereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ruereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/Rue-Foundation/go-rue/common/hexutil"
+	"github.com/Rue-Foundation/go-rue/log"
+)
+
+const (
+	defaultMinBackoff = 500 * time.Millisecond // initial reconnect delay if none is configured
+	defaultMaxBackoff = 30 * time.Second       // reconnect delay limit if none is configured
+	backfillTimeout   = 30 * time.Second       // timeout of each call retrieving missed events
+)
+
+// recoverOp is the hand-over of lost subscriptions between the dispatch and the
+// recover loops.
+type recoverOp struct {
+	failed []*ClientSubscription      // subscriptions that couldn't be re-issued
+	resp   chan []*ClientSubscription // receives the subscriptions to re-issue next
+}
+
+// EnableResilience switches a websocket or IPC client into resilient mode. If the
+// connection drops, the client re-dials the server with exponential backoff
+// between minBackoff and maxBackoff, and re-issues all active subscriptions
+// transparently instead of failing them.
+//
+// Events missed while disconnected are backfilled for the "newHeads" and "logs"
+// subscriptions, based on the last block delivered, so subscribers receive a
+// gap-free stream. Other subscriptions resume with the next event.
+//
+// EnableResilience must be called before any subscriptions are made and has no
+// effect on HTTP clients.
+func (c *Client) EnableResilience(minBackoff, maxBackoff time.Duration) {
+	if minBackoff <= 0 {
+		minBackoff = defaultMinBackoff
+	}
+	if maxBackoff <= 0 {
+		maxBackoff = defaultMaxBackoff
+	}
+	if maxBackoff < minBackoff {
+		maxBackoff = minBackoff
+	}
+	c.minBackoff, c.maxBackoff = minBackoff, maxBackoff
+}
+
+// recover is the loop re-establishing the lost subscriptions of a resilient client
+// until there are none left.
+func (c *Client) recover() {
+	var (
+		failed  []*ClientSubscription
+		backoff time.Duration
+	)
+	for {
+		op := &recoverOp{failed: failed, resp: make(chan []*ClientSubscription, 1)}
+		select {
+		case c.recoverOp <- op:
+		case <-c.didQuit:
+			for _, sub := range failed {
+				sub.quitWithError(ErrClientQuit, false)
+			}
+			return
+		}
+		subs := <-op.resp
+		if len(subs) == 0 {
+			return
+		}
+		// Back off if the previous attempt failed
+		if backoff > 0 {
+			select {
+			case <-time.After(backoff):
+			case <-c.didQuit:
+				for _, sub := range subs {
+					sub.quitWithError(ErrClientQuit, false)
+				}
+				return
+			}
+		}
+		failed = nil
+		if err := c.redial(); err != nil {
+			log.Debug("Failed to reconnect RPC client", "err", err)
+			failed = subs
+		} else {
+			for i, sub := range subs {
+				if err := c.resubscribe(sub); err != nil {
+					log.Debug("Failed to re-issue subscription", "err", err)
+					failed = subs[i:]
+					break
+				}
+			}
+		}
+		switch {
+		case len(failed) == 0:
+			backoff = 0
+		case backoff == 0:
+			backoff = c.minBackoff
+		default:
+			if backoff *= 2; backoff > c.maxBackoff {
+				backoff = c.maxBackoff
+			}
+		}
+	}
+}
+
+// redial replaces the connection of the client with a new one.
+func (c *Client) redial() error {
+	// Take the write lock to swap the connection out safely
+	select {
+	case c.requestOp <- new(requestOp):
+	case <-c.didQuit:
+		return ErrClientQuit
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultDialTimeout)
+	defer cancel()
+
+	err := c.reconnect(ctx)
+	c.sendDone <- err
+	return err
+}
+
+// resubscribe re-issues a subscription on the current connection and backfills
+// the events it missed. An error is only returned if the request could not be
+// made, in which case it should be retried after reconnecting.
+func (c *Client) resubscribe(sub *ClientSubscription) error {
+	select {
+	case <-sub.quit:
+		return nil // unsubscribed in the meantime
+	default:
+	}
+	// Hold back live notifications until the missed ones are delivered
+	sub.pause()
+
+	msg := &jsonrpcMessage{Version: "2.0", ID: c.nextID(), Method: sub.namespace + subscribeMethodSuffix, Params: sub.params}
+	op := &requestOp{
+		ids:         []json.RawMessage{msg.ID},
+		resp:        make(chan *jsonrpcMessage),
+		sub:         sub,
+		resubscribe: true,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), subscribeTimeout)
+	defer cancel()
+
+	if err := c.send(ctx, op, msg); err != nil {
+		if err == ErrClientQuit {
+			sub.quitWithError(err, false)
+			return nil
+		}
+		return err
+	}
+	if _, err := op.wait(ctx); err != nil {
+		switch err.(type) {
+		case *jsonError:
+			// The server refused the subscription, give up on it
+			sub.quitWithError(err, false)
+			return nil
+		default:
+			if err == ErrClientQuit {
+				sub.quitWithError(err, false)
+				return nil
+			}
+			return err
+		}
+	}
+	// The subscription is live again, any further connection loss is handled
+	// by the dispatch loop
+	if err := c.backfill(sub); err != nil {
+		if _, ok := err.(*jsonError); !ok {
+			// Retrieval failed due to the connection, which in turn suspends the
+			// subscription again. Keep notifications held back until it recovers.
+			log.Debug("Failed to backfill subscription", "err", err)
+			return nil
+		}
+		log.Warn("Failed to backfill subscription, events may be missing", "err", err)
+		sub.resume(0)
+	}
+	return nil
+}
+
+// markSubscription records the current head of the chain as the starting point
+// of a backfillable subscription.
+func (c *Client) markSubscription(ctx context.Context, sub *ClientSubscription) error {
+	head, err := c.headNumber(ctx, sub.namespace)
+	if err != nil {
+		return err
+	}
+	sub.advance(head+1, 0)
+	return nil
+}
+
+// headNumber retrieves the number of the current head block.
+func (c *Client) headNumber(ctx context.Context, namespace string) (uint64, error) {
+	var head struct {
+		Number *hexutil.Uint64 `json:"number"`
+	}
+	if err := c.CallContext(ctx, &head, namespace+"_getBlockByNumber", "latest", false); err != nil {
+		return 0, err
+	}
+	if head.Number == nil {
+		return 0, ErrNoResult
+	}
+	return uint64(*head.Number), nil
+}
+
+// backfill delivers the events a re-issued subscription missed while being
+// disconnected, then resumes the live notifications following them.
+func (c *Client) backfill(sub *ClientSubscription) error {
+	sub.mu.Lock()
+	block, index := sub.nextBlock, sub.nextIndex
+	sub.mu.Unlock()
+
+	if sub.kind == "" || block == 0 {
+		sub.resume(0)
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), backfillTimeout)
+	head, err := c.headNumber(ctx, sub.namespace)
+	cancel()
+	if err != nil {
+		return err
+	}
+	switch sub.kind {
+	case "newHeads":
+		for number := block; number <= head; number++ {
+			var header json.RawMessage
+
+			ctx, cancel := context.WithTimeout(context.Background(), backfillTimeout)
+			err := c.CallContext(ctx, &header, sub.namespace+"_getBlockByNumber", hexutil.Uint64(number), false)
+			cancel()
+			if err != nil {
+				return err
+			}
+			if !sub.push(header) {
+				return nil
+			}
+		}
+	case "logs":
+		if block > head {
+			break
+		}
+		// Reuse the filter criteria of the subscription over the missed range
+		var (
+			args []json.RawMessage
+			crit = make(map[string]interface{})
+		)
+		if err := json.Unmarshal(sub.params, &args); err == nil && len(args) > 1 {
+			json.Unmarshal(args[1], &crit)
+		}
+		crit["fromBlock"], crit["toBlock"] = hexutil.Uint64(block), hexutil.Uint64(head)
+
+		var logs []json.RawMessage
+
+		ctx, cancel := context.WithTimeout(context.Background(), backfillTimeout)
+		err := c.CallContext(ctx, &logs, sub.namespace+"_getLogs", crit)
+		cancel()
+		if err != nil {
+			return err
+		}
+		for _, entry := range logs {
+			if number, idx, ok := eventPosition(entry); ok && number == block && idx < index {
+				continue // delivered before the connection dropped
+			}
+			if !sub.push(entry) {
+				return nil
+			}
+		}
+	}
+	sub.resume(head)
+	return nil
+}
+
+// backfillKind returns the name of the subscription created with the given
+// parameters if its missed events can be backfilled, or an empty string.
+func backfillKind(params json.RawMessage) string {
+	var args []json.RawMessage
+	if err := json.Unmarshal(params, &args); err != nil || len(args) == 0 {
+		return ""
+	}
+	var name string
+	if err := json.Unmarshal(args[0], &name); err != nil {
+		return ""
+	}
+	switch name {
+	case "newHeads", "logs":
+		return name
+	}
+	return ""
+}
+
+// eventPosition extracts the block number and the log index (zero for headers)
+// of a header or log notification.
+func eventPosition(result json.RawMessage) (uint64, uint64, bool) {
+	var event struct {
+		Number      *hexutil.Uint64 `json:"number"`
+		BlockNumber *hexutil.Uint64 `json:"blockNumber"`
+		LogIndex    *hexutil.Uint64 `json:"logIndex"`
+		Removed     bool            `json:"removed"`
+	}
+	if err := json.Unmarshal(result, &event); err != nil || event.Removed {
+		return 0, 0, false
+	}
+	switch {
+	case event.Number != nil:
+		return uint64(*event.Number), 0, true
+	case event.BlockNumber != nil && event.LogIndex != nil:
+		return uint64(*event.BlockNumber), uint64(*event.LogIndex), true
+	}
+	return 0, 0, false
+}
+
+// track advances the position of the stream past a delivered notification.
+func (sub *ClientSubscription) track(result json.RawMessage) {
+	number, index, ok := eventPosition(result)
+	if !ok {
+		return
+	}
+	if sub.kind == "newHeads" {
+		sub.advance(number+1, 0)
+	} else {
+		sub.advance(number, index+1)
+	}
+}
+
+// advance moves the position of the stream forward to the given block and log
+// index, unless it's already past it.
+func (sub *ClientSubscription) advance(block, index uint64) {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+
+	if block > sub.nextBlock || (block == sub.nextBlock && index > sub.nextIndex) {
+		sub.nextBlock, sub.nextIndex = block, index
+	}
+}
+
+// pause starts holding back live notifications.
+func (sub *ClientSubscription) pause() {
+	sub.mu.Lock()
+	sub.paused = true
+	sub.mu.Unlock()
+}
+
+// resume delivers the notifications held back while paused, skipping the ones
+// of blocks up to head that were already backfilled, and then resumes delivering
+// live notifications.
+func (sub *ClientSubscription) resume(head uint64) {
+	for {
+		sub.mu.Lock()
+		held := sub.held
+		sub.held = nil
+		if len(held) == 0 {
+			sub.paused = false
+			sub.mu.Unlock()
+			return
+		}
+		sub.mu.Unlock()
+
+		for _, result := range held {
+			if number, _, ok := eventPosition(result); ok && head > 0 && number <= head {
+				continue
+			}
+			if !sub.push(result) {
+				return
+			}
+		}
+	}
+}
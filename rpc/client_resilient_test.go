@@ -0,0 +1,173 @@
+// Copyright 2018 The go-ruereum Authors
+// This file is part of the go-ruereum library.
+//
+// The go-ruereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ruereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ruereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Rue-Foundation/go-rue/common/hexutil"
+)
+
+// ChainTestService emulates the head subscription and retrieval methods of a
+// chain, producing blocks on demand.
+type ChainTestService struct {
+	mu   sync.Mutex
+	head uint64
+	subs map[*Subscription]*Notifier
+}
+
+type ChainHeader struct {
+	Number hexutil.Uint64 `json:"number"`
+}
+
+func (s *ChainTestService) NewHeads(ctx context.Context) (*Subscription, error) {
+	notifier, supported := NotifierFromContext(ctx)
+	if !supported {
+		return nil, ErrNotificationsUnsupported
+	}
+	sub := notifier.CreateSubscription()
+
+	s.mu.Lock()
+	s.subs[sub] = notifier
+	s.mu.Unlock()
+	return sub, nil
+}
+
+func (s *ChainTestService) GetBlockByNumber(number BlockNumber, full bool) *ChainHeader {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if number == LatestBlockNumber {
+		return &ChainHeader{Number: hexutil.Uint64(s.head)}
+	}
+	if number < 0 || uint64(number) > s.head {
+		return nil
+	}
+	return &ChainHeader{Number: hexutil.Uint64(number)}
+}
+
+// mine produces a new head block, notifying all subscribers.
+func (s *ChainTestService) mine() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.head++
+	for sub, notifier := range s.subs {
+		if err := notifier.Notify(sub.ID, &ChainHeader{Number: hexutil.Uint64(s.head)}); err != nil {
+			delete(s.subs, sub)
+		}
+	}
+}
+
+// Tests that a resilient client re-issues its subscriptions after the connection
+// drops, backfilling the heads produced while disconnected.
+func TestClientResilientSubscription(t *testing.T) {
+	service := &ChainTestService{subs: make(map[*Subscription]*Notifier)}
+	server := newTestServer("rue", service)
+	defer server.Stop()
+
+	// Connect through in-memory pipes that can be cut and refused at will
+	var (
+		lock sync.Mutex
+		down bool
+		conn net.Conn
+	)
+	dial := func(ctx context.Context) (net.Conn, error) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		if down {
+			return nil, errors.New("server down")
+		}
+		local, remote := net.Pipe()
+		go server.ServeCodec(NewJSONCodec(remote), OptionMethodInvocation|OptionSubscriptions)
+		conn = remote
+		return local, nil
+	}
+	client, err := newClient(context.Background(), dial)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	defer client.Close()
+	client.EnableResilience(10*time.Millisecond, 50*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	heads := make(chan ChainHeader)
+	sub, err := client.EthSubscribe(ctx, heads, "newHeads")
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	expect := func(from, to uint64) {
+		for number := from; number <= to; number++ {
+			select {
+			case head := <-heads:
+				if uint64(head.Number) != number {
+					t.Fatalf("head mismatch: have %d, want %d", head.Number, number)
+				}
+			case err := <-sub.Err():
+				t.Fatalf("subscription failed: %v", err)
+			case <-ctx.Done():
+				t.Fatalf("timeout waiting for head %d", number)
+			}
+		}
+	}
+	for i := 0; i < 3; i++ {
+		service.mine()
+	}
+	expect(1, 3)
+
+	// Cut the connection and produce blocks while the client can't reconnect
+	lock.Lock()
+	down = true
+	conn.Close()
+	lock.Unlock()
+
+	for i := 0; i < 3; i++ {
+		service.mine()
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	lock.Lock()
+	down = false
+	lock.Unlock()
+
+	// The missed heads must be backfilled, followed by the live ones
+	expect(4, 6)
+	for {
+		service.mine()
+		select {
+		case head := <-heads:
+			if head.Number < 7 {
+				t.Fatalf("duplicate head %d", head.Number)
+			}
+			return
+		case <-time.After(50 * time.Millisecond):
+			// Subscription might not be re-issued yet, mine another block
+		case <-ctx.Done():
+			t.Fatalf("timeout waiting for live heads")
+		}
+	}
+}
@@ -94,5 +94,15 @@ Subscriptions are deleted when:
  - the connection which was used to create the subscription is closed. This can be initiated
    by the client and server. The server will close the connection on an write error or when
    the queue of buffered notifications gets too big.
+
+Plain HTTP connections can't carry notifications, but HTTP clients may ask for their
+requests to be served as a stream of server-sent events by accepting text/event-stream.
+Responses and subscription notifications are then written as data events until the
+client closes the stream, which also ends its subscriptions. Requests are taken from
+the body of POST requests, GET requests are rejected.
+
+Every server offers the rpc_discover method, returning an OpenRPC document which
+describes the methods and subscriptions of all registered services. Parameter names
+and JSON schemas are derived from the Go types of the method signatures.
 */
 package rpc
@@ -64,3 +64,60 @@ type shutdownError struct{}
 func (e *shutdownError) ErrorCode() int { return -32000 }
 
 func (e *shutdownError) Error() string { return "server is shutting down" }
+
+// request lacks valid credentials for the invoked method
+type unauthorizedError struct{ message string }
+
+func (e *unauthorizedError) ErrorCode() int { return -32001 }
+
+func (e *unauthorizedError) Error() string { return "unauthorized: " + e.message }
+
+// authenticated caller is not allowed to invoke the method
+type forbiddenError struct{ method string }
+
+func (e *forbiddenError) ErrorCode() int { return -32002 }
+
+func (e *forbiddenError) Error() string {
+	return fmt.Sprintf("access to %s is not permitted", e.method)
+}
+
+// client exceeded its request rate allowance
+type rateLimitError struct{}
+
+func (e *rateLimitError) ErrorCode() int { return -32005 }
+
+func (e *rateLimitError) Error() string { return "request rate limit exceeded" }
+
+// batch request contains more calls than allowed
+type batchTooLargeError struct{ size, limit int }
+
+func (e *batchTooLargeError) ErrorCode() int { return -32006 }
+
+func (e *batchTooLargeError) Error() string {
+	return fmt.Sprintf("batch too large: %d requests, limit %d", e.size, e.limit)
+}
+
+// result of a call exceeds the maximum response size
+type responseTooLargeError struct{ size, limit int }
+
+func (e *responseTooLargeError) ErrorCode() int { return -32007 }
+
+func (e *responseTooLargeError) Error() string {
+	return fmt.Sprintf("response too large: %d bytes, limit %d", e.size, e.limit)
+}
+
+// call did not finish within its execution timeout
+type timeoutError struct{ method string }
+
+func (e *timeoutError) ErrorCode() int { return -32008 }
+
+func (e *timeoutError) Error() string { return fmt.Sprintf("%s timed out", e.method) }
+
+// connection reached its maximum number of concurrent subscriptions
+type subscriptionLimitError struct{ limit int }
+
+func (e *subscriptionLimitError) ErrorCode() int { return -32009 }
+
+func (e *subscriptionLimitError) Error() string {
+	return fmt.Sprintf("subscription limit reached: at most %d per connection", e.limit)
+}
@@ -27,6 +27,7 @@ import (
 	"mime"
 	"net"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
@@ -139,15 +140,27 @@ func (t *httpReadWriteNopCloser) Close() error {
 	return nil
 }
 
-// NewHTTPServer creates a new HTTP RPC server around an API provider.
+// NewHTTPServer creates a new HTTP RPC server around an API provider, accepting
+// requests only from the given origins and addressed to the given virtual hosts.
 //
 // Deprecated: Server implements http.Handler
-func NewHTTPServer(cors []string, srv *Server) *http.Server {
-	return &http.Server{Handler: newCorsHandler(srv, cors)}
+func NewHTTPServer(cors []string, vhosts []string, srv *Server) *http.Server {
+	return &http.Server{Handler: NewHTTPHandlerStack(srv, cors, vhosts)}
+}
+
+// NewHTTPHandlerStack wraps an arbitrary HTTP handler with the same origin and
+// virtual host checks the HTTP RPC server applies to its requests.
+func NewHTTPHandlerStack(srv http.Handler, cors []string, vhosts []string) http.Handler {
+	return newVHostHandler(vhosts, newCorsHandler(srv, cors))
 }
 
 // ServeHTTP serves JSON-RPC requests over HTTP.
 func (srv *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Serve subscriptions over server-sent events if the client asks for a stream
+	if isSSERequest(r) {
+		srv.serveSSE(w, r)
+		return
+	}
 	// Permit dumb empty requests for remote health-checks (AWS)
 	if r.Method == http.MethodGet && r.ContentLength == 0 && r.URL.RawQuery == "" {
 		return
@@ -163,7 +176,7 @@ func (srv *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	defer codec.Close()
 
 	w.Header().Set("content-type", contentType)
-	srv.ServeSingleRequest(codec, OptionMethodInvocation)
+	srv.serveRequest(srv.connContext(r), codec, true, OptionMethodInvocation)
 }
 
 // validateRequest returns a non-zero response code and error message if the
@@ -184,7 +197,7 @@ func validateRequest(r *http.Request) (int, error) {
 	return 0, nil
 }
 
-func newCorsHandler(srv *Server, allowedOrigins []string) http.Handler {
+func newCorsHandler(srv http.Handler, allowedOrigins []string) http.Handler {
 	// disable CORS support if user has not specified a custom CORS configuration
 	if len(allowedOrigins) == 0 {
 		return srv
@@ -198,3 +211,46 @@ func newCorsHandler(srv *Server, allowedOrigins []string) http.Handler {
 	})
 	return c.Handler(srv)
 }
+
+// virtualHostHandler is a handler which validates the Host header of incoming
+// requests, preventing DNS rebinding attacks against locally bound endpoints.
+// Requests addressed to an IP address are always accepted, since a rebinding
+// attack needs a domain name to work with.
+type virtualHostHandler struct {
+	vhosts map[string]struct{}
+	next   http.Handler
+}
+
+// ServeHTTP implements http.Handler, rejecting requests to unknown virtual hosts.
+func (h *virtualHostHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Requests without a Host header can't originate from a browser
+	if r.Host == "" {
+		h.next.ServeHTTP(w, r)
+		return
+	}
+	host, _, err := net.SplitHostPort(r.Host)
+	if err != nil {
+		host = r.Host // no port specified (or malformed, rejected below)
+	}
+	if net.ParseIP(host) != nil {
+		h.next.ServeHTTP(w, r)
+		return
+	}
+	if _, ok := h.vhosts["*"]; ok {
+		h.next.ServeHTTP(w, r)
+		return
+	}
+	if _, ok := h.vhosts[strings.ToLower(host)]; ok {
+		h.next.ServeHTTP(w, r)
+		return
+	}
+	http.Error(w, fmt.Sprintf("invalid host specified: %s is not an allowed virtual host", host), http.StatusForbidden)
+}
+
+func newVHostHandler(vhosts []string, next http.Handler) http.Handler {
+	vhostMap := make(map[string]struct{})
+	for _, allowedHost := range vhosts {
+		vhostMap[strings.ToLower(allowedHost)] = struct{}{}
+	}
+	return &virtualHostHandler{vhostMap, next}
+}
@@ -52,3 +52,40 @@ func testHTTPErrorResponse(t *testing.T, method, contentType, body string, expec
 		t.Fatalf("response code should be %d not %d", expected, code)
 	}
 }
+
+func TestHTTPVirtualHosts(t *testing.T) {
+	handler := newVHostHandler([]string{"localhost", "Node.Example.com"}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	tests := []struct {
+		host     string
+		expected int
+	}{
+		{"localhost", http.StatusOK},
+		{"localhost:8545", http.StatusOK},
+		{"node.example.com:8545", http.StatusOK},
+		{"127.0.0.1:8545", http.StatusOK},
+		{"[::1]:8545", http.StatusOK},
+		{"rebind.attacker.com", http.StatusForbidden},
+		{"rebind.attacker.com:8545", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		request := httptest.NewRequest(http.MethodPost, "http://url.com", nil)
+		request.Host = tt.host
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		if recorder.Code != tt.expected {
+			t.Errorf("host %s: response code should be %d not %d", tt.host, tt.expected, recorder.Code)
+		}
+	}
+	// Wildcards must accept any host
+	handler = newVHostHandler([]string{"*"}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	request := httptest.NewRequest(http.MethodPost, "http://url.com", nil)
+	request.Host = "rebind.attacker.com"
+
+	recorder := httptest.NewRecorder()
+	if handler.ServeHTTP(recorder, request); recorder.Code != http.StatusOK {
+		t.Errorf("wildcard: response code should be %d not %d", http.StatusOK, recorder.Code)
+	}
+}
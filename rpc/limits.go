@@ -0,0 +1,213 @@
+// Copyright 2018 The go-ruereum Authors
+// This file is part of the go-ruereum library.
+//
+// The go-ruereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ruereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ruereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// maxRateBuckets is the number of client buckets a rate limiter tracks before
+// dropping the ones that have been idle long enough to be fully refilled.
+const maxRateBuckets = 4096
+
+// Limits are the resource quotas a Server enforces on the requests it serves.
+// Zero values disable the respective limit.
+//
+// Violations are reported with the following JSON-RPC error codes:
+//
+//	-32005  request rate exceeded
+//	-32006  batch too large
+//	-32007  response too large
+//	-32008  execution timed out
+//	-32009  subscription limit reached
+type Limits struct {
+	RequestRate   float64 `toml:",omitempty"` // Requests per second allowed per client (remote address or token identity)
+	RequestBurst  int     `toml:",omitempty"` // Requests a client may make at once after idling, defaults to the rate
+	BatchSize     int     `toml:",omitempty"` // Maximum number of requests in a single batch
+	ResponseSize  int     `toml:",omitempty"` // Maximum size of the result of a single call in bytes
+	Subscriptions int     `toml:",omitempty"` // Maximum number of concurrent subscriptions per connection
+
+	// Timeout is the maximum execution time of a call, overridden per method
+	// (e.g. "debug_traceTransaction") by MethodTimeouts. Timeouts are enforced
+	// through the context of the call, so they only apply to methods accepting
+	// one.
+	Timeout        time.Duration            `toml:",omitempty"`
+	MethodTimeouts map[string]time.Duration `toml:",omitempty"`
+}
+
+// timeout returns the execution timeout of the given method, zero if unlimited.
+func (l *Limits) timeout(method string) time.Duration {
+	if timeout, ok := l.MethodTimeouts[method]; ok {
+		return timeout
+	}
+	return l.Timeout
+}
+
+// rateBucket is the token bucket of a single client.
+type rateBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// rateLimiter is a token bucket rate limiter tracking each client separately.
+type rateLimiter struct {
+	rate  float64 // Tokens refilled per second
+	burst float64 // Capacity of each bucket
+
+	buckets map[string]*rateBucket
+	lock    sync.Mutex
+}
+
+// newRateLimiter creates a rate limiter allowing rate requests per second with
+// the given burst capacity, defaulting to the rate if not positive.
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	capacity := float64(burst)
+	if capacity <= 0 {
+		capacity = rate
+	}
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &rateLimiter{
+		rate:    rate,
+		burst:   capacity,
+		buckets: make(map[string]*rateBucket),
+	}
+}
+
+// allow consumes a token from the bucket of the given client, returning whether
+// there was one available.
+func (l *rateLimiter) allow(client string, now time.Time) bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	bucket := l.buckets[client]
+	if bucket == nil {
+		if len(l.buckets) >= maxRateBuckets {
+			l.prune(now)
+		}
+		bucket = &rateBucket{tokens: l.burst, last: now}
+		l.buckets[client] = bucket
+	}
+	bucket.tokens += now.Sub(bucket.last).Seconds() * l.rate
+	if bucket.tokens > l.burst {
+		bucket.tokens = l.burst
+	}
+	bucket.last = now
+
+	if bucket.tokens < 1 {
+		return false
+	}
+	bucket.tokens--
+	return true
+}
+
+// prune drops the buckets which would be full by now, as tracking them makes no
+// difference compared to starting afresh.
+func (l *rateLimiter) prune(now time.Time) {
+	for client, bucket := range l.buckets {
+		if bucket.tokens+now.Sub(bucket.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, client)
+		}
+	}
+}
+
+// clientKey is the context key of the remote address of a connection.
+type clientKey struct{}
+
+// client returns the identity rate limits are accounted to: the subject of the
+// bearer token if authenticated, the remote host otherwise. Connections without
+// a remote address, such as IPC and in-process ones, are not limited.
+func client(ctx context.Context) (string, bool) {
+	if info, ok := ctx.Value(authKey{}).(*authInfo); ok && info.identity != "" {
+		return "id:" + info.identity, true
+	}
+	if remote, ok := ctx.Value(clientKey{}).(string); ok {
+		return "ip:" + remote, true
+	}
+	return "", false
+}
+
+// connContext returns the context to serve the requests of an HTTP or websocket
+// connection with, carrying the address of the remote client and its
+// authentication info if access control is enabled.
+func (s *Server) connContext(r *http.Request) context.Context {
+	remote := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(remote); err == nil {
+		remote = host
+	}
+	ctx := context.WithValue(context.Background(), clientKey{}, remote)
+	if s.auth != nil {
+		ctx = s.auth.authenticate(ctx, r)
+	}
+	return ctx
+}
+
+// checkRate consumes a request from the rate allowance of the caller, returning
+// an error if it ran out.
+func (s *Server) checkRate(ctx context.Context) Error {
+	if s.limiter == nil {
+		return nil
+	}
+	if id, ok := client(ctx); ok && !s.limiter.allow(id, time.Now()) {
+		return &rateLimitError{}
+	}
+	return nil
+}
+
+// checkSubscriptions returns an error if the connection of ctx already reached
+// its limit of concurrent subscriptions.
+func (s *Server) checkSubscriptions(ctx context.Context) Error {
+	if s.limits.Subscriptions <= 0 {
+		return nil
+	}
+	if notifier, ok := NotifierFromContext(ctx); ok && notifier.count() >= s.limits.Subscriptions {
+		return &subscriptionLimitError{s.limits.Subscriptions}
+	}
+	return nil
+}
+
+// createResponse creates the response to a successful call, replacing it with an
+// error if its result exceeds the response size limit.
+func (s *Server) createResponse(codec ServerCodec, id interface{}, result interface{}) interface{} {
+	if s.limits.ResponseSize <= 0 {
+		return codec.CreateResponse(id, result)
+	}
+	blob, err := json.Marshal(result)
+	if err != nil {
+		return codec.CreateErrorResponse(&id, &callbackError{err.Error()})
+	}
+	if len(blob) > s.limits.ResponseSize {
+		return codec.CreateErrorResponse(&id, &responseTooLargeError{len(blob), s.limits.ResponseSize})
+	}
+	return codec.CreateResponse(id, json.RawMessage(blob))
+}
+
+// SetLimits configures the resource quotas enforced on the served requests. It
+// must be called before serving any.
+func (s *Server) SetLimits(limits Limits) {
+	s.limits = limits
+	s.limiter = nil
+	if limits.RequestRate > 0 {
+		s.limiter = newRateLimiter(limits.RequestRate, limits.RequestBurst)
+	}
+}
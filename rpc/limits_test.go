@@ -0,0 +1,156 @@
+// Copyright 2018 The go-ruereum Authors
+// This file is part of the go-ruereum library.
+//
+// The go-ruereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ruereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ruereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type LimitService struct{}
+
+func (s *LimitService) Blob(size int) string {
+	return strings.Repeat("x", size)
+}
+
+func (s *LimitService) Wait(ctx context.Context, duration time.Duration) error {
+	select {
+	case <-time.After(duration):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// limitTestCall sends a raw request to an HTTP RPC server from the given remote
+// address, returning the error codes of the responses.
+func limitTestCall(t *testing.T, server *Server, remote, body string) []int {
+	request := httptest.NewRequest("POST", "http://url.com", strings.NewReader(body))
+	request.Header.Set("content-type", contentType)
+	request.RemoteAddr = remote
+
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, request)
+
+	var responses []jsonErrResponse
+	if strings.HasPrefix(body, "[") {
+		if err := json.Unmarshal(recorder.Body.Bytes(), &responses); err != nil {
+			t.Fatalf("failed to decode batch response %s: %v", recorder.Body.String(), err)
+		}
+	} else {
+		responses = make([]jsonErrResponse, 1)
+		if err := json.Unmarshal(recorder.Body.Bytes(), &responses[0]); err != nil {
+			t.Fatalf("failed to decode response %s: %v", recorder.Body.String(), err)
+		}
+	}
+	codes := make([]int, len(responses))
+	for i, response := range responses {
+		codes[i] = response.Error.Code
+	}
+	return codes
+}
+
+// Tests that the requests of each client are rate limited separately.
+func TestRateLimits(t *testing.T) {
+	server := NewServer()
+	defer server.Stop()
+
+	if err := server.RegisterName("test", new(LimitService)); err != nil {
+		t.Fatal(err)
+	}
+	server.SetLimits(Limits{RequestRate: 0.001, RequestBurst: 2})
+
+	call := `{"jsonrpc":"2.0","id":1,"method":"test_blob","params":[1]}`
+	for i, want := range []int{0, 0, -32005} {
+		if code := limitTestCall(t, server, "10.0.0.1:1000", call)[0]; code != want {
+			t.Errorf("request %d: error code mismatch: have %d, want %d", i, code, want)
+		}
+	}
+	// Other clients must not be affected, not even when sending a batch
+	batch := "[" + call + "," + call + "," + call + "]"
+	codes := limitTestCall(t, server, "10.0.0.2:1000", batch)
+	if len(codes) != 3 || codes[0] != 0 || codes[1] != 0 || codes[2] != -32005 {
+		t.Errorf("batch error codes mismatch: have %v, want [0 0 -32005]", codes)
+	}
+}
+
+// Tests that oversized batches and responses, and slow calls are rejected.
+func TestRequestLimits(t *testing.T) {
+	server := NewServer()
+	defer server.Stop()
+
+	if err := server.RegisterName("test", new(LimitService)); err != nil {
+		t.Fatal(err)
+	}
+	server.SetLimits(Limits{
+		BatchSize:      2,
+		ResponseSize:   64,
+		Timeout:        time.Second,
+		MethodTimeouts: map[string]time.Duration{"test_wait": 10 * time.Millisecond},
+	})
+	blob := func(size int) string {
+		return `{"jsonrpc":"2.0","id":1,"method":"test_blob","params":[` + strconv.Itoa(size) + `]}`
+	}
+	tests := []struct {
+		body  string
+		codes []int
+	}{
+		{"[" + blob(1) + "," + blob(1) + "]", []int{0, 0}},
+		{"[" + blob(1) + "," + blob(1) + "," + blob(1) + "]", []int{-32006, -32006, -32006}},
+		{blob(62), []int{0}},
+		{blob(63), []int{-32007}},
+		{`{"jsonrpc":"2.0","id":1,"method":"test_wait","params":[1000000]}`, []int{0}},
+		{`{"jsonrpc":"2.0","id":1,"method":"test_wait","params":[1000000000]}`, []int{-32008}},
+	}
+	for i, tt := range tests {
+		codes := limitTestCall(t, server, "10.0.0.1:1000", tt.body)
+		if len(codes) != len(tt.codes) {
+			t.Errorf("test %d: response count mismatch: have %d, want %d", i, len(codes), len(tt.codes))
+			continue
+		}
+		for j := range codes {
+			if codes[j] != tt.codes[j] {
+				t.Errorf("test %d: error codes mismatch: have %v, want %v", i, codes, tt.codes)
+				break
+			}
+		}
+	}
+}
+
+// Tests that the number of concurrent subscriptions of a connection is limited.
+func TestSubscriptionLimits(t *testing.T) {
+	server := NewServer()
+	server.SetLimits(Limits{Subscriptions: 2})
+
+	notifier := newNotifier(nil)
+	ctx := context.WithValue(context.Background(), notifierKey{}, notifier)
+
+	for i := 0; i < 2; i++ {
+		if err := server.checkSubscriptions(ctx); err != nil {
+			t.Fatalf("subscription %d rejected: %v", i, err)
+		}
+		notifier.CreateSubscription()
+	}
+	if err := server.checkSubscriptions(ctx); err == nil || err.ErrorCode() != -32009 {
+		t.Fatalf("subscription limit not enforced: %v", err)
+	}
+}
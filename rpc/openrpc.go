@@ -0,0 +1,312 @@
+// Copyright 2018 The go-ruereum Authors
+// This file is part of the go-ruereum library.
+//
+// The go-ruereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ruereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ruereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"encoding"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/Rue-Foundation/go-rue/common/hexutil"
+)
+
+// openRPCVersion is the version of the OpenRPC specification the discovery
+// document produced by rpc_discover conforms to.
+const openRPCVersion = "1.2.6"
+
+// OpenRPCDocument is a service description in the OpenRPC format, listing all
+// the methods and subscriptions served by an RPC server.
+type OpenRPCDocument struct {
+	OpenRPC    string                 `json:"openrpc"`
+	Info       OpenRPCInfo            `json:"info"`
+	Methods    []*OpenRPCMethod       `json:"methods"`
+	Components map[string]interface{} `json:"components"`
+}
+
+// OpenRPCInfo contains metadata about the described API.
+type OpenRPCInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// OpenRPCMethod describes a single RPC method or subscription.
+type OpenRPCMethod struct {
+	Name         string                      `json:"name"`
+	Summary      string                      `json:"summary,omitempty"`
+	Params       []*OpenRPCContentDescriptor `json:"params"`
+	Result       *OpenRPCContentDescriptor   `json:"result"`
+	Subscription bool                        `json:"x-subscription,omitempty"` // Method is a subscription created via <namespace>_subscribe
+}
+
+// OpenRPCContentDescriptor describes a parameter or the result of a method.
+type OpenRPCContentDescriptor struct {
+	Name     string      `json:"name"`
+	Required bool        `json:"required,omitempty"`
+	Schema   interface{} `json:"schema"`
+}
+
+// jsonSchema is a JSON schema definition of a value.
+type jsonSchema map[string]interface{}
+
+var (
+	hexQuantitySchema = jsonSchema{"type": "string", "pattern": "^0x(0|[1-9a-fA-F][0-9a-fA-F]*)$"}
+	hexBytesSchema    = jsonSchema{"type": "string", "pattern": "^0x([0-9a-fA-F]{2})*$"}
+	blockNumberSchema = jsonSchema{"oneOf": []interface{}{
+		hexQuantitySchema,
+		jsonSchema{"type": "string", "enum": []string{"earliest", "latest", "pending"}},
+	}}
+	subscriptionIDSchema = jsonSchema{"type": "string"}
+)
+
+// knownSchemas are the JSON schemas of types whose encoding can't be derived
+// from their Go definition.
+var knownSchemas = map[reflect.Type]jsonSchema{
+	reflect.TypeOf(big.Int{}):         hexQuantitySchema,
+	reflect.TypeOf(hexutil.Big{}):     hexQuantitySchema,
+	reflect.TypeOf(hexutil.Uint64(0)): hexQuantitySchema,
+	reflect.TypeOf(hexutil.Uint(0)):   hexQuantitySchema,
+	reflect.TypeOf(hexutil.Bytes{}):   hexBytesSchema,
+	reflect.TypeOf(BlockNumber(0)):    blockNumberSchema,
+	reflect.TypeOf(ID("")):            subscriptionIDSchema,
+}
+
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+// Discover returns an OpenRPC document describing all the methods and
+// subscriptions of the services registered on the server.
+//
+// Go doesn't retain parameter names at runtime, so parameters are named after
+// their types. Optional (pointer) parameters are marked as not required.
+func (s *RPCService) Discover() *OpenRPCDocument {
+	builder := &schemaBuilder{
+		defs:  make(map[string]interface{}),
+		names: make(map[reflect.Type]string),
+	}
+	doc := &OpenRPCDocument{
+		OpenRPC: openRPCVersion,
+		Info:    OpenRPCInfo{Title: "Rue JSON-RPC API", Version: "1.0.0"},
+		Methods: []*OpenRPCMethod{},
+	}
+	for name, service := range s.server.services {
+		for mname, cb := range service.callbacks {
+			doc.Methods = append(doc.Methods, &OpenRPCMethod{
+				Name:   name + serviceMethodSeparator + mname,
+				Params: builder.params(cb.argTypes),
+				Result: builder.result(cb),
+			})
+		}
+		for sname, cb := range service.subscriptions {
+			doc.Methods = append(doc.Methods, &OpenRPCMethod{
+				Name:         name + serviceMethodSeparator + sname,
+				Summary:      fmt.Sprintf("Subscription created by %s%s with %q as its first parameter", name, subscribeMethodSuffix, sname),
+				Params:       builder.params(cb.argTypes),
+				Result:       &OpenRPCContentDescriptor{Name: "subscriptionID", Schema: subscriptionIDSchema},
+				Subscription: true,
+			})
+		}
+		if len(service.subscriptions) > 0 {
+			doc.Methods = append(doc.Methods, &OpenRPCMethod{
+				Name:    name + unsubscribeMethodSuffix,
+				Summary: "Cancels a subscription",
+				Params:  []*OpenRPCContentDescriptor{{Name: "subscriptionID", Required: true, Schema: subscriptionIDSchema}},
+				Result:  &OpenRPCContentDescriptor{Name: "result", Schema: jsonSchema{"type": "boolean"}},
+			})
+		}
+	}
+	sort.Sort(openRPCMethodsByName(doc.Methods))
+
+	doc.Components = map[string]interface{}{"schemas": builder.defs}
+	return doc
+}
+
+// openRPCMethodsByName implements sort.Interface to order methods by name.
+type openRPCMethodsByName []*OpenRPCMethod
+
+func (m openRPCMethodsByName) Len() int           { return len(m) }
+func (m openRPCMethodsByName) Less(i, j int) bool { return m[i].Name < m[j].Name }
+func (m openRPCMethodsByName) Swap(i, j int)      { m[i], m[j] = m[j], m[i] }
+
+// schemaBuilder derives JSON schemas from Go types, collecting named struct
+// types into reusable component definitions.
+type schemaBuilder struct {
+	defs  map[string]interface{}  // Component schemas of named struct types
+	names map[reflect.Type]string // Component names of already visited struct types
+}
+
+// params creates the content descriptors of a method's arguments.
+func (b *schemaBuilder) params(types []reflect.Type) []*OpenRPCContentDescriptor {
+	var (
+		params = make([]*OpenRPCContentDescriptor, len(types))
+		seen   = make(map[string]int)
+	)
+	for i, typ := range types {
+		name := paramName(typ)
+		if seen[name]++; seen[name] > 1 {
+			name = fmt.Sprintf("%s%d", name, seen[name])
+		}
+		params[i] = &OpenRPCContentDescriptor{
+			Name:     name,
+			Required: typ.Kind() != reflect.Ptr,
+			Schema:   b.schema(typ),
+		}
+	}
+	return params
+}
+
+// result creates the content descriptor of a method's return value.
+func (b *schemaBuilder) result(cb *callback) *OpenRPCContentDescriptor {
+	mtype := cb.method.Type
+	for i := 0; i < mtype.NumOut(); i++ {
+		if i != cb.errPos {
+			return &OpenRPCContentDescriptor{Name: "result", Schema: b.schema(mtype.Out(i))}
+		}
+	}
+	return &OpenRPCContentDescriptor{Name: "result", Schema: jsonSchema{"type": "null"}}
+}
+
+// schema returns the JSON schema of values of the given type.
+func (b *schemaBuilder) schema(typ reflect.Type) jsonSchema {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if schema, ok := knownSchemas[typ]; ok {
+		return schema
+	}
+	// Types with custom text encodings are strings, fixed size byte arrays (e.g.
+	// addresses and hashes) are hex encoded by convention
+	if typ.Implements(textMarshalerType) || reflect.PtrTo(typ).Implements(textMarshalerType) {
+		if typ.Kind() == reflect.Array && typ.Elem().Kind() == reflect.Uint8 {
+			return jsonSchema{"type": "string", "pattern": fmt.Sprintf("^0x[0-9a-fA-F]{%d}$", 2*typ.Len())}
+		}
+		return jsonSchema{"type": "string"}
+	}
+	switch typ.Kind() {
+	case reflect.Bool:
+		return jsonSchema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return jsonSchema{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return jsonSchema{"type": "number"}
+	case reflect.String:
+		return jsonSchema{"type": "string"}
+	case reflect.Slice:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return jsonSchema{"type": "string", "contentEncoding": "base64"}
+		}
+		return jsonSchema{"type": "array", "items": b.schema(typ.Elem())}
+	case reflect.Array:
+		return jsonSchema{"type": "array", "items": b.schema(typ.Elem()), "minItems": typ.Len(), "maxItems": typ.Len()}
+	case reflect.Map:
+		return jsonSchema{"type": "object", "additionalProperties": b.schema(typ.Elem())}
+	case reflect.Struct:
+		if typ.Name() == "" {
+			return b.structSchema(typ)
+		}
+		return b.ref(typ)
+	}
+	// Interfaces and anything else may hold arbitrary values
+	return jsonSchema{}
+}
+
+// ref returns a reference to the component schema of a named struct type,
+// defining it on first use.
+func (b *schemaBuilder) ref(typ reflect.Type) jsonSchema {
+	name, ok := b.names[typ]
+	if !ok {
+		name = typ.String()
+		for i := 2; b.defs[name] != nil; i++ {
+			name = fmt.Sprintf("%s%d", typ.String(), i)
+		}
+		// Reserve the name before descending to support recursive types
+		b.names[typ], b.defs[name] = name, jsonSchema{}
+		b.defs[name] = b.structSchema(typ)
+	}
+	return jsonSchema{"$ref": "#/components/schemas/" + name}
+}
+
+// structSchema returns the object schema of a struct type, following the field
+// naming rules of encoding/json.
+func (b *schemaBuilder) structSchema(typ reflect.Type) jsonSchema {
+	props := make(map[string]interface{})
+	b.addFields(typ, props)
+	return jsonSchema{"type": "object", "properties": props}
+}
+
+// addFields collects the JSON encoded fields of a struct type into props,
+// flattening untagged embedded structs.
+func (b *schemaBuilder) addFields(typ reflect.Type, props map[string]interface{}) {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if field.Anonymous && name == "" {
+			ftyp := field.Type
+			if ftyp.Kind() == reflect.Ptr {
+				ftyp = ftyp.Elem()
+			}
+			if ftyp.Kind() == reflect.Struct {
+				b.addFields(ftyp, props)
+				continue
+			}
+		}
+		if field.PkgPath != "" { // unexported field
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if _, exists := props[name]; !exists {
+			props[name] = b.schema(field.Type)
+		}
+	}
+}
+
+// paramName derives a parameter name from its type, e.g. blockNumber for an
+// rpc.BlockNumber or addresses for a []common.Address.
+func paramName(typ reflect.Type) string {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if name := typ.Name(); name != "" {
+		runes := []rune(name)
+		runes[0] = unicode.ToLower(runes[0])
+		return string(runes)
+	}
+	switch typ.Kind() {
+	case reflect.Slice, reflect.Array:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return "data"
+		}
+		name := paramName(typ.Elem())
+		if strings.HasSuffix(name, "s") {
+			return name + "es"
+		}
+		return name + "s"
+	case reflect.Map:
+		return paramName(typ.Elem()) + "Map"
+	}
+	return typ.Kind().String()
+}
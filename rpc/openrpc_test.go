@@ -0,0 +1,149 @@
+// Copyright 2018 The go-ruereum Authors
+// This file is part of the go-ruereum library.
+//
+// The go-ruereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ruereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ruereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/Rue-Foundation/go-rue/common/hexutil"
+)
+
+type DiscoverAddress [4]byte
+
+func (a DiscoverAddress) MarshalText() ([]byte, error) { return hexutil.Bytes(a[:]).MarshalText() }
+
+type DiscoverArgs struct {
+	From  DiscoverAddress `json:"from"`
+	Value *hexutil.Big    `json:"value"`
+	Next  *DiscoverArgs   `json:"next,omitempty"`
+	Skip  string          `json:"-"`
+	Tags  []string
+}
+
+type DiscoverService struct{}
+
+func (s *DiscoverService) Balance(addr DiscoverAddress, number BlockNumber) (*hexutil.Big, error) {
+	return nil, nil
+}
+
+func (s *DiscoverService) Call(ctx context.Context, args DiscoverArgs, number *BlockNumber) {}
+
+func (s *DiscoverService) Transfer(from, to DiscoverAddress) error { return nil }
+
+func (s *DiscoverService) Updates(ctx context.Context, filter *DiscoverArgs) (*Subscription, error) {
+	return nil, nil
+}
+
+// Tests that the OpenRPC discovery document lists all registered methods and
+// subscriptions with schemas derived from their Go types.
+func TestDiscover(t *testing.T) {
+	server := NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("test", new(DiscoverService)); err != nil {
+		t.Fatalf("failed to register service: %v", err)
+	}
+	client := DialInProc(server)
+	defer client.Close()
+
+	var doc struct {
+		OpenRPC string `json:"openrpc"`
+		Methods []struct {
+			Name   string `json:"name"`
+			Params []struct {
+				Name     string          `json:"name"`
+				Required bool            `json:"required"`
+				Schema   json.RawMessage `json:"schema"`
+			} `json:"params"`
+			Result struct {
+				Schema json.RawMessage `json:"schema"`
+			} `json:"result"`
+			Subscription bool `json:"x-subscription"`
+		} `json:"methods"`
+		Components struct {
+			Schemas map[string]json.RawMessage `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := client.Call(&doc, "rpc_discover"); err != nil {
+		t.Fatalf("failed to retrieve discovery document: %v", err)
+	}
+	if doc.OpenRPC != openRPCVersion {
+		t.Errorf("version mismatch: have %s, want %s", doc.OpenRPC, openRPCVersion)
+	}
+	want := []string{"rpc_discover", "rpc_modules", "test_balance", "test_call", "test_transfer", "test_unsubscribe", "test_updates"}
+	if len(doc.Methods) != len(want) {
+		t.Fatalf("method count mismatch: have %d, want %d", len(doc.Methods), len(want))
+	}
+	for i, method := range doc.Methods {
+		if method.Name != want[i] {
+			t.Errorf("method %d: name mismatch: have %s, want %s", i, method.Name, want[i])
+		}
+	}
+	// Check the parameter names, optionality and schemas
+	balance, call, transfer, updates := doc.Methods[2], doc.Methods[3], doc.Methods[4], doc.Methods[6]
+	if p := balance.Params; len(p) != 2 || p[0].Name != "discoverAddress" || p[1].Name != "blockNumber" || !p[0].Required || !p[1].Required {
+		t.Errorf("balance params mismatch: %+v", p)
+	}
+	if have, want := string(balance.Params[0].Schema), `{"pattern":"^0x[0-9a-fA-F]{8}$","type":"string"}`; have != want {
+		t.Errorf("address schema mismatch: have %s, want %s", have, want)
+	}
+	if have := string(balance.Params[1].Schema); have != mustJSON(blockNumberSchema) {
+		t.Errorf("block number schema mismatch: have %s", have)
+	}
+	if have := string(balance.Result.Schema); have != mustJSON(hexQuantitySchema) {
+		t.Errorf("balance result schema mismatch: have %s", have)
+	}
+	if p := call.Params; len(p) != 2 || p[0].Name != "discoverArgs" || !p[0].Required || p[1].Required {
+		t.Errorf("call params mismatch: %+v", p)
+	}
+	if have, want := string(call.Result.Schema), `{"type":"null"}`; have != want {
+		t.Errorf("call result schema mismatch: have %s, want %s", have, want)
+	}
+	if p := transfer.Params; len(p) != 2 || p[0].Name != "discoverAddress" || p[1].Name != "discoverAddress2" {
+		t.Errorf("transfer params mismatch: %+v", p)
+	}
+	if !updates.Subscription || len(updates.Params) != 1 || updates.Params[0].Required {
+		t.Errorf("subscription mismatch: %+v", updates)
+	}
+	// Check that struct types are referenced from the components section
+	if have, want := string(call.Params[0].Schema), `{"$ref":"#/components/schemas/rpc.DiscoverArgs"}`; have != want {
+		t.Errorf("struct reference mismatch: have %s, want %s", have, want)
+	}
+	var args struct {
+		Properties map[string]json.RawMessage `json:"properties"`
+	}
+	if err := json.Unmarshal(doc.Components.Schemas["rpc.DiscoverArgs"], &args); err != nil {
+		t.Fatalf("failed to decode struct schema: %v", err)
+	}
+	for _, field := range []string{"from", "value", "next", "Tags"} {
+		if _, ok := args.Properties[field]; !ok {
+			t.Errorf("struct schema missing field %s", field)
+		}
+	}
+	if len(args.Properties) != 4 {
+		t.Errorf("struct schema field count mismatch: have %d, want %d", len(args.Properties), 4)
+	}
+}
+
+func mustJSON(v interface{}) string {
+	blob, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return string(blob)
+}
@@ -125,7 +125,7 @@ func (s *Server) RegisterName(name string, rcvr interface{}) error {
 // If singleShot is true it will process a single request, otherwise it will handle
 // requests until the codec returns an error when reading a request (in most cases
 // an EOF). It executes requests in parallel when singleShot is false.
-func (s *Server) serveRequest(codec ServerCodec, singleShot bool, options CodecOption) error {
+func (s *Server) serveRequest(ctx context.Context, codec ServerCodec, singleShot bool, options CodecOption) error {
 	var pend sync.WaitGroup
 
 	defer func() {
@@ -140,7 +140,7 @@ func (s *Server) serveRequest(codec ServerCodec, singleShot bool, options CodecO
 		s.codecsMu.Unlock()
 	}()
 
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
 	// if the codec supports notification include a notifier that callbacks can use
@@ -159,7 +159,7 @@ func (s *Server) serveRequest(codec ServerCodec, singleShot bool, options CodecO
 
 	// test if the server is ordered to stop
 	for atomic.LoadInt32(&s.run) == 1 {
-		reqs, batch, err := s.readRequest(codec)
+		reqs, batch, err := s.readRequest(ctx, codec)
 		if err != nil {
 			// If a parsing error occurred, send an error
 			if err.Error() != "EOF" {
@@ -215,14 +215,14 @@ func (s *Server) serveRequest(codec ServerCodec, singleShot bool, options CodecO
 // stopped. In either case the codec is closed.
 func (s *Server) ServeCodec(codec ServerCodec, options CodecOption) {
 	defer codec.Close()
-	s.serveRequest(codec, false, options)
+	s.serveRequest(context.Background(), codec, false, options)
 }
 
 // ServeSingleRequest reads and processes a single RPC request from the given codec. It will not
 // close the codec unless a non-recoverable error has occurred. Note, this method will return after
 // a single request has been processed!
 func (s *Server) ServeSingleRequest(codec ServerCodec, options CodecOption) {
-	s.serveRequest(codec, true, options)
+	s.serveRequest(context.Background(), codec, true, options)
 }
 
 // Stop will stop reading new requests, wait for stopPendingRequestTimeout to allow pending requests to finish,
@@ -277,6 +277,9 @@ func (s *Server) handle(ctx context.Context, codec ServerCodec, req *serverReque
 	}
 
 	if req.callb.isSubscribe {
+		if err := s.checkSubscriptions(ctx); err != nil {
+			return codec.CreateErrorResponse(&req.id, err), nil
+		}
 		subid, err := s.createSubscription(ctx, codec, req)
 		if err != nil {
 			return codec.CreateErrorResponse(&req.id, &callbackError{err.Error()}), nil
@@ -299,8 +302,15 @@ func (s *Server) handle(ctx context.Context, codec ServerCodec, req *serverReque
 		return codec.CreateErrorResponse(&req.id, rpcErr), nil
 	}
 
+	name := req.svcname + serviceMethodSeparator + formatName(req.callb.method.Name)
+
 	arguments := []reflect.Value{req.callb.rcvr}
 	if req.callb.hasCtx {
+		if timeout := s.limits.timeout(name); timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
 		arguments = append(arguments, reflect.ValueOf(ctx))
 	}
 	if len(req.args) > 0 {
@@ -316,11 +326,14 @@ func (s *Server) handle(ctx context.Context, codec ServerCodec, req *serverReque
 	if req.callb.errPos >= 0 { // test if method returned an error
 		if !reply[req.callb.errPos].IsNil() {
 			e := reply[req.callb.errPos].Interface().(error)
+			if ctx.Err() == context.DeadlineExceeded {
+				return codec.CreateErrorResponse(&req.id, &timeoutError{name}), nil
+			}
 			res := codec.CreateErrorResponse(&req.id, &callbackError{e.Error()})
 			return res, nil
 		}
 	}
-	return codec.CreateResponse(req.id, reply[0].Interface()), nil
+	return s.createResponse(codec, req.id, reply[0].Interface()), nil
 }
 
 // exec executes the given request and writes the result back using the codec.
@@ -373,8 +386,9 @@ func (s *Server) execBatch(ctx context.Context, codec ServerCodec, requests []*s
 
 // readRequest requests the next (batch) request from the codec. It will return the collection
 // of requests, an indication if the request was a batch, the invalid request identifier and an
-// error when the request could not be read/parsed.
-func (s *Server) readRequest(codec ServerCodec) ([]*serverRequest, bool, Error) {
+// error when the request could not be read/parsed. Requests the caller is not allowed to make
+// are marked as failed.
+func (s *Server) readRequest(ctx context.Context, codec ServerCodec) ([]*serverRequest, bool, Error) {
 	reqs, batch, err := codec.ReadRequestHeaders()
 	if err != nil {
 		return nil, batch, err
@@ -382,6 +396,15 @@ func (s *Server) readRequest(codec ServerCodec) ([]*serverRequest, bool, Error)
 
 	requests := make([]*serverRequest, len(reqs))
 
+	// reject batches exceeding the size limit as a whole
+	if batch && s.limits.BatchSize > 0 && len(reqs) > s.limits.BatchSize {
+		err := &batchTooLargeError{len(reqs), s.limits.BatchSize}
+		for i, r := range reqs {
+			requests[i] = &serverRequest{id: r.id, err: err}
+		}
+		return requests, batch, nil
+	}
+
 	// verify requests
 	for i, r := range reqs {
 		var ok bool
@@ -392,6 +415,11 @@ func (s *Server) readRequest(codec ServerCodec) ([]*serverRequest, bool, Error)
 			continue
 		}
 
+		if err := s.checkRate(ctx); err != nil {
+			requests[i] = &serverRequest{id: r.id, err: err}
+			continue
+		}
+
 		if r.isPubSub && strings.HasSuffix(r.method, unsubscribeMethodSuffix) {
 			requests[i] = &serverRequest{id: r.id, isUnsubscribe: true}
 			argTypes := []reflect.Type{reflect.TypeOf("")} // expect subscription id as first arg
@@ -408,6 +436,15 @@ func (s *Server) readRequest(codec ServerCodec) ([]*serverRequest, bool, Error)
 			continue
 		}
 
+		method := r.method
+		if r.isPubSub { // access to subscriptions is granted through the subscribe method
+			method = "subscribe"
+		}
+		if err := authorize(ctx, r.service, method); err != nil {
+			requests[i] = &serverRequest{id: r.id, err: err}
+			continue
+		}
+
 		if r.isPubSub { // eth_subscribe, r.method contains the subscription method name
 			if callb, ok := svc.subscriptions[r.method]; ok {
 				requests[i] = &serverRequest{id: r.id, svcname: svc.name, callb: callb}
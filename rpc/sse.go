@@ -0,0 +1,190 @@
+// Copyright 2018 The go-ruereum Authors
+// This file is part of the go-ruereum library.
+//
+// The go-ruereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ruereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ruereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	sseContentType   = "text/event-stream"
+	sseKeepaliveRate = 15 * time.Second // Interval of comment lines keeping idle streams open
+)
+
+// errStreamClosed is returned when writing to an event stream whose client is gone.
+var errStreamClosed = errors.New("event stream closed")
+
+// isSSERequest reports whether the client asked for its requests to be served
+// as a stream of server-sent events.
+func isSSERequest(r *http.Request) bool {
+	for _, accept := range strings.Split(r.Header.Get("accept"), ",") {
+		if mt := strings.TrimSpace(strings.Split(accept, ";")[0]); mt == sseContentType {
+			return true
+		}
+	}
+	return false
+}
+
+// serveSSE serves the JSON-RPC requests contained in the body of a POST request
+// over a stream of server-sent events, keeping the stream open for the
+// notifications of any subscriptions created until the client disconnects.
+//
+// Requests in the query string of GET requests, as sent by browser EventSources,
+// are deliberately not supported: those are exempt from CORS preflights, letting
+// any web page invoke methods on a local node. Subscriptions end when the client
+// closes the stream.
+func (srv *Server) serveSSE(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if code, err := validateRequest(r); err != nil {
+		http.Error(w, err.Error(), code)
+		return
+	}
+	// Consume the body before streaming, HTTP/1 can't read it after responding
+	blob, err := ioutil.ReadAll(io.LimitReader(r.Body, maxHTTPRequestContentLength))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("content-type", sseContentType)
+	w.Header().Set("cache-control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	codec := newSSECodec(bytes.NewReader(blob), w, flusher)
+	defer codec.Close()
+
+	// Tear the stream down when the client goes away
+	go func() {
+		select {
+		case <-r.Context().Done():
+			codec.Close()
+		case <-codec.Closed():
+		}
+	}()
+	go codec.keepalive(sseKeepaliveRate)
+
+	srv.serveRequest(srv.connContext(r), codec, false, OptionMethodInvocation|OptionSubscriptions)
+}
+
+// sseCodec is a JSON codec reading requests from an HTTP request and writing
+// responses and notifications as server-sent events. Once the requests are
+// consumed, reads block until the stream is closed so the server keeps serving
+// the connection's subscriptions.
+type sseCodec struct {
+	ServerCodec
+
+	w       io.Writer
+	flusher http.Flusher
+	lock    sync.Mutex    // Guards the writer and the closed flag
+	closed  bool          // Whether the stream was closed, rejecting writes
+	done    chan struct{} // Closed when the stream is closed, ending reads
+}
+
+// newSSECodec creates a codec reading requests from body and streaming events
+// to w.
+func newSSECodec(body io.Reader, w io.Writer, flusher http.Flusher) *sseCodec {
+	done := make(chan struct{})
+	rw := &httpReadWriteNopCloser{io.MultiReader(body, &blockingReader{done}), nil}
+
+	return &sseCodec{
+		ServerCodec: NewJSONCodec(rw),
+		w:           w,
+		flusher:     flusher,
+		done:        done,
+	}
+}
+
+// Write sends msg to the client as a single event.
+func (c *sseCodec) Write(msg interface{}) error {
+	blob, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return c.send("data: " + string(blob) + "\n\n")
+}
+
+// keepalive periodically sends a comment line to the client, preventing proxies
+// from cutting streams without notifications for a while.
+func (c *sseCodec) keepalive(rate time.Duration) {
+	ticker := time.NewTicker(rate)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := c.send(": keepalive\n\n"); err != nil {
+				return
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// send writes a raw event to the stream and flushes it to the client.
+func (c *sseCodec) send(event string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.closed {
+		return errStreamClosed
+	}
+	if _, err := io.WriteString(c.w, event); err != nil {
+		return err
+	}
+	c.flusher.Flush()
+	return nil
+}
+
+// Close ends the stream, after which no more events are written.
+func (c *sseCodec) Close() {
+	c.lock.Lock()
+	if !c.closed {
+		c.closed = true
+		close(c.done)
+	}
+	c.lock.Unlock()
+
+	c.ServerCodec.Close()
+}
+
+// blockingReader is a reader blocking until the done channel is closed, then
+// reporting the end of the stream.
+type blockingReader struct {
+	done chan struct{}
+}
+
+func (r *blockingReader) Read(p []byte) (int, error) {
+	<-r.done
+	return 0, io.EOF
+}
@@ -0,0 +1,158 @@
+// Copyright 2018 The go-ruereum Authors
+// This file is part of the go-ruereum library.
+//
+// The go-ruereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ruereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ruereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// SSEService is a test service streaming counters through subscriptions.
+type SSEService struct {
+	start chan struct{} // Signals a subscription to start notifying
+	ended chan struct{} // Signalled when a subscription's connection closes
+}
+
+func (s *SSEService) Counter(ctx context.Context, n int) (*Subscription, error) {
+	notifier, supported := NotifierFromContext(ctx)
+	if !supported {
+		return nil, ErrNotificationsUnsupported
+	}
+	sub := notifier.CreateSubscription()
+
+	go func() {
+		<-s.start
+		for i := 0; i < n; i++ {
+			if err := notifier.Notify(sub.ID, i); err != nil {
+				return
+			}
+		}
+		<-notifier.Closed()
+		s.ended <- struct{}{}
+	}()
+	return sub, nil
+}
+
+// readEvent reads the next data event from a server-sent event stream, skipping
+// over comments.
+func readEvent(t *testing.T, stream *bufio.Reader) *jsonrpcMessage {
+	for {
+		line, err := stream.ReadString('\n')
+		if err != nil {
+			t.Fatalf("failed to read event: %v", err)
+		}
+		if !strings.HasPrefix(line, "data: ") {
+			continue
+		}
+		msg := new(jsonrpcMessage)
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), msg); err != nil {
+			t.Fatalf("failed to decode event %q: %v", line, err)
+		}
+		return msg
+	}
+}
+
+// Tests that subscriptions can be consumed as server-sent events over HTTP.
+func TestSSESubscription(t *testing.T) {
+	server := NewServer()
+	service := &SSEService{start: make(chan struct{}), ended: make(chan struct{})}
+	if err := server.RegisterName("test", service); err != nil {
+		t.Fatalf("failed to register service: %v", err)
+	}
+	httpsrv := httptest.NewServer(server)
+	defer httpsrv.Close()
+
+	request := `{"jsonrpc":"2.0","id":1,"method":"test_subscribe","params":["counter",3]}`
+
+	req, _ := http.NewRequest(http.MethodPost, httpsrv.URL, strings.NewReader(request))
+	req.Header.Set("content-type", contentType)
+	req.Header.Set("accept", sseContentType)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to open stream: %v", err)
+	}
+	if ct := resp.Header.Get("content-type"); ct != sseContentType {
+		t.Fatalf("content type mismatch: have %s, want %s", ct, sseContentType)
+	}
+	stream := bufio.NewReader(resp.Body)
+
+	// Retrieve the subscription id, then all the notifications
+	msg := readEvent(t, stream)
+	var id string
+	if err := json.Unmarshal(msg.Result, &id); err != nil || id == "" {
+		t.Fatalf("invalid subscription response: %s %v", msg.Result, msg.Error)
+	}
+	service.start <- struct{}{}
+
+	for i := 0; i < 3; i++ {
+		msg := readEvent(t, stream)
+		if msg.Method != "test"+notificationMethodSuffix {
+			t.Fatalf("notification method mismatch: have %s", msg.Method)
+		}
+		var params struct {
+			Subscription string
+			Result       int
+		}
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			t.Fatalf("invalid notification: %v", err)
+		}
+		if params.Subscription != id || params.Result != i {
+			t.Fatalf("notification %d mismatch: %+v", i, params)
+		}
+	}
+	// Disconnecting the client should end the subscription
+	resp.Body.Close()
+	select {
+	case <-service.ended:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("subscription not ended after disconnect")
+	}
+}
+
+// Tests that requests can't be smuggled into event streams through the query
+// string of GET requests, which browsers send cross-origin without a preflight.
+func TestSSERejectsGet(t *testing.T) {
+	server := NewServer()
+	if err := server.RegisterName("test", new(Service)); err != nil {
+		t.Fatalf("failed to register service: %v", err)
+	}
+	httpsrv := httptest.NewServer(server)
+	defer httpsrv.Close()
+
+	request := `{"jsonrpc":"2.0","id":1,"method":"test_echo","params":["hello",1,{}]}`
+
+	req, _ := http.NewRequest(http.MethodGet, httpsrv.URL+"?request="+url.QueryEscape(request), nil)
+	req.Header.Set("accept", sseContentType)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to send request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("status code mismatch: have %d, want %d", resp.StatusCode, http.StatusMethodNotAllowed)
+	}
+}
@@ -107,6 +107,14 @@ func (n *Notifier) Closed() <-chan interface{} {
 	return n.codec.Closed()
 }
 
+// count returns the number of subscriptions of the connection, including those
+// not activated yet.
+func (n *Notifier) count() int {
+	n.subMu.RLock()
+	defer n.subMu.RUnlock()
+	return len(n.active) + len(n.inactive)
+}
+
 // unsubscribe a subscription.
 // If the subscription could not be found ErrSubscriptionNotFound is returned.
 func (n *Notifier) unsubscribe(id ID) error {
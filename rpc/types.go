@@ -74,6 +74,10 @@ type Server struct {
 	run      int32
 	codecsMu sync.Mutex
 	codecs   *set.Set
+
+	auth    *Authenticator // Access control of HTTP and websocket requests, nil if disabled
+	limits  Limits         // Resource quotas enforced on the served requests
+	limiter *rateLimiter   // Per client request rate limiter, nil if unlimited
 }
 
 // rpcRequest represents a raw incoming RPC request
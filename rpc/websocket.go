@@ -40,7 +40,10 @@ func (srv *Server) WebsocketHandler(allowedOrigins []string) http.Handler {
 	return websocket.Server{
 		Handshake: wsHandshakeValidator(allowedOrigins),
 		Handler: func(conn *websocket.Conn) {
-			srv.ServeCodec(NewJSONCodec(conn), OptionMethodInvocation|OptionSubscriptions)
+			codec := NewJSONCodec(conn)
+			defer codec.Close()
+
+			srv.serveRequest(srv.connContext(conn.Request()), codec, false, OptionMethodInvocation|OptionSubscriptions)
 		},
 	}
 }
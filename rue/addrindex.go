@@ -0,0 +1,103 @@
+// Copyright 2018 The go-ruereum Authors
+// This file is part of the go-ruereum library.
+//
+// The go-ruereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ruereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ruereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rue
+
+import (
+	"fmt"
+
+	"github.com/Rue-Foundation/go-rue/common"
+	"github.com/Rue-Foundation/go-rue/core"
+	"github.com/Rue-Foundation/go-rue/core/types"
+	"github.com/Rue-Foundation/go-rue/params"
+	"github.com/Rue-Foundation/go-rue/ruedb"
+)
+
+// AddrIndexer implements a core.ChainIndexer, building up an index from account
+// addresses to the transactions sending to, received by or created by them.
+type AddrIndexer struct {
+	db     ruedb.Database      // database instance to read blocks from and write index data into
+	config *params.ChainConfig // chain configuration to derive transaction senders with
+
+	section uint64                               // Section is the section number being processed currently
+	head    common.Hash                          // Head is the hash of the last header processed
+	entries map[common.Address][]core.TxPosition // Transaction positions accumulated for the current section
+	err     error                                // Error encountered while processing the current section
+}
+
+// NewAddrIndexer returns a chain indexer that generates an address transaction
+// history index for the canonical chain.
+func NewAddrIndexer(db ruedb.Database, config *params.ChainConfig, size uint64) *core.ChainIndexer {
+	backend := &AddrIndexer{
+		db:     db,
+		config: config,
+	}
+	table := ruedb.NewTable(db, string(core.AddrTxIndexPrefix))
+
+	return core.NewChainIndexer(db, table, backend, size, bloomConfirms, bloomThrottling, "addrindex")
+}
+
+// Reset implements core.ChainIndexerBackend, starting a new address index section.
+func (a *AddrIndexer) Reset(section uint64, lastSectionHead common.Hash) error {
+	a.section, a.head, a.err = section, common.Hash{}, nil
+	a.entries = make(map[common.Address][]core.TxPosition)
+	return nil
+}
+
+// Process implements core.ChainIndexerBackend, adding the transactions of a new
+// header's block into the index.
+func (a *AddrIndexer) Process(header *types.Header) {
+	hash, number := header.Hash(), header.Number.Uint64()
+
+	body := core.GetBody(a.db, hash, number)
+	if body == nil {
+		if a.err == nil {
+			a.err = fmt.Errorf("block #%d [%x…] body missing", number, hash[:4])
+		}
+		return
+	}
+	signer := types.MakeSigner(a.config, header.Number)
+	for i, tx := range body.Transactions {
+		addresses, err := core.TxAddresses(signer, tx)
+		if err != nil {
+			if a.err == nil {
+				a.err = fmt.Errorf("block #%d [%x…] transaction %d: %v", number, hash[:4], i, err)
+			}
+			return
+		}
+		pos := core.TxPosition{Block: number, Index: uint32(i)}
+		for _, address := range addresses {
+			a.entries[address] = append(a.entries[address], pos)
+		}
+	}
+	a.head = hash
+}
+
+// Commit implements core.ChainIndexerBackend, writing the accumulated address
+// index section out into the database.
+func (a *AddrIndexer) Commit() error {
+	if a.err != nil {
+		return a.err
+	}
+	batch := a.db.NewBatch()
+
+	for address, positions := range a.entries {
+		if err := core.WriteAddrTxIndex(batch, address, a.section, a.head, positions); err != nil {
+			return err
+		}
+	}
+	return batch.Write()
+}
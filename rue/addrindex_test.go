@@ -0,0 +1,162 @@
+// Copyright 2018 The go-ruereum Authors
+// This file is part of the go-ruereum library.
+//
+// The go-ruereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ruereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ruereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rue
+
+import (
+	"context"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/Rue-Foundation/go-rue/common"
+	"github.com/Rue-Foundation/go-rue/common/hexutil"
+	"github.com/Rue-Foundation/go-rue/consensus/ruehash"
+	"github.com/Rue-Foundation/go-rue/core"
+	"github.com/Rue-Foundation/go-rue/core/types"
+	"github.com/Rue-Foundation/go-rue/core/vm"
+	"github.com/Rue-Foundation/go-rue/crypto"
+	"github.com/Rue-Foundation/go-rue/internal/rueapi"
+	"github.com/Rue-Foundation/go-rue/params"
+	"github.com/Rue-Foundation/go-rue/rpc"
+	"github.com/Rue-Foundation/go-rue/ruedb"
+)
+
+// addrIndexBackend is an API backend serving a fixed address index status.
+type addrIndexBackend struct {
+	*EthApiBackend
+	size, sections uint64
+	head           common.Hash
+}
+
+func (b *addrIndexBackend) AddressIndexStatus() (uint64, uint64, common.Hash) {
+	return b.size, b.sections, b.head
+}
+
+// Tests that the address history of an account is retrieved correctly in pages,
+// both from the address index and by scanning unindexed blocks.
+func TestGetTransactionsByAddress(t *testing.T) {
+	var (
+		db, _ = ruedb.NewMemDatabase()
+		gspec = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  core.GenesisAlloc{testBank: {Balance: big.NewInt(1000000000)}},
+		}
+		genesis       = gspec.MustCommit(db)
+		blockchain, _ = core.NewBlockChain(db, nil, gspec.Config, ruehash.NewFaker(), vm.Config{})
+
+		recipient = common.Address{0x01}
+		signer    = types.HomesteadSigner{}
+	)
+	defer blockchain.Stop()
+
+	// Send funds to the recipient in every block, and create a contract in one
+	var (
+		nonce    uint64
+		contract common.Address
+	)
+	chain, _ := core.GenerateChain(gspec.Config, genesis, ruehash.NewFaker(), db, 10, func(i int, gen *core.BlockGen) {
+		for j := 0; j < i%3; j++ {
+			tx, _ := types.SignTx(types.NewTransaction(nonce, recipient, big.NewInt(1), big.NewInt(21000), new(big.Int), nil), signer, testBankKey)
+			gen.AddTx(tx)
+			nonce++
+		}
+		if i == 5 {
+			tx, _ := types.SignTx(types.NewContractCreation(nonce, new(big.Int), big.NewInt(100000), new(big.Int), []byte{0x00}), signer, testBankKey)
+			gen.AddTx(tx)
+			contract = crypto.CreateAddress(testBank, nonce)
+			nonce++
+		}
+	})
+	if _, err := blockchain.InsertChain(chain); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+
+	// Index the first two sections of four blocks each
+	indexer := &AddrIndexer{db: db, config: gspec.Config}
+	for section := uint64(0); section < 2; section++ {
+		indexer.Reset(section, common.Hash{})
+		for number := section * 4; number < (section+1)*4; number++ {
+			indexer.Process(blockchain.GetHeaderByNumber(number))
+		}
+		if err := indexer.Commit(); err != nil {
+			t.Fatalf("failed to commit section %d: %v", section, err)
+		}
+	}
+	var (
+		plain   = &EthApiBackend{rue: &Ruereum{blockchain: blockchain, chainDb: db, chainConfig: gspec.Config}}
+		indexed = &addrIndexBackend{EthApiBackend: plain, size: 4, sections: 2, head: blockchain.GetHeaderByNumber(7).Hash()}
+	)
+	// Collects the history of an address page by page
+	history := func(backend rueapi.Backend, address common.Address, from, to rpc.BlockNumber) []common.Hash {
+		var (
+			api    = rueapi.NewPublicTransactionPoolAPI(backend, nil)
+			limit  = hexutil.Uint64(2)
+			cursor *rueapi.AddressTxCursor
+			hashes []common.Hash
+		)
+		for {
+			page, err := api.GetTransactionsByAddress(context.Background(), address, from, to, cursor, &limit)
+			if err != nil {
+				t.Fatalf("failed to retrieve address history: %v", err)
+			}
+			if len(page.Transactions) > int(limit) {
+				t.Fatalf("page size mismatch: have %d, limit %d", len(page.Transactions), limit)
+			}
+			for _, tx := range page.Transactions {
+				hashes = append(hashes, tx.Hash)
+			}
+			if cursor = page.Next; cursor == nil {
+				return hashes
+			}
+		}
+	}
+	// Assemble the expected histories from the chain itself
+	var all, received, created []common.Hash
+	for _, block := range chain {
+		for _, tx := range block.Transactions() {
+			all = append(all, tx.Hash())
+			if tx.To() == nil {
+				created = append(created, tx.Hash())
+			} else if block.NumberU64() >= 3 && block.NumberU64() <= 8 {
+				received = append(received, tx.Hash())
+			}
+		}
+	}
+	for i, backend := range []rueapi.Backend{plain, indexed} {
+		if have := history(backend, testBank, 0, rpc.LatestBlockNumber); !reflect.DeepEqual(have, all) {
+			t.Errorf("backend %d: sender history mismatch: have %x, want %x", i, have, all)
+		}
+		if have := history(backend, recipient, 3, 8); !reflect.DeepEqual(have, received) {
+			t.Errorf("backend %d: recipient history mismatch: have %x, want %x", i, have, received)
+		}
+		if have := history(backend, contract, 0, rpc.LatestBlockNumber); !reflect.DeepEqual(have, created) {
+			t.Errorf("backend %d: contract history mismatch: have %x, want %x", i, have, created)
+		}
+	}
+	// Rewind the chain and ensure the stale index isn't used anymore
+	blockchain.SetHead(5)
+
+	var rewound []common.Hash
+	for _, block := range chain[:5] {
+		for _, tx := range block.Transactions() {
+			rewound = append(rewound, tx.Hash())
+		}
+	}
+	if have := history(indexed, testBank, 0, rpc.LatestBlockNumber); !reflect.DeepEqual(have, rewound) {
+		t.Errorf("rewound history mismatch: have %x, want %x", have, rewound)
+	}
+}
@@ -140,6 +140,10 @@ func (b *EthApiBackend) SendTx(ctx context.Context, signedTx *types.Transaction)
 	return b.rue.txPool.AddLocal(signedTx)
 }
 
+func (b *EthApiBackend) SendPrivateTx(ctx context.Context, signedTx *types.Transaction, expiry uint64) error {
+	return b.rue.txPool.AddPrivate(signedTx, expiry)
+}
+
 func (b *EthApiBackend) GetPoolTransactions() (types.Transactions, error) {
 	pending, err := b.rue.txPool.Pending()
 	if err != nil {
@@ -164,10 +168,22 @@ func (b *EthApiBackend) Stats() (pending int, queued int) {
 	return b.rue.txPool.Stats()
 }
 
+func (b *EthApiBackend) PrivateStats() int {
+	return b.rue.txPool.PrivateStats()
+}
+
 func (b *EthApiBackend) TxPoolContent() (map[common.Address]types.Transactions, map[common.Address]types.Transactions) {
 	return b.rue.TxPool().Content()
 }
 
+func (b *EthApiBackend) SubscribeTxDropEvent(ch chan<- core.TxDropEvent) event.Subscription {
+	return b.rue.TxPool().SubscribeTxDropEvent(ch)
+}
+
+func (b *EthApiBackend) TxPoolRejections() []*core.TxRejection {
+	return b.rue.TxPool().Rejections()
+}
+
 func (b *EthApiBackend) SubscribeTxPreEvent(ch chan<- core.TxPreEvent) event.Subscription {
 	return b.rue.TxPool().SubscribeTxPreEvent(ch)
 }
@@ -201,6 +217,22 @@ func (b *EthApiBackend) BloomStatus() (uint64, uint64) {
 	return params.BloomBitsBlocks, sections
 }
 
+func (b *EthApiBackend) LogIndexStatus() (uint64, uint64) {
+	if b.rue.logIndexer == nil {
+		return 0, 0
+	}
+	sections, _, _ := b.rue.logIndexer.Sections()
+	return params.BloomBitsBlocks, sections
+}
+
+func (b *EthApiBackend) AddressIndexStatus() (uint64, uint64, common.Hash) {
+	if b.rue.addrIndexer == nil {
+		return 0, 0, common.Hash{}
+	}
+	sections, _, head := b.rue.addrIndexer.Sections()
+	return params.BloomBitsBlocks, sections, head
+}
+
 func (b *EthApiBackend) ServiceFilter(ctx context.Context, session *bloombits.MatcherSession) {
 	for i := 0; i < bloomFilterThreads; i++ {
 		go session.Multiplex(bloomRetrievalBatch, bloomRetrievalWait, b.rue.bloomRequests)
@@ -0,0 +1,273 @@
+// Copyright 2018 The go-ruereum Authors
+// This file is part of the go-ruereum library.
+//
+// The go-ruereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ruereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ruereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rue
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/Rue-Foundation/go-rue/common"
+	"github.com/Rue-Foundation/go-rue/common/hexutil"
+	"github.com/Rue-Foundation/go-rue/core"
+	"github.com/Rue-Foundation/go-rue/core/state"
+	"github.com/Rue-Foundation/go-rue/core/types"
+	"github.com/Rue-Foundation/go-rue/core/vm"
+	"github.com/Rue-Foundation/go-rue/internal/rueapi"
+	"github.com/Rue-Foundation/go-rue/rlp"
+	"github.com/Rue-Foundation/go-rue/rpc"
+)
+
+// errEmptyBundle is returned if a bundle simulation is requested without any
+// transactions to execute.
+var errEmptyBundle = errors.New("empty bundle")
+
+// BundleCall is a single transaction of a simulated bundle. It is either a raw
+// signed transaction, or unsigned call arguments executed on behalf of From.
+type BundleCall struct {
+	rueapi.CallArgs
+	Raw hexutil.Bytes `json:"raw"` // RLP encoded signed transaction, overrides the call arguments
+}
+
+// BundleConfig holds extra parameters to bundle simulations.
+type BundleConfig struct {
+	Coinbase  *common.Address // Beneficiary of the simulated block, parent's if unset
+	Timestamp *hexutil.Uint64 // Timestamp of the simulated block, parent's + 1 if unset
+	Reexec    *uint64         // Number of blocks to reexecute for missing historical state
+}
+
+// BundleTxResult is the outcome of a single transaction within a bundle.
+type BundleTxResult struct {
+	Hash        common.Hash   `json:"hash"`            // Hash of the transaction (unsigned for calls)
+	GasUsed     *hexutil.Big  `json:"gasUsed"`         // Gas consumed by the transaction
+	ReturnValue hexutil.Bytes `json:"returnValue"`     // Data returned by the execution
+	Logs        []*types.Log  `json:"logs"`            // Logs emitted by the execution
+	Failed      bool          `json:"failed"`          // Whether the execution reverted or failed
+	Error       string        `json:"error,omitempty"` // Reason the transaction could not be applied at all
+}
+
+// Diff is a pair of values a state field had before and after a simulation.
+type Diff struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// AccountDiff contains the changes a simulation made to a single account.
+type AccountDiff struct {
+	Balance *Diff                 `json:"balance,omitempty"`
+	Nonce   *Diff                 `json:"nonce,omitempty"`
+	Code    *Diff                 `json:"code,omitempty"`
+	Storage map[common.Hash]*Diff `json:"storage,omitempty"`
+}
+
+// BundleResult is the outcome of simulating an entire bundle.
+type BundleResult struct {
+	Results   []*BundleTxResult               `json:"results"`   // Per transaction results, in bundle order
+	GasUsed   *hexutil.Big                    `json:"gasUsed"`   // Total gas consumed by the bundle
+	StateDiff map[common.Address]*AccountDiff `json:"stateDiff"` // Accounts modified by the bundle
+}
+
+// SimulateBundle executes the given transactions sequentially on top of the
+// requested block, as if they were included in this order in its child, and
+// returns the individual execution results along with the resulting state diff.
+// No changes are persisted.
+func (api *PrivateDebugAPI) SimulateBundle(ctx context.Context, calls []BundleCall, number rpc.BlockNumber, config *BundleConfig) (*BundleResult, error) {
+	if len(calls) == 0 {
+		return nil, errEmptyBundle
+	}
+	if config == nil {
+		config = new(BundleConfig)
+	}
+	// Retrieve the block to build on and its state
+	var (
+		parent  *types.Block
+		statedb *state.StateDB
+		err     error
+	)
+	switch number {
+	case rpc.PendingBlockNumber:
+		if parent, statedb = api.rue.miner.Pending(); parent == nil {
+			return nil, errors.New("pending block not available")
+		}
+	case rpc.LatestBlockNumber:
+		parent = api.rue.blockchain.CurrentBlock()
+	default:
+		if parent = api.rue.blockchain.GetBlockByNumber(uint64(number)); parent == nil {
+			return nil, fmt.Errorf("block #%d not found", uint64(number))
+		}
+	}
+	if statedb == nil {
+		reexec := defaultTraceReexec
+		if config.Reexec != nil {
+			reexec = *config.Reexec
+		}
+		if statedb, err = api.computeStateDB(parent, reexec); err != nil {
+			return nil, err
+		}
+	}
+	// Assemble the header of the block the bundle is simulated in
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Coinbase:   parent.Coinbase(),
+		Number:     new(big.Int).Add(parent.Number(), common.Big1),
+		GasLimit:   parent.GasLimit(),
+		Difficulty: parent.Difficulty(),
+		Time:       new(big.Int).Add(parent.Time(), common.Big1),
+	}
+	if config.Coinbase != nil {
+		header.Coinbase = *config.Coinbase
+	}
+	if config.Timestamp != nil {
+		header.Time = new(big.Int).SetUint64(uint64(*config.Timestamp))
+	}
+	return api.simulateBundle(ctx, calls, header, statedb)
+}
+
+// simulateBundle executes a bundle of transactions on top of the given state in
+// the context of the given header.
+func (api *PrivateDebugAPI) simulateBundle(ctx context.Context, calls []BundleCall, header *types.Header, statedb *state.StateDB) (*BundleResult, error) {
+	var (
+		signer  = types.MakeSigner(api.config, header.Number)
+		gp      = new(core.GasPool).AddGas(header.GasLimit)
+		base    = statedb.Copy()
+		dirties = make(map[common.Address]map[common.Hash]struct{})
+
+		result = &BundleResult{
+			Results: make([]*BundleTxResult, len(calls)),
+			GasUsed: new(hexutil.Big),
+		}
+	)
+	for i, call := range calls {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		msg, hash, err := api.bundleMessage(call, signer, gp, statedb)
+		if err != nil {
+			return nil, fmt.Errorf("bundle transaction %d: %v", i, err)
+		}
+		statedb.Prepare(hash, common.Hash{}, i)
+
+		// Execute the transaction, discarding any changes if it can't be applied
+		var (
+			snapshot = statedb.Snapshot()
+			gasLeft  = gp.Gas()
+		)
+		vmctx := core.NewEVMContext(msg, header, api.rue.blockchain, &header.Coinbase)
+		vmenv := vm.NewEVM(vmctx, statedb, api.config, vm.Config{})
+
+		ret, gas, failed, err := core.ApplyMessage(vmenv, msg, gp)
+		if err != nil {
+			// The gas may have been bought before the failure, return it to the pool
+			statedb.RevertToSnapshot(snapshot)
+			(*big.Int)(gp).Set(gasLeft)
+			result.Results[i] = &BundleTxResult{Hash: hash, GasUsed: new(hexutil.Big), Logs: []*types.Log{}, Error: err.Error()}
+			continue
+		}
+		logs := statedb.GetLogs(hash)
+		if logs == nil {
+			logs = []*types.Log{}
+		}
+		result.Results[i] = &BundleTxResult{
+			Hash:        hash,
+			GasUsed:     (*hexutil.Big)(gas),
+			ReturnValue: ret,
+			Logs:        logs,
+			Failed:      failed,
+		}
+		(*big.Int)(result.GasUsed).Add((*big.Int)(result.GasUsed), gas)
+
+		// Track the modified accounts before finalising the transaction
+		for addr, keys := range statedb.Dirties() {
+			if dirties[addr] == nil {
+				dirties[addr] = make(map[common.Hash]struct{})
+			}
+			for _, key := range keys {
+				dirties[addr][key] = struct{}{}
+			}
+		}
+		statedb.Finalise(true)
+	}
+	result.StateDiff = diffState(base, statedb, dirties)
+	return result, nil
+}
+
+// bundleMessage converts a bundle transaction into a message executable on top
+// of the given state, returning the hash to attribute its logs to. Unsigned
+// calls without a gas allowance get all the gas left in the pool.
+func (api *PrivateDebugAPI) bundleMessage(call BundleCall, signer types.Signer, gp *core.GasPool, statedb *state.StateDB) (core.Message, common.Hash, error) {
+	// Signed transactions are executed as is, nonce checks included
+	if len(call.Raw) > 0 {
+		tx := new(types.Transaction)
+		if err := rlp.DecodeBytes(call.Raw, tx); err != nil {
+			return nil, common.Hash{}, err
+		}
+		msg, err := tx.AsMessage(signer)
+		if err != nil {
+			return nil, common.Hash{}, err
+		}
+		return msg, tx.Hash(), nil
+	}
+	// Unsigned calls get sensible defaults for any unset field
+	var (
+		nonce    = statedb.GetNonce(call.From)
+		gas      = call.Gas.ToInt()
+		gasPrice = call.GasPrice.ToInt()
+		value    = call.Value.ToInt()
+	)
+	if gas.Sign() == 0 {
+		gas = gp.Gas()
+	}
+	var tx *types.Transaction
+	if call.To == nil {
+		tx = types.NewContractCreation(nonce, value, gas, gasPrice, call.Data)
+	} else {
+		tx = types.NewTransaction(nonce, *call.To, value, gas, gasPrice, call.Data)
+	}
+	return types.NewMessage(call.From, call.To, nonce, value, gas, gasPrice, call.Data, false), tx.Hash(), nil
+}
+
+// diffState compares the given accounts and storage slots between two states,
+// returning the ones that changed.
+func diffState(pre, post *state.StateDB, dirties map[common.Address]map[common.Hash]struct{}) map[common.Address]*AccountDiff {
+	diffs := make(map[common.Address]*AccountDiff)
+	for addr, keys := range dirties {
+		diff := new(AccountDiff)
+		if from, to := pre.GetBalance(addr), post.GetBalance(addr); from.Cmp(to) != 0 {
+			diff.Balance = &Diff{From: (*hexutil.Big)(from), To: (*hexutil.Big)(to)}
+		}
+		if from, to := pre.GetNonce(addr), post.GetNonce(addr); from != to {
+			diff.Nonce = &Diff{From: hexutil.Uint64(from), To: hexutil.Uint64(to)}
+		}
+		if from, to := pre.GetCode(addr), post.GetCode(addr); !bytes.Equal(from, to) {
+			diff.Code = &Diff{From: hexutil.Bytes(from), To: hexutil.Bytes(to)}
+		}
+		for key := range keys {
+			if from, to := pre.GetState(addr, key), post.GetState(addr, key); from != to {
+				if diff.Storage == nil {
+					diff.Storage = make(map[common.Hash]*Diff)
+				}
+				diff.Storage[key] = &Diff{From: from, To: to}
+			}
+		}
+		if diff.Balance != nil || diff.Nonce != nil || diff.Code != nil || diff.Storage != nil {
+			diffs[addr] = diff
+		}
+	}
+	return diffs
+}
@@ -0,0 +1,163 @@
+// Copyright 2018 The go-ruereum Authors
+// This file is part of the go-ruereum library.
+//
+// The go-ruereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ruereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ruereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rue
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/Rue-Foundation/go-rue/common"
+	"github.com/Rue-Foundation/go-rue/common/hexutil"
+	"github.com/Rue-Foundation/go-rue/consensus/ruehash"
+	"github.com/Rue-Foundation/go-rue/core"
+	"github.com/Rue-Foundation/go-rue/core/types"
+	"github.com/Rue-Foundation/go-rue/core/vm"
+	"github.com/Rue-Foundation/go-rue/internal/rueapi"
+	"github.com/Rue-Foundation/go-rue/params"
+	"github.com/Rue-Foundation/go-rue/rlp"
+	"github.com/Rue-Foundation/go-rue/rpc"
+	"github.com/Rue-Foundation/go-rue/ruedb"
+)
+
+// Tests that a bundle of signed and unsigned transactions is executed in order
+// on top of a block, and that the resulting state diff is reported.
+func TestSimulateBundle(t *testing.T) {
+	var (
+		db, _ = ruedb.NewMemDatabase()
+		gspec = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  core.GenesisAlloc{testBank: {Balance: big.NewInt(1000000)}},
+		}
+		_             = gspec.MustCommit(db)
+		blockchain, _ = core.NewBlockChain(db, nil, gspec.Config, ruehash.NewFaker(), vm.Config{})
+		api           = NewPrivateDebugAPI(gspec.Config, &Ruereum{blockchain: blockchain})
+
+		recipient = common.Address{0x01}
+	)
+	defer blockchain.Stop()
+
+	// Create a signed transaction following an unsigned call, and a stale one
+	sign := func(nonce uint64, value int64) hexutil.Bytes {
+		tx, _ := types.SignTx(types.NewTransaction(nonce, recipient, big.NewInt(value), big.NewInt(21000), new(big.Int), nil), types.HomesteadSigner{}, testBankKey)
+		blob, _ := rlp.EncodeToBytes(tx)
+		return blob
+	}
+	calls := []BundleCall{
+		{CallArgs: rueapi.CallArgs{From: testBank, To: &recipient, Gas: hexutil.Big(*big.NewInt(21000)), Value: hexutil.Big(*big.NewInt(1000))}},
+		{Raw: sign(1, 500)},
+		{Raw: sign(0, 500)},
+	}
+	result, err := api.SimulateBundle(context.Background(), calls, rpc.LatestBlockNumber, nil)
+	if err != nil {
+		t.Fatalf("failed to simulate bundle: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if res := result.Results[i]; res.Error != "" || res.Failed || res.GasUsed.ToInt().Uint64() != 21000 {
+			t.Errorf("transaction %d: unexpected result: %+v", i, res)
+		}
+	}
+	if result.Results[2].Error == "" {
+		t.Errorf("stale nonce transaction applied")
+	}
+	if gas := result.GasUsed.ToInt().Uint64(); gas != 42000 {
+		t.Errorf("bundle gas mismatch: have %d, want %d", gas, 42000)
+	}
+	// Verify the reported state changes
+	if diff := result.StateDiff[recipient]; diff == nil || diff.Balance == nil || diff.Balance.To.(*hexutil.Big).ToInt().Int64() != 1500 {
+		t.Errorf("recipient diff mismatch: %+v", diff)
+	}
+	if diff := result.StateDiff[testBank]; diff == nil || diff.Nonce == nil || diff.Nonce.To.(hexutil.Uint64) != 2 {
+		t.Errorf("sender diff mismatch: %+v", diff)
+	}
+	// Make sure nothing was persisted
+	statedb, _ := blockchain.State()
+	if balance := statedb.GetBalance(recipient); balance.Sign() != 0 {
+		t.Errorf("simulation leaked into chain state: balance %v", balance)
+	}
+}
+
+// Tests that multiple unsigned calls without an explicit gas allowance can be
+// executed in the same bundle, each defaulting to the gas left in the pool.
+func TestSimulateBundleDefaultGas(t *testing.T) {
+	var (
+		db, _ = ruedb.NewMemDatabase()
+		gspec = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  core.GenesisAlloc{testBank: {Balance: big.NewInt(1000000)}},
+		}
+		_             = gspec.MustCommit(db)
+		blockchain, _ = core.NewBlockChain(db, nil, gspec.Config, ruehash.NewFaker(), vm.Config{})
+		api           = NewPrivateDebugAPI(gspec.Config, &Ruereum{blockchain: blockchain})
+
+		recipient = common.Address{0x01}
+	)
+	defer blockchain.Stop()
+
+	calls := []BundleCall{
+		{CallArgs: rueapi.CallArgs{From: testBank, To: &recipient, Value: hexutil.Big(*big.NewInt(1000))}},
+		{CallArgs: rueapi.CallArgs{From: testBank, To: &recipient, Value: hexutil.Big(*big.NewInt(1000))}},
+	}
+	result, err := api.SimulateBundle(context.Background(), calls, rpc.LatestBlockNumber, nil)
+	if err != nil {
+		t.Fatalf("failed to simulate bundle: %v", err)
+	}
+	for i, res := range result.Results {
+		if res.Error != "" || res.Failed || res.GasUsed.ToInt().Uint64() != 21000 {
+			t.Errorf("transaction %d: unexpected result: %+v", i, res)
+		}
+	}
+	if diff := result.StateDiff[recipient]; diff == nil || diff.Balance == nil || diff.Balance.To.(*hexutil.Big).ToInt().Int64() != 2000 {
+		t.Errorf("recipient diff mismatch: %+v", diff)
+	}
+}
+
+// Tests that gas bought by a bundle transaction which subsequently fails to be
+// applied is returned to the block's gas pool.
+func TestSimulateBundleGasRefund(t *testing.T) {
+	var (
+		db, _ = ruedb.NewMemDatabase()
+		gspec = &core.Genesis{
+			Config:   params.TestChainConfig,
+			GasLimit: 30000,
+			Alloc:    core.GenesisAlloc{testBank: {Balance: big.NewInt(1000000)}},
+		}
+		_             = gspec.MustCommit(db)
+		blockchain, _ = core.NewBlockChain(db, nil, gspec.Config, ruehash.NewFaker(), vm.Config{})
+		api           = NewPrivateDebugAPI(gspec.Config, &Ruereum{blockchain: blockchain})
+
+		recipient = common.Address{0x01}
+	)
+	defer blockchain.Stop()
+
+	// Buy less gas than the intrinsic cost, which fails after purchase, then use
+	// more gas than would be left in the pool if it wasn't refunded
+	calls := []BundleCall{
+		{CallArgs: rueapi.CallArgs{From: testBank, To: &recipient, Gas: hexutil.Big(*big.NewInt(20000))}},
+		{CallArgs: rueapi.CallArgs{From: testBank, To: &recipient, Gas: hexutil.Big(*big.NewInt(21000))}},
+	}
+	result, err := api.SimulateBundle(context.Background(), calls, rpc.LatestBlockNumber, nil)
+	if err != nil {
+		t.Fatalf("failed to simulate bundle: %v", err)
+	}
+	if result.Results[0].Error == "" {
+		t.Errorf("intrinsic gas shortfall not reported")
+	}
+	if res := result.Results[1]; res.Error != "" || res.GasUsed.ToInt().Uint64() != 21000 {
+		t.Errorf("transaction after failure: unexpected result: %+v", res)
+	}
+}
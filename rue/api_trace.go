@@ -0,0 +1,439 @@
+// Copyright 2018 The go-ruereum Authors
+// This file is part of the go-ruereum library.
+//
+// The go-ruereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ruereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ruereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/Rue-Foundation/go-rue/common"
+	"github.com/Rue-Foundation/go-rue/common/hexutil"
+	"github.com/Rue-Foundation/go-rue/consensus/ruehash"
+	"github.com/Rue-Foundation/go-rue/core"
+	"github.com/Rue-Foundation/go-rue/core/state"
+	"github.com/Rue-Foundation/go-rue/core/types"
+	"github.com/Rue-Foundation/go-rue/core/vm"
+	"github.com/Rue-Foundation/go-rue/params"
+	"github.com/Rue-Foundation/go-rue/rpc"
+	"github.com/Rue-Foundation/go-rue/rue/tracers"
+)
+
+const (
+	// flatCallTracer is the native tracer producing Parity style call traces.
+	flatCallTracer = "flatCallTracer"
+
+	// vmTracer is the native tracer producing Parity style vm traces.
+	vmTracer = "vmTracer"
+
+	// maxFilterBlocks is the maximum number of blocks a single trace filter may
+	// reexecute, to prevent an RPC caller from replaying the entire chain.
+	maxFilterBlocks = 1000
+)
+
+var (
+	// errNoFilterRange is returned if a trace filter's block range is inverted.
+	errNoFilterRange = errors.New("invalid block range")
+
+	// errFilterRangeTooLarge is returned if a trace filter spans more blocks than
+	// allowed to be reexecuted in one go.
+	errFilterRangeTooLarge = fmt.Errorf("block range exceeds %d blocks", maxFilterBlocks)
+)
+
+// ParityTrace is a single flat call or reward trace along with its position in
+// the chain, in the Parity trace format.
+type ParityTrace struct {
+	tracers.FlatCallFrame
+	BlockHash           common.Hash  `json:"blockHash"`
+	BlockNumber         uint64       `json:"blockNumber"`
+	TransactionHash     *common.Hash `json:"transactionHash"`
+	TransactionPosition *uint64      `json:"transactionPosition"`
+}
+
+// TraceFilterArgs are the criteria to filter the traces of a range of blocks by.
+type TraceFilterArgs struct {
+	FromBlock   *rpc.BlockNumber `json:"fromBlock"`   // First block to trace, latest if unset
+	ToBlock     *rpc.BlockNumber `json:"toBlock"`     // Last block to trace, latest if unset
+	FromAddress []common.Address `json:"fromAddress"` // Senders to filter by, any if empty
+	ToAddress   []common.Address `json:"toAddress"`   // Recipients to filter by, any if empty
+	After       *uint64          `json:"after"`       // Number of matching traces to skip
+	Count       *uint64          `json:"count"`       // Maximum number of traces to return, unlimited if zero
+}
+
+// TraceResults is the outcome of replaying a transaction with the requested
+// Parity trace modes, leaving the results of unrequested modes empty.
+type TraceResults struct {
+	Output    hexutil.Bytes                         `json:"output"`
+	StateDiff map[common.Address]*ParityAccountDiff `json:"stateDiff"`
+	Trace     []*tracers.FlatCallFrame              `json:"trace"`
+	VMTrace   json.RawMessage                       `json:"vmTrace"`
+}
+
+// ParityAccountDiff contains the changes made to a single account in the Parity
+// state diff format. Each field is either "=" if unchanged, or an object keyed
+// by "+", "-" or "*" for created, deleted and modified values respectively.
+type ParityAccountDiff struct {
+	Balance interface{}                 `json:"balance"`
+	Nonce   interface{}                 `json:"nonce"`
+	Code    interface{}                 `json:"code"`
+	Storage map[common.Hash]interface{} `json:"storage"`
+}
+
+// PrivateTraceAPI is the collection of Parity compatible tracing APIs exposed
+// over the private tracing endpoint.
+type PrivateTraceAPI struct {
+	debug *PrivateDebugAPI
+}
+
+// NewPrivateTraceAPI creates a new API definition for the Parity compatible
+// trace methods of the Ruereum service.
+func NewPrivateTraceAPI(config *params.ChainConfig, rue *Ruereum) *PrivateTraceAPI {
+	return &PrivateTraceAPI{debug: NewPrivateDebugAPI(config, rue)}
+}
+
+// Block returns the flat call traces of all the transactions in a block, along
+// with the block and uncle reward traces.
+func (api *PrivateTraceAPI) Block(ctx context.Context, number rpc.BlockNumber) ([]*ParityTrace, error) {
+	block := api.blockByNumber(number)
+	if block == nil {
+		return nil, fmt.Errorf("block #%d not found", number)
+	}
+	return api.traceBlock(ctx, block)
+}
+
+// Transaction returns the flat call traces of a single transaction.
+func (api *PrivateTraceAPI) Transaction(ctx context.Context, hash common.Hash) ([]*ParityTrace, error) {
+	tx, blockHash, blockNumber, index := core.GetTransaction(api.debug.rue.ChainDb(), hash)
+	if tx == nil {
+		return nil, fmt.Errorf("transaction %x not found", hash)
+	}
+	msg, vmctx, statedb, err := api.debug.computeTxEnv(blockHash, int(index), defaultTraceReexec)
+	if err != nil {
+		return nil, err
+	}
+	frames, err := api.traceTx(ctx, msg, vmctx, statedb)
+	if err != nil {
+		return nil, err
+	}
+	traces := make([]*ParityTrace, len(frames))
+	for i, frame := range frames {
+		traces[i] = &ParityTrace{
+			FlatCallFrame:       *frame,
+			BlockHash:           blockHash,
+			BlockNumber:         blockNumber,
+			TransactionHash:     &hash,
+			TransactionPosition: &index,
+		}
+	}
+	return traces, nil
+}
+
+// Filter returns the flat call and reward traces of a range of blocks, matching
+// the given sender and recipient addresses.
+func (api *PrivateTraceAPI) Filter(ctx context.Context, args TraceFilterArgs) ([]*ParityTrace, error) {
+	// Resolve the block range to trace
+	from, to := api.debug.rue.blockchain.CurrentBlock().NumberU64(), api.debug.rue.blockchain.CurrentBlock().NumberU64()
+	if args.FromBlock != nil && *args.FromBlock >= 0 {
+		from = uint64(*args.FromBlock)
+	}
+	if args.ToBlock != nil && *args.ToBlock >= 0 {
+		to = uint64(*args.ToBlock)
+	}
+	if from > to {
+		return nil, errNoFilterRange
+	}
+	if to-from >= maxFilterBlocks {
+		return nil, errFilterRangeTooLarge
+	}
+	// Trace the blocks one by one, gathering the matching traces
+	var (
+		traces  = []*ParityTrace{}
+		skipped uint64
+	)
+	for number := from; number <= to; number++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		block := api.debug.rue.blockchain.GetBlockByNumber(number)
+		if block == nil {
+			return nil, fmt.Errorf("block #%d not found", number)
+		}
+		blockTraces, err := api.traceBlock(ctx, block)
+		if err != nil {
+			return nil, err
+		}
+		for _, trace := range blockTraces {
+			if !args.matches(trace) {
+				continue
+			}
+			if args.After != nil && skipped < *args.After {
+				skipped++
+				continue
+			}
+			traces = append(traces, trace)
+			if args.Count != nil && *args.Count > 0 && uint64(len(traces)) >= *args.Count {
+				return traces, nil
+			}
+		}
+	}
+	return traces, nil
+}
+
+// ReplayTransaction reexecutes a transaction, returning its output along with
+// the results of the requested trace modes: "trace", "vmTrace" and "stateDiff".
+func (api *PrivateTraceAPI) ReplayTransaction(ctx context.Context, hash common.Hash, modes []string) (*TraceResults, error) {
+	var trace, vmTrace, stateDiff bool
+	for _, mode := range modes {
+		switch mode {
+		case "trace":
+			trace = true
+		case "vmTrace":
+			vmTrace = true
+		case "stateDiff":
+			stateDiff = true
+		default:
+			return nil, fmt.Errorf("unknown trace mode %q", mode)
+		}
+	}
+	tx, blockHash, _, index := core.GetTransaction(api.debug.rue.ChainDb(), hash)
+	if tx == nil {
+		return nil, fmt.Errorf("transaction %x not found", hash)
+	}
+	msg, vmctx, statedb, err := api.debug.computeTxEnv(blockHash, int(index), defaultTraceReexec)
+	if err != nil {
+		return nil, err
+	}
+	results := new(TraceResults)
+	if trace {
+		if results.Trace, err = api.traceTx(ctx, msg, vmctx, statedb.Copy()); err != nil {
+			return nil, err
+		}
+	}
+	if vmTrace {
+		tracer := vmTracer
+		res, err := api.debug.traceTx(ctx, msg, vmctx, statedb.Copy(), &TraceConfig{Tracer: &tracer})
+		if err != nil {
+			return nil, err
+		}
+		results.VMTrace = res.(json.RawMessage)
+	}
+	// Execute the transaction without tracing to retrieve the output and changes
+	pre := statedb.Copy()
+
+	vmenv := vm.NewEVM(vmctx, statedb, api.debug.config, vm.Config{})
+	ret, _, _, err := core.ApplyMessage(vmenv, msg, new(core.GasPool).AddGas(msg.Gas()))
+	if err != nil {
+		return nil, fmt.Errorf("tracing failed: %v", err)
+	}
+	results.Output = ret
+
+	if stateDiff {
+		dirties := make(map[common.Address]map[common.Hash]struct{})
+		for addr, keys := range statedb.Dirties() {
+			dirties[addr] = make(map[common.Hash]struct{})
+			for _, key := range keys {
+				dirties[addr][key] = struct{}{}
+			}
+		}
+		statedb.Finalise(true)
+		results.StateDiff = parityStateDiff(pre, statedb, dirties)
+	}
+	return results, nil
+}
+
+// blockByNumber retrieves a block by number, resolving the pending and latest
+// block aliases.
+func (api *PrivateTraceAPI) blockByNumber(number rpc.BlockNumber) *types.Block {
+	switch number {
+	case rpc.PendingBlockNumber:
+		return api.debug.rue.miner.PendingBlock()
+	case rpc.LatestBlockNumber:
+		return api.debug.rue.blockchain.CurrentBlock()
+	default:
+		return api.debug.rue.blockchain.GetBlockByNumber(uint64(number))
+	}
+}
+
+// traceBlock traces all the transactions in a block with the flat call tracer,
+// returning their traces followed by the reward traces.
+func (api *PrivateTraceAPI) traceBlock(ctx context.Context, block *types.Block) ([]*ParityTrace, error) {
+	// The genesis block has neither transactions, nor rewards
+	traces := []*ParityTrace{}
+	if block.NumberU64() == 0 {
+		return traces, nil
+	}
+	tracer := flatCallTracer
+	results, err := api.debug.traceBlock(ctx, block, &TraceConfig{Tracer: &tracer})
+	if err != nil {
+		return nil, err
+	}
+	var (
+		hash   = block.Hash()
+		number = block.NumberU64()
+	)
+	for i, tx := range block.Transactions() {
+		if results[i].Error != "" {
+			return nil, fmt.Errorf("tracing transaction %x failed: %s", tx.Hash(), results[i].Error)
+		}
+		var frames []*tracers.FlatCallFrame
+		if err := json.Unmarshal(results[i].Result.(json.RawMessage), &frames); err != nil {
+			return nil, err
+		}
+		txHash, txIndex := tx.Hash(), uint64(i)
+		for _, frame := range frames {
+			traces = append(traces, &ParityTrace{
+				FlatCallFrame:       *frame,
+				BlockHash:           hash,
+				BlockNumber:         number,
+				TransactionHash:     &txHash,
+				TransactionPosition: &txIndex,
+			})
+		}
+	}
+	return append(traces, api.rewardTraces(block)...), nil
+}
+
+// traceTx traces a single message with the flat call tracer.
+func (api *PrivateTraceAPI) traceTx(ctx context.Context, msg core.Message, vmctx vm.Context, statedb *state.StateDB) ([]*tracers.FlatCallFrame, error) {
+	tracer := flatCallTracer
+	res, err := api.debug.traceTx(ctx, msg, vmctx, statedb, &TraceConfig{Tracer: &tracer})
+	if err != nil {
+		return nil, err
+	}
+	var frames []*tracers.FlatCallFrame
+	if err := json.Unmarshal(res.(json.RawMessage), &frames); err != nil {
+		return nil, err
+	}
+	return frames, nil
+}
+
+// rewardTraces assembles the traces of the block and uncle rewards credited by
+// the ruehash consensus engine. Other engines don't issue rewards.
+func (api *PrivateTraceAPI) rewardTraces(block *types.Block) []*ParityTrace {
+	if _, ok := api.debug.rue.engine.(*ruehash.Ruehash); !ok {
+		return nil
+	}
+	reward, uncleRewards := ruehash.BlockRewards(api.debug.config, block.Header(), block.Uncles())
+
+	traces := []*ParityTrace{rewardTrace(block, block.Coinbase(), "block", reward)}
+	for i, uncle := range block.Uncles() {
+		traces = append(traces, rewardTrace(block, uncle.Coinbase, "uncle", uncleRewards[i]))
+	}
+	return traces
+}
+
+// rewardTrace creates a single reward trace of the given block.
+func rewardTrace(block *types.Block, author common.Address, kind string, value *big.Int) *ParityTrace {
+	return &ParityTrace{
+		FlatCallFrame: tracers.FlatCallFrame{
+			Action: tracers.FlatCallAction{
+				Author:     hexutil.Encode(author.Bytes()),
+				RewardType: kind,
+				Value:      hexutil.EncodeBig(value),
+			},
+			TraceAddress: []int{},
+			Type:         "reward",
+		},
+		BlockHash:   block.Hash(),
+		BlockNumber: block.NumberU64(),
+	}
+}
+
+// matches checks whether a trace satisfies the address criteria of the filter.
+func (args *TraceFilterArgs) matches(trace *ParityTrace) bool {
+	var from, to string
+	switch trace.Type {
+	case "call":
+		from, to = trace.Action.From, trace.Action.To
+	case "create":
+		from = trace.Action.From
+		if trace.Result != nil {
+			to = trace.Result.Address
+		}
+	case "suicide":
+		from, to = trace.Action.Address, trace.Action.RefundAddress
+	case "reward":
+		to = trace.Action.Author
+	}
+	return matchAddress(args.FromAddress, from) && matchAddress(args.ToAddress, to)
+}
+
+// matchAddress checks whether a hex encoded address is contained within a list
+// of addresses, an empty list matching anything.
+func matchAddress(addrs []common.Address, addr string) bool {
+	if len(addrs) == 0 {
+		return true
+	}
+	if addr == "" {
+		return false
+	}
+	for _, want := range addrs {
+		if want == common.HexToAddress(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// parityStateDiff converts the changes made to the given accounts between two
+// states into the Parity state diff format.
+func parityStateDiff(pre, post *state.StateDB, dirties map[common.Address]map[common.Hash]struct{}) map[common.Address]*ParityAccountDiff {
+	diffs := make(map[common.Address]*ParityAccountDiff)
+	for addr, diff := range diffState(pre, post, dirties) {
+		var marker string
+		switch {
+		case !pre.Exist(addr):
+			marker = "+"
+		case !post.Exist(addr):
+			marker = "-"
+		default:
+			marker = "*"
+		}
+		var (
+			balance = &Diff{From: (*hexutil.Big)(pre.GetBalance(addr)), To: (*hexutil.Big)(post.GetBalance(addr))}
+			nonce   = &Diff{From: hexutil.Uint64(pre.GetNonce(addr)), To: hexutil.Uint64(post.GetNonce(addr))}
+			code    = &Diff{From: hexutil.Bytes(pre.GetCode(addr)), To: hexutil.Bytes(post.GetCode(addr))}
+		)
+		account := &ParityAccountDiff{
+			Balance: parityDiff(marker, balance, diff.Balance != nil),
+			Nonce:   parityDiff(marker, nonce, diff.Nonce != nil),
+			Code:    parityDiff(marker, code, diff.Code != nil),
+			Storage: make(map[common.Hash]interface{}),
+		}
+		for key, slot := range diff.Storage {
+			account.Storage[key] = parityDiff(marker, slot, true)
+		}
+		diffs[addr] = account
+	}
+	return diffs
+}
+
+// parityDiff converts a single value change into the Parity diff format.
+func parityDiff(marker string, diff *Diff, changed bool) interface{} {
+	switch marker {
+	case "+":
+		return map[string]interface{}{marker: diff.To}
+	case "-":
+		return map[string]interface{}{marker: diff.From}
+	}
+	if !changed {
+		return "="
+	}
+	return map[string]interface{}{marker: diff}
+}
@@ -0,0 +1,147 @@
+// Copyright 2018 The go-ruereum Authors
+// This file is part of the go-ruereum library.
+//
+// The go-ruereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ruereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ruereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rue
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/Rue-Foundation/go-rue/common"
+	"github.com/Rue-Foundation/go-rue/common/hexutil"
+	"github.com/Rue-Foundation/go-rue/consensus/ruehash"
+	"github.com/Rue-Foundation/go-rue/core"
+	"github.com/Rue-Foundation/go-rue/core/types"
+	"github.com/Rue-Foundation/go-rue/core/vm"
+	"github.com/Rue-Foundation/go-rue/crypto"
+	"github.com/Rue-Foundation/go-rue/params"
+	"github.com/Rue-Foundation/go-rue/rpc"
+	"github.com/Rue-Foundation/go-rue/ruedb"
+)
+
+// Tests that the Parity style trace APIs report the flat call traces and the
+// reward traces of a block, filter them, and replay individual transactions.
+func TestTraceAPI(t *testing.T) {
+	var (
+		db, _ = ruedb.NewMemDatabase()
+		gspec = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  core.GenesisAlloc{testBank: {Balance: big.NewInt(1000000)}},
+		}
+		genesis = gspec.MustCommit(db)
+		engine  = ruehash.NewFaker()
+
+		coinbase  = common.Address{0xc0}
+		recipient = common.Address{0x01}
+		contract  = crypto.CreateAddress(testBank, 1)
+		signer    = types.HomesteadSigner{}
+	)
+	// Create a block with a plain transfer and a contract storing a value on creation
+	var transfer, create *types.Transaction
+	blocks, _ := core.GenerateChain(gspec.Config, genesis, engine, db, 1, func(i int, block *core.BlockGen) {
+		block.SetCoinbase(coinbase)
+
+		transfer, _ = types.SignTx(types.NewTransaction(0, recipient, big.NewInt(1000), big.NewInt(21000), new(big.Int), nil), signer, testBankKey)
+		block.AddTx(transfer)
+
+		code := []byte{byte(vm.PUSH1), 0x01, byte(vm.PUSH1), 0x00, byte(vm.SSTORE), byte(vm.STOP)}
+		create, _ = types.SignTx(types.NewContractCreation(1, new(big.Int), big.NewInt(100000), new(big.Int), code), signer, testBankKey)
+		block.AddTx(create)
+	})
+	blockchain, _ := core.NewBlockChain(db, nil, gspec.Config, engine, vm.Config{})
+	defer blockchain.Stop()
+
+	if _, err := blockchain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	api := NewPrivateTraceAPI(gspec.Config, &Ruereum{blockchain: blockchain, engine: engine, chainDb: db})
+
+	// Check the traces of the entire block, rewards included
+	traces, err := api.Block(context.Background(), rpc.BlockNumber(1))
+	if err != nil {
+		t.Fatalf("failed to trace block: %v", err)
+	}
+	if len(traces) != 3 {
+		t.Fatalf("trace count mismatch: have %d, want %d", len(traces), 3)
+	}
+	if trace := traces[0]; trace.Type != "call" || trace.Action.To != hexutil.Encode(recipient.Bytes()) || *trace.TransactionHash != transfer.Hash() {
+		t.Errorf("transfer trace mismatch: %+v", trace)
+	}
+	if trace := traces[1]; trace.Type != "create" || trace.Result == nil || trace.Result.Address != hexutil.Encode(contract.Bytes()) || *trace.TransactionPosition != 1 {
+		t.Errorf("creation trace mismatch: %+v", trace)
+	}
+	if trace := traces[2]; trace.Type != "reward" || trace.Action.Author != hexutil.Encode(coinbase.Bytes()) || trace.Action.Value != hexutil.EncodeBig(ruehash.ByzantiumBlockReward) || trace.TransactionHash != nil {
+		t.Errorf("reward trace mismatch: %+v", trace)
+	}
+	// Check that a single transaction can be traced
+	if traces, err = api.Transaction(context.Background(), create.Hash()); err != nil {
+		t.Fatalf("failed to trace transaction: %v", err)
+	}
+	if len(traces) != 1 || traces[0].Type != "create" || traces[0].BlockNumber != 1 {
+		t.Errorf("transaction traces mismatch: %+v", traces)
+	}
+	// Check that traces can be filtered by address
+	from, to := rpc.BlockNumber(0), rpc.BlockNumber(1)
+	if traces, err = api.Filter(context.Background(), TraceFilterArgs{FromBlock: &from, ToBlock: &to, ToAddress: []common.Address{recipient, coinbase}}); err != nil {
+		t.Fatalf("failed to filter traces: %v", err)
+	}
+	if len(traces) != 2 || traces[0].Type != "call" || traces[1].Type != "reward" {
+		t.Errorf("filtered traces mismatch: %+v", traces)
+	}
+	count := uint64(1)
+	if traces, err = api.Filter(context.Background(), TraceFilterArgs{FromBlock: &from, ToBlock: &to, FromAddress: []common.Address{testBank}, Count: &count}); err != nil {
+		t.Fatalf("failed to filter traces: %v", err)
+	}
+	if len(traces) != 1 || traces[0].Type != "call" {
+		t.Errorf("limited traces mismatch: %+v", traces)
+	}
+	count = 0
+	if traces, err = api.Filter(context.Background(), TraceFilterArgs{FromBlock: &from, ToBlock: &to, FromAddress: []common.Address{testBank}, Count: &count}); err != nil {
+		t.Fatalf("failed to filter traces: %v", err)
+	}
+	if len(traces) != 2 {
+		t.Errorf("unlimited traces mismatch: %+v", traces)
+	}
+	far := rpc.BlockNumber(maxFilterBlocks)
+	if _, err := api.Filter(context.Background(), TraceFilterArgs{FromBlock: &from, ToBlock: &far}); err != errFilterRangeTooLarge {
+		t.Errorf("oversized range error mismatch: have %v, want %v", err, errFilterRangeTooLarge)
+	}
+	// Check that transactions can be replayed with all the trace modes
+	result, err := api.ReplayTransaction(context.Background(), create.Hash(), []string{"trace", "vmTrace", "stateDiff"})
+	if err != nil {
+		t.Fatalf("failed to replay transaction: %v", err)
+	}
+	if len(result.Trace) != 1 || result.Trace[0].Type != "create" {
+		t.Errorf("replayed call trace mismatch: %+v", result.Trace)
+	}
+	if len(result.VMTrace) == 0 {
+		t.Errorf("missing vm trace")
+	}
+	diff := result.StateDiff[contract]
+	if diff == nil {
+		t.Fatalf("missing state diff of created contract")
+	}
+	if slot, ok := diff.Storage[common.Hash{}].(map[string]interface{}); !ok || slot["+"] != common.BigToHash(big.NewInt(1)) {
+		t.Errorf("created storage diff mismatch: %+v", diff.Storage)
+	}
+	if diff := result.StateDiff[testBank]; diff == nil || diff.Balance != "=" || diff.Nonce == "=" {
+		t.Errorf("sender state diff mismatch: %+v", diff)
+	}
+	if _, err := api.ReplayTransaction(context.Background(), create.Hash(), []string{"unknown"}); err == nil {
+		t.Errorf("unknown trace mode accepted")
+	}
+}
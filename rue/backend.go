@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"path/filepath"
 	"runtime"
 	"sync"
 	"sync/atomic"
@@ -33,6 +34,7 @@ import (
 	"github.com/Rue-Foundation/go-rue/consensus/ruehash"
 	"github.com/Rue-Foundation/go-rue/core"
 	"github.com/Rue-Foundation/go-rue/core/bloombits"
+	"github.com/Rue-Foundation/go-rue/core/state/pruner"
 	"github.com/Rue-Foundation/go-rue/core/types"
 	"github.com/Rue-Foundation/go-rue/core/vm"
 	"github.com/Rue-Foundation/go-rue/rue/downloader"
@@ -81,6 +83,8 @@ type Ruereum struct {
 
 	bloomRequests chan chan *bloombits.Retrieval // Channel receiving bloom data retrieval requests
 	bloomIndexer  *core.ChainIndexer             // Bloom indexer operating during block imports
+	logIndexer    *core.ChainIndexer             // Log indexer operating during block imports (nil if disabled)
+	addrIndexer   *core.ChainIndexer             // Address transaction indexer operating during block imports (nil if disabled)
 
 	ApiBackend *EthApiBackend
 
@@ -112,7 +116,14 @@ func New(ctx *node.ServiceContext, config *Config) (*Ruereum, error) {
 	if err != nil {
 		return nil, err
 	}
+	// Finish any interrupted state pruning before new state gets written
+	if err := pruner.RecoverPruning(ctx.ResolvePath(pruner.BloomFileName), chainDb); err != nil {
+		return nil, err
+	}
 	stopDbUpgrade := upgradeDeduplicateData(chainDb)
+	if chainDb, err = CreateFreezerDB(ctx, config, chainDb); err != nil {
+		return nil, err
+	}
 	chainConfig, genesisHash, genesisErr := core.SetupGenesisBlock(chainDb, config.Genesis)
 	if _, ok := genesisErr.(*params.ConfigCompatError); genesisErr != nil && !ok {
 		return nil, genesisErr
@@ -135,6 +146,12 @@ func New(ctx *node.ServiceContext, config *Config) (*Ruereum, error) {
 		bloomIndexer:   NewBloomIndexer(chainDb, params.BloomBitsBlocks),
 	}
 
+	if config.LogIndex {
+		rue.logIndexer = NewLogIndexer(chainDb, params.BloomBitsBlocks)
+	}
+	if config.AddressIndex {
+		rue.addrIndexer = NewAddrIndexer(chainDb, chainConfig, params.BloomBitsBlocks)
+	}
 	log.Info("Initialising Ruereum protocol", "versions", ProtocolVersions, "network", config.NetworkId)
 
 	if !config.SkipBcVersionCheck {
@@ -145,8 +162,11 @@ func New(ctx *node.ServiceContext, config *Config) (*Ruereum, error) {
 		core.WriteBlockChainVersion(chainDb, core.BlockChainVersion)
 	}
 
-	vmConfig := vm.Config{EnablePreimageRecording: config.EnablePreimageRecording}
-	rue.blockchain, err = core.NewBlockChain(chainDb, rue.chainConfig, rue.engine, vmConfig)
+	var (
+		vmConfig    = vm.Config{EnablePreimageRecording: config.EnablePreimageRecording}
+		cacheConfig = &core.CacheConfig{Disabled: config.NoPruning, TrieNodeLimit: config.TrieCache, TrieTimeLimit: config.TrieTimeout}
+	)
+	rue.blockchain, err = core.NewBlockChain(chainDb, cacheConfig, rue.chainConfig, rue.engine, vmConfig)
 	if err != nil {
 		return nil, err
 	}
@@ -157,10 +177,19 @@ func New(ctx *node.ServiceContext, config *Config) (*Ruereum, error) {
 		core.WriteChainConfig(chainDb, genesisHash, chainConfig)
 	}
 	rue.bloomIndexer.Start(rue.blockchain)
+	if rue.logIndexer != nil {
+		rue.logIndexer.Start(rue.blockchain)
+	}
+	if rue.addrIndexer != nil {
+		rue.addrIndexer.Start(rue.blockchain)
+	}
 
 	if config.TxPool.Journal != "" {
 		config.TxPool.Journal = ctx.ResolvePath(config.TxPool.Journal)
 	}
+	if config.TxPool.RemoteJournal != "" {
+		config.TxPool.RemoteJournal = ctx.ResolvePath(config.TxPool.RemoteJournal)
+	}
 	rue.txPool = core.NewTxPool(config.TxPool, rue.chainConfig, rue.blockchain)
 
 	if rue.protocolManager, err = NewProtocolManager(rue.chainConfig, config.SyncMode, config.NetworkId, rue.eventMux, rue.txPool, rue.engine, rue.blockchain, chainDb); err != nil {
@@ -208,6 +237,27 @@ func CreateDB(ctx *node.ServiceContext, config *Config, name string) (ruedb.Data
 	return db, nil
 }
 
+// CreateFreezerDB attaches the ancient store to the chain database, moving the
+// immutable chain segment out of leveldb. Ephemeral databases are left as is.
+func CreateFreezerDB(ctx *node.ServiceContext, config *Config, db ruedb.Database) (ruedb.Database, error) {
+	chaindata := ctx.ResolvePath("chaindata")
+	if chaindata == "" {
+		return db, nil
+	}
+	freezer := config.DatabaseFreezer
+	switch {
+	case freezer == "":
+		freezer = filepath.Join(chaindata, "ancient")
+	case !filepath.IsAbs(freezer):
+		freezer = ctx.ResolvePath(freezer)
+	}
+	threshold := config.FreezerThreshold
+	if threshold == 0 {
+		threshold = core.ImmutabilityThreshold
+	}
+	return core.NewDatabaseWithFreezer(db, freezer, "rue/db/chaindata/", threshold, true)
+}
+
 // CreateConsensusEngine creates the required type of consensus engine instance for an Ruereum service
 func CreateConsensusEngine(ctx *node.ServiceContext, config *ruehash.Config, chainConfig *params.ChainConfig, db ruedb.Database) consensus.Engine {
 	// If proof-of-authority is requested, set it up
@@ -287,6 +337,10 @@ func (s *Ruereum) APIs() []rpc.API {
 			Namespace: "debug",
 			Version:   "1.0",
 			Service:   NewPrivateDebugAPI(s.chainConfig, s),
+		}, {
+			Namespace: "trace",
+			Version:   "1.0",
+			Service:   NewPrivateTraceAPI(s.chainConfig, s),
 		}, {
 			Namespace: "net",
 			Version:   "1.0",
@@ -413,6 +467,12 @@ func (s *Ruereum) Stop() error {
 		s.stopDbUpgrade()
 	}
 	s.bloomIndexer.Close()
+	if s.logIndexer != nil {
+		s.logIndexer.Close()
+	}
+	if s.addrIndexer != nil {
+		s.addrIndexer.Close()
+	}
 	s.blockchain.Stop()
 	s.protocolManager.Stop()
 	if s.lesServer != nil {
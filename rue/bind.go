@@ -66,7 +66,7 @@ func (b *ContractBackend) PendingCodeAt(ctx context.Context, contract common.Add
 // call with the specified data as the input. The pending flag requests execution
 // against the pending block, not the stable head of the chain.
 func (b *ContractBackend) CallContract(ctx context.Context, msg ruereum.CallMsg, blockNum *big.Int) ([]byte, error) {
-	out, err := b.bcapi.Call(ctx, toCallArgs(msg), toBlockNumber(blockNum))
+	out, err := b.bcapi.Call(ctx, toCallArgs(msg), toBlockNumber(blockNum), nil)
 	return out, err
 }
 
@@ -74,7 +74,7 @@ func (b *ContractBackend) CallContract(ctx context.Context, msg ruereum.CallMsg,
 // call with the specified data as the input. The pending flag requests execution
 // against the pending block, not the stable head of the chain.
 func (b *ContractBackend) PendingCallContract(ctx context.Context, msg ruereum.CallMsg) ([]byte, error) {
-	out, err := b.bcapi.Call(ctx, toCallArgs(msg), rpc.PendingBlockNumber)
+	out, err := b.bcapi.Call(ctx, toCallArgs(msg), rpc.PendingBlockNumber, nil)
 	return out, err
 }
 
@@ -125,7 +125,7 @@ func (b *ContractBackend) SuggestGasPrice(ctx context.Context) (*big.Int, error)
 // requirement as other transactions may be added or removed by miners, but it
 // should provide a basis for setting a reasonable default.
 func (b *ContractBackend) EstimateGas(ctx context.Context, msg ruereum.CallMsg) (*big.Int, error) {
-	out, err := b.bcapi.EstimateGas(ctx, toCallArgs(msg))
+	out, err := b.bcapi.EstimateGas(ctx, toCallArgs(msg), nil)
 	return out.ToInt(), err
 }
 
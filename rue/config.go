@@ -22,6 +22,7 @@ import (
 	"os/user"
 	"path/filepath"
 	"runtime"
+	"time"
 
 	"github.com/Rue-Foundation/go-rue/common"
 	"github.com/Rue-Foundation/go-rue/common/hexutil"
@@ -45,8 +46,12 @@ var DefaultConfig = Config{
 	NetworkId:     1,
 	LightPeers:    20,
 	DatabaseCache: 128,
+	TrieCache:     256,
+	TrieTimeout:   5 * time.Minute,
 	GasPrice:      big.NewInt(18 * params.Shannon),
 
+	FreezerThreshold: core.ImmutabilityThreshold,
+
 	TxPool: core.DefaultTxPoolConfig,
 	GPO: gasprice.Config{
 		Blocks:     10,
@@ -78,6 +83,7 @@ type Config struct {
 	// Protocol options
 	NetworkId uint64 // Network ID to use for selecting peers to connect to
 	SyncMode  downloader.SyncMode
+	NoPruning bool
 
 	// Light client options
 	LightServ  int `toml:",omitempty"` // Maximum percentage of time allowed for serving LES requests
@@ -87,6 +93,12 @@ type Config struct {
 	SkipBcVersionCheck bool `toml:"-"`
 	DatabaseHandles    int  `toml:"-"`
 	DatabaseCache      int
+	TrieCache          int
+	TrieTimeout        time.Duration
+	DatabaseFreezer    string // Directory of the ancient store, chaindata/ancient if empty
+	FreezerThreshold   uint64 // Number of recent blocks kept out of the ancient store
+	LogIndex           bool   // Whether to maintain an address and topic index of all logs
+	AddressIndex       bool   // Whether to maintain an index of the transactions touching each address
 
 	// Mining-related options
 	Ruerbase    common.Address `toml:",omitempty"`
@@ -27,6 +27,7 @@ import (
 
 	"github.com/Rue-Foundation/go-rue/common"
 	"github.com/Rue-Foundation/go-rue/common/hexutil"
+	"github.com/Rue-Foundation/go-rue/core"
 	"github.com/Rue-Foundation/go-rue/core/types"
 	"github.com/Rue-Foundation/go-rue/ruedb"
 	"github.com/Rue-Foundation/go-rue/event"
@@ -163,6 +164,50 @@ func (api *PublicFilterAPI) NewPendingTransactions(ctx context.Context) (*rpc.Su
 	return rpcSub, nil
 }
 
+// DroppedTransaction is the notification of a transaction dropped from the
+// transaction pool without being included in a block.
+type DroppedTransaction struct {
+	Hash        common.Hash       `json:"hash"`
+	Reason      core.TxDropReason `json:"reason"`
+	Replacement *common.Hash      `json:"replacement,omitempty"`
+}
+
+// DroppedTransactions creates a subscription that is triggered each time a
+// transaction is dropped from the transaction pool, reporting why it was dropped
+// and, for replaced transactions, the hash of the replacing one.
+func (api *PublicFilterAPI) DroppedTransactions(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		drops := make(chan core.TxDropEvent)
+		droppedTxSub := api.events.SubscribeDroppedTxEvents(drops)
+
+		for {
+			select {
+			case ev := <-drops:
+				dropped := &DroppedTransaction{Hash: ev.Tx.Hash(), Reason: ev.Reason}
+				if ev.Replacement != (common.Hash{}) {
+					dropped.Replacement = &ev.Replacement
+				}
+				notifier.Notify(rpcSub.ID, dropped)
+			case <-rpcSub.Err():
+				droppedTxSub.Unsubscribe()
+				return
+			case <-notifier.Closed():
+				droppedTxSub.Unsubscribe()
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
 // NewBlockFilter creates a filter that fetches blocks that are imported into the chain.
 // It is part of the filter package since polling goes with rue_getFilterChanges.
 //
@@ -36,12 +36,15 @@ type Backend interface {
 	GetReceipts(ctx context.Context, blockHash common.Hash) (types.Receipts, error)
 
 	SubscribeTxPreEvent(chan<- core.TxPreEvent) event.Subscription
+	SubscribeTxDropEvent(chan<- core.TxDropEvent) event.Subscription
 	SubscribeChainEvent(ch chan<- core.ChainEvent) event.Subscription
 	SubscribeRemovedLogsEvent(ch chan<- core.RemovedLogsEvent) event.Subscription
 	SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscription
 
 	BloomStatus() (uint64, uint64)
 	ServiceFilter(ctx context.Context, session *bloombits.MatcherSession)
+
+	LogIndexStatus() (uint64, uint64)
 }
 
 // Filter can be used to retrieve and filter logs.
@@ -113,13 +116,26 @@ func (f *Filter) Logs(ctx context.Context) ([]*types.Log, error) {
 		logs []*types.Log
 		err  error
 	)
-	size, sections := f.backend.BloomStatus()
-	if indexed := sections * size; indexed > uint64(f.begin) {
+	size, sections := f.backend.LogIndexStatus()
+	if indexed := sections * size; indexed > uint64(f.begin) && f.selective() {
+		if indexed > end {
+			logs, err = f.logIndexLogs(ctx, size, end)
+		} else {
+			logs, err = f.logIndexLogs(ctx, size, indexed-1)
+		}
+		if err != nil {
+			return logs, err
+		}
+	}
+	size, sections = f.backend.BloomStatus()
+	if indexed := sections * size; indexed > uint64(f.begin) && uint64(f.begin) <= end {
+		var found []*types.Log
 		if indexed > end {
-			logs, err = f.indexedLogs(ctx, end)
+			found, err = f.indexedLogs(ctx, end)
 		} else {
-			logs, err = f.indexedLogs(ctx, indexed-1)
+			found, err = f.indexedLogs(ctx, indexed-1)
 		}
+		logs = append(logs, found...)
 		if err != nil {
 			return logs, err
 		}
@@ -176,6 +192,110 @@ func (f *Filter) indexedLogs(ctx context.Context, end uint64) ([]*types.Log, err
 	}
 }
 
+// selective reports whether the filter restricts the logs by address or topic,
+// making it eligible for resolution via the log index.
+func (f *Filter) selective() bool {
+	if len(f.addresses) > 0 {
+		return true
+	}
+	for _, topics := range f.topics {
+		if len(topics) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// logIndexLogs returns the logs matching the filter criteria based on the local
+// address and topic log index.
+func (f *Filter) logIndexLogs(ctx context.Context, size uint64, end uint64) ([]*types.Log, error) {
+	var logs []*types.Log
+
+	for section := uint64(f.begin) / size; section*size <= end; section++ {
+		if err := ctx.Err(); err != nil {
+			return logs, err
+		}
+		// Resolve the blocks containing candidate logs within the section
+		head := core.GetCanonicalHash(f.db, (section+1)*size-1)
+
+		positions, err := f.logIndexPositions(section, head)
+		if err != nil {
+			return logs, err
+		}
+		blocks := make(map[uint64]struct{})
+		for pos := range positions {
+			blocks[pos.Block] = struct{}{}
+		}
+		last := (section+1)*size - 1
+		if last > end {
+			last = end
+		}
+		// Retrieve the candidate blocks and pull any truly matching logs
+		for number := uint64(f.begin); number <= last && len(blocks) > 0; number++ {
+			if _, ok := blocks[number]; !ok {
+				continue
+			}
+			delete(blocks, number)
+
+			header, err := f.backend.HeaderByNumber(ctx, rpc.BlockNumber(number))
+			if header == nil || err != nil {
+				return logs, err
+			}
+			found, err := f.checkMatches(ctx, header)
+			if err != nil {
+				return logs, err
+			}
+			logs = append(logs, found...)
+		}
+		f.begin = int64(last) + 1
+	}
+	return logs, nil
+}
+
+// logIndexPositions returns the positions of the logs within a log index section
+// that satisfy all the address and topic criteria of the filter.
+func (f *Filter) logIndexPositions(section uint64, head common.Hash) (map[core.LogPosition]struct{}, error) {
+	var keys [][][]byte
+	if len(f.addresses) > 0 {
+		clause := make([][]byte, len(f.addresses))
+		for i, address := range f.addresses {
+			clause[i] = core.LogIndexAddressKey(address)
+		}
+		keys = append(keys, clause)
+	}
+	for i, topics := range f.topics {
+		if len(topics) == 0 {
+			continue // empty rule set == wildcard
+		}
+		clause := make([][]byte, len(topics))
+		for j, topic := range topics {
+			clause[j] = core.LogIndexTopicKey(i, topic)
+		}
+		keys = append(keys, clause)
+	}
+	// Union the positions within each clause, intersecting across clauses
+	var matches map[core.LogPosition]struct{}
+	for _, clause := range keys {
+		union := make(map[core.LogPosition]struct{})
+		for _, key := range clause {
+			positions, err := core.GetLogIndex(f.db, key, section, head)
+			if err != nil {
+				return nil, err
+			}
+			for _, pos := range positions {
+				if _, ok := matches[pos]; matches == nil || ok {
+					union[pos] = struct{}{}
+				}
+			}
+		}
+		matches = union
+		if len(matches) == 0 {
+			break
+		}
+	}
+	return matches, nil
+}
+
 // indexedLogs returns the logs matching the filter criteria based on raw block
 // iteration and bloom matching.
 func (f *Filter) unindexedLogs(ctx context.Context, end uint64) ([]*types.Log, error) {
@@ -50,6 +50,9 @@ const (
 	PendingTransactionsSubscription
 	// BlocksSubscription queries hashes for blocks that are imported
 	BlocksSubscription
+	// DroppedTransactionsSubscription queries transactions dropped from the
+	// transaction pool without being included
+	DroppedTransactionsSubscription
 	// LastSubscription keeps track of the last index
 	LastIndexSubscription
 )
@@ -59,6 +62,8 @@ const (
 	// txChanSize is the size of channel listening to TxPreEvent.
 	// The number is referenced from the size of tx pool.
 	txChanSize = 4096
+	// dropChanSize is the size of channel listening to TxDropEvent.
+	dropChanSize = 4096
 	// rmLogsChanSize is the size of channel listening to RemovedLogsEvent.
 	rmLogsChanSize = 10
 	// logsChanSize is the size of channel listening to LogsEvent.
@@ -79,6 +84,7 @@ type subscription struct {
 	logs      chan []*types.Log
 	hashes    chan common.Hash
 	headers   chan *types.Header
+	drops     chan core.TxDropEvent
 	installed chan struct{} // closed when the filter is installed
 	err       chan error    // closed when the filter is uninstalled
 }
@@ -281,6 +287,23 @@ func (es *EventSystem) SubscribePendingTxEvents(hashes chan common.Hash) *Subscr
 	return es.subscribe(sub)
 }
 
+// SubscribeDroppedTxEvents creates a subscription that writes the transactions
+// dropped from the transaction pool, along with the reason of the drop.
+func (es *EventSystem) SubscribeDroppedTxEvents(drops chan core.TxDropEvent) *Subscription {
+	sub := &subscription{
+		id:        rpc.NewID(),
+		typ:       DroppedTransactionsSubscription,
+		created:   time.Now(),
+		logs:      make(chan []*types.Log),
+		hashes:    make(chan common.Hash),
+		headers:   make(chan *types.Header),
+		drops:     drops,
+		installed: make(chan struct{}),
+		err:       make(chan error),
+	}
+	return es.subscribe(sub)
+}
+
 type filterIndex map[Type]map[rpc.ID]*subscription
 
 // broadcast event to filters that match criteria.
@@ -319,6 +342,10 @@ func (es *EventSystem) broadcast(filters filterIndex, ev interface{}) {
 		for _, f := range filters[PendingTransactionsSubscription] {
 			f.hashes <- e.Tx.Hash()
 		}
+	case core.TxDropEvent:
+		for _, f := range filters[DroppedTransactionsSubscription] {
+			f.drops <- e
+		}
 	case core.ChainEvent:
 		for _, f := range filters[BlocksSubscription] {
 			f.headers <- e.Block.Header()
@@ -400,6 +427,9 @@ func (es *EventSystem) eventLoop() {
 		// Subscribe TxPreEvent form txpool
 		txCh  = make(chan core.TxPreEvent, txChanSize)
 		txSub = es.backend.SubscribeTxPreEvent(txCh)
+		// Subscribe TxDropEvent from txpool
+		dropCh  = make(chan core.TxDropEvent, dropChanSize)
+		dropSub = es.backend.SubscribeTxDropEvent(dropCh)
 		// Subscribe RemovedLogsEvent
 		rmLogsCh  = make(chan core.RemovedLogsEvent, rmLogsChanSize)
 		rmLogsSub = es.backend.SubscribeRemovedLogsEvent(rmLogsCh)
@@ -414,6 +444,7 @@ func (es *EventSystem) eventLoop() {
 	// Unsubscribe all events
 	defer sub.Unsubscribe()
 	defer txSub.Unsubscribe()
+	defer dropSub.Unsubscribe()
 	defer rmLogsSub.Unsubscribe()
 	defer logsSub.Unsubscribe()
 	defer chainEvSub.Unsubscribe()
@@ -433,6 +464,8 @@ func (es *EventSystem) eventLoop() {
 		// Handle subscribed events
 		case ev := <-txCh:
 			es.broadcast(index, ev)
+		case ev := <-dropCh:
+			es.broadcast(index, ev)
 		case ev := <-rmLogsCh:
 			es.broadcast(index, ev)
 		case ev := <-logsCh:
@@ -462,6 +495,8 @@ func (es *EventSystem) eventLoop() {
 		// System stopped
 		case <-txSub.Err():
 			return
+		case <-dropSub.Err():
+			return
 		case <-rmLogsSub.Err():
 			return
 		case <-logsSub.Err():
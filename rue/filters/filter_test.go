@@ -21,6 +21,7 @@ import (
 	"io/ioutil"
 	"math/big"
 	"os"
+	"reflect"
 	"testing"
 
 	"github.com/Rue-Foundation/go-rue/common"
@@ -56,7 +57,7 @@ func BenchmarkFilters(b *testing.B) {
 		rmLogsFeed = new(event.Feed)
 		logsFeed   = new(event.Feed)
 		chainFeed  = new(event.Feed)
-		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed}
+		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed, new(event.Feed)}
 		key1, _    = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
 		addr1      = crypto.PubkeyToAddress(key1.PublicKey)
 		addr2      = common.BytesToAddress([]byte("jeff"))
@@ -121,7 +122,7 @@ func TestFilters(t *testing.T) {
 		rmLogsFeed = new(event.Feed)
 		logsFeed   = new(event.Feed)
 		chainFeed  = new(event.Feed)
-		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed}
+		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed, new(event.Feed)}
 		key1, _    = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
 		addr       = crypto.PubkeyToAddress(key1.PublicKey)
 
@@ -241,3 +242,104 @@ func TestFilters(t *testing.T) {
 		t.Error("expected 0 log, got", len(logs))
 	}
 }
+
+// logIndexBackend is a test backend serving a fixed number of log index sections.
+type logIndexBackend struct {
+	*testBackend
+	size, sections uint64
+}
+
+func (b *logIndexBackend) LogIndexStatus() (uint64, uint64) {
+	return b.size, b.sections
+}
+
+func TestLogIndexFilters(t *testing.T) {
+	var (
+		db, _   = ruedb.NewMemDatabase()
+		backend = &logIndexBackend{
+			testBackend: &testBackend{new(event.TypeMux), db, 0, new(event.Feed), new(event.Feed), new(event.Feed), new(event.Feed), new(event.Feed)},
+			size:        100,
+			sections:    5,
+		}
+		addr1 = common.BytesToAddress([]byte("addr1"))
+		addr2 = common.BytesToAddress([]byte("addr2"))
+
+		hash1 = common.BytesToHash([]byte("topic1"))
+		hash2 = common.BytesToHash([]byte("topic2"))
+	)
+	genesis := core.GenesisBlockForTesting(db, addr1, big.NewInt(1000000))
+	chain, receipts := core.GenerateChain(params.TestChainConfig, genesis, ruehash.NewFaker(), db, 600, func(i int, gen *core.BlockGen) {
+		receipt := types.NewReceipt(nil, false, new(big.Int))
+		switch i {
+		case 10, 250, 550:
+			receipt.Logs = []*types.Log{{Address: addr1, Topics: []common.Hash{hash1, hash2}, BlockNumber: uint64(i + 1)}}
+		case 20, 499, 560:
+			receipt.Logs = []*types.Log{{Address: addr2, Topics: []common.Hash{hash2, hash1}, BlockNumber: uint64(i + 1)}}
+		default:
+			return
+		}
+		gen.AddUncheckedReceipt(receipt)
+	})
+	// Write the chain and index the logs of the first few sections by hand
+	entries := make(map[uint64]map[string][]core.LogPosition)
+	for i, block := range chain {
+		core.WriteBlock(db, block)
+		core.WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+		core.WriteHeadBlockHash(db, block.Hash())
+		core.WriteBlockReceipts(db, block.Hash(), block.NumberU64(), receipts[i])
+
+		section := block.NumberU64() / backend.size
+		if entries[section] == nil {
+			entries[section] = make(map[string][]core.LogPosition)
+		}
+		for j, receipt := range receipts[i] {
+			for _, log := range receipt.Logs {
+				pos := core.LogPosition{Block: block.NumberU64(), TxIndex: uint32(j)}
+
+				key := string(core.LogIndexAddressKey(log.Address))
+				entries[section][key] = append(entries[section][key], pos)
+				for k, topic := range log.Topics {
+					key := string(core.LogIndexTopicKey(k, topic))
+					entries[section][key] = append(entries[section][key], pos)
+				}
+			}
+		}
+	}
+	for section := uint64(0); section < backend.sections; section++ {
+		head := core.GetCanonicalHash(db, (section+1)*backend.size-1)
+		for key, positions := range entries[section] {
+			core.WriteLogIndex(db, []byte(key), section, head, positions)
+		}
+	}
+	// Run filters spanning both indexed and unindexed ranges
+	tests := []struct {
+		begin, end int64
+		addresses  []common.Address
+		topics     [][]common.Hash
+		want       []uint64
+	}{
+		{0, -1, []common.Address{addr1}, nil, []uint64{11, 251, 551}},
+		{0, -1, []common.Address{addr1, addr2}, nil, []uint64{11, 21, 251, 500, 551, 561}},
+		{0, -1, nil, [][]common.Hash{{hash1}}, []uint64{11, 251, 551}},
+		{0, -1, nil, [][]common.Hash{nil, {hash1}}, []uint64{21, 500, 561}},
+		{0, -1, []common.Address{addr2}, [][]common.Hash{{hash1}}, nil},
+		{15, 499, []common.Address{addr2}, nil, []uint64{21}},
+		{15, 500, []common.Address{addr2}, nil, []uint64{21, 500}},
+		{300, 555, nil, [][]common.Hash{nil, {hash2}}, []uint64{551}},
+	}
+	for i, tt := range tests {
+		filter := New(backend, tt.begin, tt.end, tt.addresses, tt.topics)
+		logs, err := filter.Logs(context.Background())
+		if err != nil {
+			t.Errorf("test %d: failed to filter logs: %v", i, err)
+			continue
+		}
+		var have []uint64
+		for _, log := range logs {
+			have = append(have, log.BlockNumber)
+		}
+		if !reflect.DeepEqual(have, tt.want) {
+			t.Errorf("test %d: log blocks mismatch: have %v, want %v", i, have, tt.want)
+		}
+	}
+}
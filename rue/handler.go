@@ -735,6 +735,10 @@ func (self *ProtocolManager) txBroadcastLoop() {
 	for {
 		select {
 		case event := <-self.txCh:
+			// Private transactions are reserved for the local miner
+			if event.Private {
+				continue
+			}
 			self.BroadcastTx(event.Tx.Hash(), event.Tx)
 
 		// Err() channel will be closed when unsubscribing.
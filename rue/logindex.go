@@ -0,0 +1,91 @@
+// Copyright 2018 The go-ruereum Authors
+// This file is part of the go-ruereum library.
+//
+// The go-ruereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ruereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ruereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rue
+
+import (
+	"github.com/Rue-Foundation/go-rue/common"
+	"github.com/Rue-Foundation/go-rue/core"
+	"github.com/Rue-Foundation/go-rue/core/types"
+	"github.com/Rue-Foundation/go-rue/ruedb"
+)
+
+// LogIndexer implements a core.ChainIndexer, building up an inverted index from
+// log addresses and positional topics to the logs containing them, permitting
+// log filtering without scanning receipts of unrelated blocks.
+type LogIndexer struct {
+	db ruedb.Database // database instance to read receipts from and write index data into
+
+	section uint64                        // Section is the section number being processed currently
+	head    common.Hash                   // Head is the hash of the last header processed
+	entries map[string][]core.LogPosition // Log positions accumulated for the current section
+}
+
+// NewLogIndexer returns a chain indexer that generates an address and topic log
+// index for the canonical chain for fast logs filtering.
+func NewLogIndexer(db ruedb.Database, size uint64) *core.ChainIndexer {
+	backend := &LogIndexer{
+		db: db,
+	}
+	table := ruedb.NewTable(db, string(core.LogIndexPrefix))
+
+	return core.NewChainIndexer(db, table, backend, size, bloomConfirms, bloomThrottling, "logindex")
+}
+
+// Reset implements core.ChainIndexerBackend, starting a new log index section.
+func (l *LogIndexer) Reset(section uint64, lastSectionHead common.Hash) error {
+	l.section, l.head = section, common.Hash{}
+	l.entries = make(map[string][]core.LogPosition)
+	return nil
+}
+
+// Process implements core.ChainIndexerBackend, adding the logs of a new header's
+// receipts into the index.
+func (l *LogIndexer) Process(header *types.Header) {
+	var (
+		hash     = header.Hash()
+		number   = header.Number.Uint64()
+		logIndex uint32
+	)
+	for i, receipt := range core.GetBlockReceipts(l.db, hash, number) {
+		for _, log := range receipt.Logs {
+			pos := core.LogPosition{Block: number, TxIndex: uint32(i), LogIndex: logIndex}
+			logIndex++
+
+			key := string(core.LogIndexAddressKey(log.Address))
+			l.entries[key] = append(l.entries[key], pos)
+
+			for j, topic := range log.Topics {
+				key := string(core.LogIndexTopicKey(j, topic))
+				l.entries[key] = append(l.entries[key], pos)
+			}
+		}
+	}
+	l.head = hash
+}
+
+// Commit implements core.ChainIndexerBackend, writing the accumulated log index
+// section out into the database.
+func (l *LogIndexer) Commit() error {
+	batch := l.db.NewBatch()
+
+	for key, positions := range l.entries {
+		if err := core.WriteLogIndex(batch, []byte(key), l.section, l.head, positions); err != nil {
+			return err
+		}
+	}
+	return batch.Write()
+}
@@ -0,0 +1,86 @@
+// Copyright 2018 The go-ruereum Authors
+// This file is part of the go-ruereum library.
+//
+// The go-ruereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ruereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ruereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rue
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/Rue-Foundation/go-rue/common"
+	"github.com/Rue-Foundation/go-rue/consensus/ruehash"
+	"github.com/Rue-Foundation/go-rue/core"
+	"github.com/Rue-Foundation/go-rue/core/types"
+	"github.com/Rue-Foundation/go-rue/params"
+	"github.com/Rue-Foundation/go-rue/ruedb"
+)
+
+// Tests that the log indexer records the positions of all logs under their
+// address and positional topic keys, scoped to the section head.
+func TestLogIndexer(t *testing.T) {
+	var (
+		db, _   = ruedb.NewMemDatabase()
+		addr    = common.Address{0x01}
+		topic   = common.Hash{0x02}
+		genesis = core.GenesisBlockForTesting(db, testBank, big.NewInt(1000000))
+	)
+	chain, receipts := core.GenerateChain(params.TestChainConfig, genesis, ruehash.NewFaker(), db, 4, func(i int, gen *core.BlockGen) {
+		if i%2 == 0 {
+			return
+		}
+		receipt := types.NewReceipt(nil, false, new(big.Int))
+		receipt.Logs = []*types.Log{{Address: addr}, {Address: addr, Topics: []common.Hash{{}, topic}}}
+		gen.AddUncheckedReceipt(receipt)
+	})
+	indexer := &LogIndexer{db: db}
+	if err := indexer.Reset(1, common.Hash{}); err != nil {
+		t.Fatalf("failed to reset indexer: %v", err)
+	}
+	for i, block := range chain {
+		core.WriteBlockReceipts(db, block.Hash(), block.NumberU64(), receipts[i])
+		indexer.Process(block.Header())
+	}
+	if err := indexer.Commit(); err != nil {
+		t.Fatalf("failed to commit section: %v", err)
+	}
+	head := chain[len(chain)-1].Hash()
+
+	tests := []struct {
+		key  []byte
+		want []core.LogPosition
+	}{
+		{core.LogIndexAddressKey(addr), []core.LogPosition{{Block: 2, LogIndex: 0}, {Block: 2, LogIndex: 1}, {Block: 4, LogIndex: 0}, {Block: 4, LogIndex: 1}}},
+		{core.LogIndexTopicKey(1, topic), []core.LogPosition{{Block: 2, LogIndex: 1}, {Block: 4, LogIndex: 1}}},
+		{core.LogIndexTopicKey(0, topic), nil},
+	}
+	for i, tt := range tests {
+		positions, err := core.GetLogIndex(db, tt.key, 1, head)
+		if err != nil {
+			t.Fatalf("test %d: failed to retrieve log index: %v", i, err)
+		}
+		if !reflect.DeepEqual(positions, tt.want) {
+			t.Errorf("test %d: positions mismatch: have %v, want %v", i, positions, tt.want)
+		}
+	}
+	// Entries must not be visible under any other section or head
+	if positions, _ := core.GetLogIndex(db, core.LogIndexAddressKey(addr), 0, head); positions != nil {
+		t.Errorf("positions leaked into other section: %v", positions)
+	}
+	if positions, _ := core.GetLogIndex(db, core.LogIndexAddressKey(addr), 1, chain[0].Hash()); positions != nil {
+		t.Errorf("positions leaked to other head: %v", positions)
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"time"
 
 	"github.com/Rue-Foundation/go-rue/common"
+	"github.com/Rue-Foundation/go-rue/core"
 	"github.com/Rue-Foundation/go-rue/core/types"
 	"github.com/Rue-Foundation/go-rue/crypto"
 	"github.com/Rue-Foundation/go-rue/rue/downloader"
@@ -174,6 +175,48 @@ func testSendTransactions(t *testing.T, protocol int) {
 	wg.Wait()
 }
 
+// Tests that private transactions are neither synced to newly connected peers
+// nor broadcast when announced by the pool.
+func TestPrivateTransactionsNotPropagated(t *testing.T) {
+	pm := newTestProtocolManagerMust(t, downloader.FullSync, 0, nil, nil)
+	defer pm.Stop()
+
+	pool := pm.txpool.(*testTxPool)
+
+	public := newTestTransaction(testAccount, 0, 0)
+	pool.AddRemotes([]*types.Transaction{public})
+	pool.AddPrivate(newTestTransaction(testAccount, 1, 0))
+
+	p, _ := newTestPeer("peer", rue63, pm, true)
+	defer p.close()
+
+	// Only the public transaction may be synced to the new peer
+	expect := func(want *types.Transaction) {
+		msg, err := p.app.ReadMsg()
+		if err != nil {
+			t.Fatalf("read error: %v", err)
+		}
+		if msg.Code != TxMsg {
+			t.Fatalf("message code mismatch: have %d, want %d", msg.Code, TxMsg)
+		}
+		var txs []*types.Transaction
+		if err := msg.Decode(&txs); err != nil {
+			t.Fatalf("failed to decode transactions: %v", err)
+		}
+		if len(txs) != 1 || txs[0].Hash() != want.Hash() {
+			t.Fatalf("propagated transactions mismatch: have %d, want %x", len(txs), want.Hash())
+		}
+	}
+	expect(public)
+
+	// Announce a private and a public transaction, only the latter may be broadcast
+	private, broadcast := newTestTransaction(testAccount, 2, 0), newTestTransaction(testAccount, 3, 0)
+	pool.txFeed.Send(core.TxPreEvent{Tx: private, Private: true})
+	pool.txFeed.Send(core.TxPreEvent{Tx: broadcast})
+
+	expect(broadcast)
+}
+
 // Tests that the custom union field encoder and decoder works correctly.
 func TestGetBlockHeadersDataEncodeDecode(t *testing.T) {
 	// Create a "random" hash for testing
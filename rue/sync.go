@@ -42,12 +42,17 @@ type txsync struct {
 	txs []*types.Transaction
 }
 
-// syncTransactions starts sending all currently pending transactions to the given peer.
+// syncTransactions starts sending all currently pending transactions to the given
+// peer, except for the private ones.
 func (pm *ProtocolManager) syncTransactions(p *peer) {
 	var txs types.Transactions
 	pending, _ := pm.txpool.Pending()
 	for _, batch := range pending {
-		txs = append(txs, batch...)
+		for _, tx := range batch {
+			if !pm.txpool.IsPrivate(tx.Hash()) {
+				txs = append(txs, tx)
+			}
+		}
 	}
 	if len(txs) == 0 {
 		return
@@ -82,13 +82,19 @@
 		}
 		// Whenever new state is accessed, add it to the prestate
 		switch (log.op.toString()) {
-			case "EXTCODECOPY": case "EXTCODESIZE": case "BALANCE":
+			case "EXTCODECOPY": case "EXTCODESIZE": case "EXTCODEHASH": case "BALANCE":
 				this.lookupAccount(toAddress(log.stack.peek(0).toString(16)), db);
 				break;
 			case "CREATE":
 				var from = log.contract.getAddress();
 				this.lookupAccount(toContract(from, db.getNonce(from)), db);
 				break;
+			case "CREATE2":
+				var from = log.contract.getAddress();
+				var inOff = log.stack.peek(1).valueOf();
+				var inEnd = inOff + log.stack.peek(2).valueOf();
+				this.lookupAccount(toContract2(from, log.stack.peek(3).toString(16), log.memory.slice(inOff, inEnd)), db);
+				break;
 			case "CALL": case "CALLCODE": case "DELEGATECALL": case "STATICCALL":
 				this.lookupAccount(toAddress(log.stack.peek(1).toString(16)), db);
 				break;
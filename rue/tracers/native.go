@@ -0,0 +1,107 @@
+// Copyright 2018 The go-ruereum Authors
+// This file is part of the go-ruereum library.
+//
+// The go-ruereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ruereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ruereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"encoding/json"
+	"math/big"
+	"sync/atomic"
+
+	"github.com/Rue-Foundation/go-rue/core/vm"
+	"github.com/Rue-Foundation/go-rue/log"
+)
+
+// NativeTracer is a transaction tracer implemented directly in Go. Tracers which
+// have a JavaScript counterpart of the same name produce the same output, without
+// the cost of running every opcode through the JavaScript VM.
+type NativeTracer interface {
+	vm.Tracer
+
+	// GetResult returns the JSON encoded result of the tracing, or any error
+	// that occurred during it.
+	GetResult() (json.RawMessage, error)
+
+	// Stop terminates execution of the tracer at the first opportune moment.
+	Stop(err error)
+}
+
+// natives contains all the built in native tracers by name.
+var natives = map[string]func() NativeTracer{
+	"callTracer":     newCallTracer,
+	"prestateTracer": newPrestateTracer,
+	"4byteTracer":    newFourByteTracer,
+	"flatCallTracer": newFlatCallTracer,
+	"vmTracer":       newVMTracer,
+}
+
+// NewNative instantiates a new native tracer by name, returning false if no
+// native implementation exists for it.
+func NewNative(name string) (NativeTracer, bool) {
+	constructor, ok := natives[name]
+	if !ok {
+		return nil, false
+	}
+	return constructor(), true
+}
+
+// interrupter implements the interruption mechanics shared by the native tracers.
+type interrupter struct {
+	interrupt uint32 // Atomic flag to signal execution interruption
+	reason    error  // Textual reason for the interruption
+	err       error  // Error, if one has occurred
+}
+
+// Stop terminates execution of the tracer at the first opportune moment.
+func (i *interrupter) Stop(err error) {
+	i.reason = err
+	atomic.StoreUint32(&i.interrupt, 1)
+}
+
+// interrupted checks whether tracing was stopped or failed, recording the reason
+// of the interruption if it was.
+func (i *interrupter) interrupted() bool {
+	if i.err != nil {
+		return true
+	}
+	if atomic.LoadUint32(&i.interrupt) > 0 {
+		i.err = i.reason
+		return true
+	}
+	return false
+}
+
+// peekStack returns the nth-from-the-top element of the stack, or zero if the
+// stack is not deep enough, mirroring the JavaScript stack wrapper.
+func peekStack(stack *vm.Stack, idx int) *big.Int {
+	data := stack.Data()
+	if len(data) <= idx {
+		log.Warn("Tracer accessed out of bound stack", "size", len(data), "index", idx)
+		return new(big.Int)
+	}
+	return data[len(data)-idx-1]
+}
+
+// sliceMemory returns the requested range of memory, or nil if the range is not
+// fully available, mirroring the JavaScript memory wrapper.
+func sliceMemory(memory *vm.Memory, offset, size *big.Int) []byte {
+	end := new(big.Int).Add(offset, size)
+	if !end.IsInt64() || int64(memory.Len()) < end.Int64() {
+		log.Warn("Tracer accessed out of bound memory", "available", memory.Len(), "offset", offset, "size", size)
+		return nil
+	}
+	return memory.Get(offset.Int64(), size.Int64())
+}
@@ -0,0 +1,104 @@
+// Copyright 2018 The go-ruereum Authors
+// This file is part of the go-ruereum library.
+//
+// The go-ruereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ruereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ruereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"encoding/json"
+	"math/big"
+	"time"
+
+	"github.com/Rue-Foundation/go-rue/common"
+	"github.com/Rue-Foundation/go-rue/common/hexutil"
+	"github.com/Rue-Foundation/go-rue/core/vm"
+)
+
+// fourByteTracer is a native port of the JavaScript 4byteTracer, searching for
+// 4byte method identifiers and collecting them along with the size of the data
+// supplied to them, so a reversed signature can be matched against the size.
+//
+// Note, the JavaScript tracer attempts to skip pre-compile invocations, but its
+// check never matches, so they are collected here too to keep the outputs the same.
+type fourByteTracer struct {
+	interrupter
+
+	ids   map[string]int // Aggregated 4byte ids with their occurrence counts
+	input []byte         // Input data of the outer transaction
+}
+
+// newFourByteTracer creates a native 4byte tracer.
+func newFourByteTracer() NativeTracer {
+	return &fourByteTracer{ids: make(map[string]int)}
+}
+
+// store saves the given identifier and data size.
+func (t *fourByteTracer) store(id []byte, size *big.Int) {
+	t.ids[hexutil.Encode(id)+"-"+size.String()]++
+}
+
+// CaptureStart implements the Tracer interface to initialize the tracing operation.
+func (t *fourByteTracer) CaptureStart(from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) error {
+	t.input = input
+	return nil
+}
+
+// CaptureState implements the Tracer interface to trace a single step of VM execution.
+func (t *fourByteTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	if t.interrupted() {
+		return nil
+	}
+	// Skip any opcodes that are not internal calls, and find the position of the
+	// input data offset on the stack for those that are
+	var in int
+	switch op {
+	case vm.CALL, vm.CALLCODE:
+		in = 3 // gas, addr, val, memin, meminsz, memout, memoutsz
+	case vm.DELEGATECALL, vm.STATICCALL:
+		in = 2 // gas, addr, memin, meminsz, memout, memoutsz
+	default:
+		return nil
+	}
+	// Gather internal call details
+	if size := peekStack(stack, in+1); size.Cmp(big.NewInt(4)) >= 0 {
+		id := sliceMemory(memory, peekStack(stack, in), big.NewInt(4))
+		t.store(id, new(big.Int).Sub(size, big.NewInt(4)))
+	}
+	return nil
+}
+
+// CaptureFault implements the Tracer interface to trace an execution fault
+// while running an opcode.
+func (t *fourByteTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	return nil
+}
+
+// CaptureEnd is called after the call finishes to finalize the tracing.
+func (t *fourByteTracer) CaptureEnd(output []byte, gasUsed uint64, d time.Duration, err error) error {
+	return nil
+}
+
+// GetResult returns the collected 4byte identifiers, or any error that occurred
+// during tracing.
+func (t *fourByteTracer) GetResult() (json.RawMessage, error) {
+	if t.err != nil {
+		return nil, t.err
+	}
+	// Save the outer calldata also
+	if len(t.input) > 4 {
+		t.store(t.input[:4], big.NewInt(int64(len(t.input)-4)))
+	}
+	return json.Marshal(t.ids)
+}
@@ -0,0 +1,287 @@
+// Copyright 2018 The go-ruereum Authors
+// This file is part of the go-ruereum library.
+//
+// The go-ruereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ruereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ruereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"encoding/json"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/Rue-Foundation/go-rue/common"
+	"github.com/Rue-Foundation/go-rue/common/hexutil"
+	"github.com/Rue-Foundation/go-rue/core/vm"
+)
+
+// callFrame is a single call reported by the call tracer. The exported fields
+// are serialized in the same order as the JavaScript tracer's finalize method,
+// the unexported ones are bookkeeping needed while the call is in progress.
+type callFrame struct {
+	Type    string       `json:"type"`
+	From    string       `json:"from,omitempty"`
+	To      string       `json:"to,omitempty"`
+	Value   string       `json:"value,omitempty"`
+	Gas     string       `json:"gas,omitempty"`
+	GasUsed string       `json:"gasUsed,omitempty"`
+	Input   string       `json:"input,omitempty"`
+	Output  string       `json:"output,omitempty"`
+	Error   string       `json:"error,omitempty"`
+	Time    string       `json:"time,omitempty"`
+	Calls   []*callFrame `json:"calls,omitempty"`
+
+	gasIn   uint64   // Gas available before the call opcode
+	gasCost uint64   // Gas cost of the call opcode
+	gas     *uint64  // Gas allowance of the inner call, if it could be retrieved
+	outOff  *big.Int // Memory offset to retrieve the call output from
+	outLen  *big.Int // Memory length of the call output
+
+	address common.Address // Self destructed contract address (not reported by the call tracer)
+	refund  common.Address // Beneficiary of a self destruct (not reported by the call tracer)
+	balance *big.Int       // Balance refunded by a self destruct (not reported by the call tracer)
+}
+
+// callTracer is a native port of the JavaScript callTracer, extracting and
+// reporting all the internal calls made by a transaction.
+type callTracer struct {
+	interrupter
+
+	callstack []*callFrame // Current recursive call stack of the EVM execution
+	descended bool         // Whether we've just descended into an inner call
+
+	typ     string         // Type of the outer transaction, CALL or CREATE
+	from    common.Address // Sender of the outer transaction
+	to      common.Address // Recipient of the outer transaction
+	input   []byte         // Input data of the outer transaction
+	gas     uint64         // Gas allowance of the outer transaction
+	value   *big.Int       // Value transferred by the outer transaction
+	output  []byte         // Data returned by the outer transaction
+	gasUsed uint64         // Gas used by the outer transaction
+	time    time.Duration  // Duration of the execution
+	failure error          // Error the outer transaction failed with
+}
+
+// newCallTracer creates a native call tracer.
+func newCallTracer() NativeTracer {
+	return &callTracer{callstack: []*callFrame{{}}}
+}
+
+// CaptureStart implements the Tracer interface to initialize the tracing operation.
+func (t *callTracer) CaptureStart(from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) error {
+	t.typ = "CALL"
+	if create {
+		t.typ = "CREATE"
+	}
+	t.from, t.to, t.input, t.gas, t.value = from, to, input, gas, value
+	return nil
+}
+
+// CaptureState implements the Tracer interface to trace a single step of VM execution.
+func (t *callTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	if t.interrupted() {
+		return nil
+	}
+	// Capture any errors immediately
+	if err != nil {
+		t.fault(err)
+		return nil
+	}
+	switch op {
+	case vm.CREATE, vm.CREATE2:
+		// If a new contract is being created, add to the call stack
+		t.callstack = append(t.callstack, &callFrame{
+			Type:    op.String(),
+			From:    hexutil.Encode(contract.Address().Bytes()),
+			Input:   hexutil.Encode(sliceMemory(memory, peekStack(stack, 1), peekStack(stack, 2))),
+			Value:   "0x" + peekStack(stack, 0).Text(16),
+			gasIn:   gas,
+			gasCost: cost,
+		})
+		t.descended = true
+		return nil
+
+	case vm.SELFDESTRUCT:
+		// If a contract is being self destructed, gather that as a subcall too
+		parent := t.callstack[len(t.callstack)-1]
+		parent.Calls = append(parent.Calls, &callFrame{
+			Type:    op.String(),
+			address: contract.Address(),
+			refund:  common.BigToAddress(peekStack(stack, 0)),
+			balance: env.StateDB.GetBalance(contract.Address()),
+		})
+		return nil
+
+	case vm.CALL, vm.CALLCODE, vm.DELEGATECALL, vm.STATICCALL:
+		// Skip any pre-compile invocations, those are just fancy opcodes
+		to := common.BigToAddress(peekStack(stack, 1))
+		if _, ok := vm.PrecompiledContractsByzantium[to]; ok {
+			return nil
+		}
+		off := 1
+		if op == vm.DELEGATECALL || op == vm.STATICCALL {
+			off = 0
+		}
+		call := &callFrame{
+			Type:    op.String(),
+			From:    hexutil.Encode(contract.Address().Bytes()),
+			To:      hexutil.Encode(to.Bytes()),
+			Input:   hexutil.Encode(sliceMemory(memory, peekStack(stack, 2+off), peekStack(stack, 3+off))),
+			gasIn:   gas,
+			gasCost: cost,
+			outOff:  new(big.Int).Set(peekStack(stack, 4+off)),
+			outLen:  new(big.Int).Set(peekStack(stack, 5+off)),
+		}
+		if op == vm.CALL || op == vm.CALLCODE {
+			call.Value = "0x" + peekStack(stack, 2).Text(16)
+		}
+		t.callstack = append(t.callstack, call)
+		t.descended = true
+		return nil
+	}
+	// If we've just descended into an inner call, retrieve it's true allowance. We
+	// need to extract if from within the call as there may be funky gas dynamics
+	// with regard to requested and actually given gas (2300 stipend, 63/64 rule).
+	// If the call was made to a plain account, the gas is left unset.
+	if t.descended {
+		if depth >= len(t.callstack) {
+			allowance := gas
+			t.callstack[len(t.callstack)-1].gas = &allowance
+		}
+		t.descended = false
+	}
+	// If an existing call is returning, pop off the call stack
+	if op == vm.REVERT {
+		t.callstack[len(t.callstack)-1].Error = "execution reverted"
+		return nil
+	}
+	if depth == len(t.callstack)-1 {
+		// Pop off the last call and get the execution results
+		call := t.callstack[len(t.callstack)-1]
+		t.callstack = t.callstack[:len(t.callstack)-1]
+
+		ret := peekStack(stack, 0)
+		if call.Type == vm.CREATE.String() || call.Type == vm.CREATE2.String() {
+			// If the call was a CREATE, retrieve the contract address and output code
+			call.GasUsed = formatGas(int64(call.gasIn) - int64(call.gasCost) - int64(gas))
+			if ret.Sign() != 0 {
+				addr := common.BigToAddress(ret)
+				call.To = hexutil.Encode(addr.Bytes())
+				call.Output = hexutil.Encode(env.StateDB.GetCode(addr))
+			} else if call.Error == "" {
+				call.Error = "internal failure"
+			}
+		} else if call.gas != nil {
+			// If the call was a contract call, retrieve the gas usage and output
+			call.GasUsed = formatGas(int64(call.gasIn) - int64(call.gasCost) + int64(*call.gas) - int64(gas))
+			if ret.Sign() != 0 {
+				call.Output = hexutil.Encode(sliceMemory(memory, call.outOff, call.outLen))
+			} else if call.Error == "" {
+				call.Error = "internal failure"
+			}
+		}
+		if call.gas != nil {
+			call.Gas = formatGas(int64(*call.gas))
+		}
+		// Inject the call into the previous one
+		parent := t.callstack[len(t.callstack)-1]
+		parent.Calls = append(parent.Calls, call)
+	}
+	return nil
+}
+
+// CaptureFault implements the Tracer interface to trace an execution fault
+// while running an opcode.
+func (t *callTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	if !t.interrupted() {
+		t.fault(err)
+	}
+	return nil
+}
+
+// fault handles the failure of the topmost call on the call stack.
+func (t *callTracer) fault(err error) {
+	// If the topmost call already reverted, don't handle the additional fault again
+	if t.callstack[len(t.callstack)-1].Error != "" {
+		return
+	}
+	// Pop off the just failed call, consuming all its available gas
+	call := t.callstack[len(t.callstack)-1]
+	t.callstack = t.callstack[:len(t.callstack)-1]
+
+	call.Error = err.Error()
+	if call.gas != nil {
+		call.Gas = formatGas(int64(*call.gas))
+		call.GasUsed = call.Gas
+	}
+	// Flatten the failed call into its parent
+	if len(t.callstack) > 0 {
+		parent := t.callstack[len(t.callstack)-1]
+		parent.Calls = append(parent.Calls, call)
+		return
+	}
+	// Last call failed too, leave it in the stack
+	t.callstack = append(t.callstack, call)
+}
+
+// CaptureEnd is called after the call finishes to finalize the tracing.
+func (t *callTracer) CaptureEnd(output []byte, gasUsed uint64, d time.Duration, err error) error {
+	t.output, t.gasUsed, t.time, t.failure = output, gasUsed, d, err
+	return nil
+}
+
+// GetResult returns the outer call along with all the internal calls made, or
+// any error that occurred during tracing.
+func (t *callTracer) GetResult() (json.RawMessage, error) {
+	if t.err != nil {
+		return nil, t.err
+	}
+	return json.Marshal(t.result())
+}
+
+// result assembles the outer call from the transaction context, with all the
+// internal calls made nested within.
+func (t *callTracer) result() *callFrame {
+	result := &callFrame{
+		Type:    t.typ,
+		From:    hexutil.Encode(t.from.Bytes()),
+		To:      hexutil.Encode(t.to.Bytes()),
+		Value:   "0x" + t.value.Text(16),
+		Gas:     formatGas(int64(t.gas)),
+		GasUsed: formatGas(int64(t.gasUsed)),
+		Input:   hexutil.Encode(t.input),
+		Output:  hexutil.Encode(t.output),
+		Time:    t.time.String(),
+		Calls:   t.callstack[0].Calls,
+	}
+	if t.callstack[0].Error != "" {
+		result.Error = t.callstack[0].Error
+	} else if t.failure != nil {
+		result.Error = t.failure.Error()
+	}
+	if result.Error != "" {
+		result.Output = ""
+	}
+	return result
+}
+
+// formatGas formats a (potentially negative) gas amount as a hex string, the
+// same way the JavaScript tracers do through big integers.
+func formatGas(gas int64) string {
+	if gas < 0 {
+		return "0x-" + strconv.FormatInt(-gas, 16)
+	}
+	return "0x" + strconv.FormatInt(gas, 16)
+}
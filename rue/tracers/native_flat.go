@@ -0,0 +1,153 @@
+// Copyright 2018 The go-ruereum Authors
+// This file is part of the go-ruereum library.
+//
+// The go-ruereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ruereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ruereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/Rue-Foundation/go-rue/common/hexutil"
+	"github.com/Rue-Foundation/go-rue/core/vm"
+)
+
+// FlatCallAction is the action part of a flat call trace, describing the call,
+// contract creation, self destruct or reward that took place.
+type FlatCallAction struct {
+	CallType      string `json:"callType,omitempty"`
+	From          string `json:"from,omitempty"`
+	To            string `json:"to,omitempty"`
+	Value         string `json:"value,omitempty"`
+	Gas           string `json:"gas,omitempty"`
+	Input         string `json:"input,omitempty"`
+	Init          string `json:"init,omitempty"`
+	Address       string `json:"address,omitempty"`
+	RefundAddress string `json:"refundAddress,omitempty"`
+	Balance       string `json:"balance,omitempty"`
+	Author        string `json:"author,omitempty"`
+	RewardType    string `json:"rewardType,omitempty"`
+}
+
+// FlatCallResult is the outcome of a successful call or contract creation.
+type FlatCallResult struct {
+	Address string `json:"address,omitempty"`
+	Code    string `json:"code,omitempty"`
+	GasUsed string `json:"gasUsed"`
+	Output  string `json:"output,omitempty"`
+}
+
+// FlatCallFrame is a single entry of the Parity style flat call trace, with
+// its position in the call tree identified by its trace address.
+type FlatCallFrame struct {
+	Action       FlatCallAction  `json:"action"`
+	Error        string          `json:"error,omitempty"`
+	Result       *FlatCallResult `json:"result"`
+	Subtraces    int             `json:"subtraces"`
+	TraceAddress []int           `json:"traceAddress"`
+	Type         string          `json:"type"`
+}
+
+// flatCallTracer is a native tracer reporting all the internal calls made by a
+// transaction as a flat list in the Parity trace format.
+type flatCallTracer struct {
+	*callTracer
+}
+
+// newFlatCallTracer creates a native flat call tracer.
+func newFlatCallTracer() NativeTracer {
+	return &flatCallTracer{callTracer: newCallTracer().(*callTracer)}
+}
+
+// GetResult returns the flattened call tree, or any error that occurred during
+// tracing.
+func (t *flatCallTracer) GetResult() (json.RawMessage, error) {
+	if t.err != nil {
+		return nil, t.err
+	}
+	return json.Marshal(flattenCall(t.result(), []int{}, nil))
+}
+
+// flattenCall appends the given call and all its internal calls, depth first,
+// to a flat trace list.
+func flattenCall(call *callFrame, address []int, frames []*FlatCallFrame) []*FlatCallFrame {
+	frame := &FlatCallFrame{
+		Error:        flatCallError(call.Error),
+		Subtraces:    len(call.Calls),
+		TraceAddress: address,
+	}
+	gas, gasUsed := call.Gas, call.GasUsed
+	if gas == "" {
+		gas = "0x0"
+	}
+	if gasUsed == "" {
+		gasUsed = "0x0"
+	}
+	switch call.Type {
+	case "CREATE", "CREATE2":
+		frame.Type = "create"
+		frame.Action = FlatCallAction{From: call.From, Value: call.Value, Gas: gas, Init: call.Input}
+		if call.Error == "" {
+			frame.Result = &FlatCallResult{Address: call.To, Code: call.Output, GasUsed: gasUsed}
+		}
+	case "SELFDESTRUCT":
+		frame.Type = "suicide"
+		frame.Action = FlatCallAction{
+			Address:       hexutil.Encode(call.address.Bytes()),
+			RefundAddress: hexutil.Encode(call.refund.Bytes()),
+			Balance:       hexutil.EncodeBig(call.balance),
+		}
+	default:
+		value, output := call.Value, call.Output
+		if value == "" {
+			value = "0x0"
+		}
+		if output == "" {
+			output = "0x"
+		}
+		frame.Type = "call"
+		frame.Action = FlatCallAction{
+			CallType: strings.ToLower(call.Type),
+			From:     call.From,
+			To:       call.To,
+			Value:    value,
+			Gas:      gas,
+			Input:    call.Input,
+		}
+		if call.Error == "" {
+			frame.Result = &FlatCallResult{GasUsed: gasUsed, Output: output}
+		}
+	}
+	frames = append(frames, frame)
+	for i, child := range call.Calls {
+		childAddress := make([]int, len(address)+1)
+		copy(childAddress, address)
+		childAddress[len(address)] = i
+
+		frames = flattenCall(child, childAddress, frames)
+	}
+	return frames
+}
+
+// flatCallError converts the error of a failed call into its Parity equivalent.
+func flatCallError(err string) string {
+	switch err {
+	case "execution reverted":
+		return "Reverted"
+	case vm.ErrOutOfGas.Error():
+		return "Out of gas"
+	}
+	return err
+}
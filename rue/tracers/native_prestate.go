@@ -0,0 +1,172 @@
+// Copyright 2018 The go-ruereum Authors
+// This file is part of the go-ruereum library.
+//
+// The go-ruereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ruereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ruereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/Rue-Foundation/go-rue/common"
+	"github.com/Rue-Foundation/go-rue/common/hexutil"
+	"github.com/Rue-Foundation/go-rue/core/vm"
+	"github.com/Rue-Foundation/go-rue/crypto"
+)
+
+// errNoPrestate is returned by the prestate tracer if the transaction did not
+// execute any code, so there was no state to gather.
+var errNoPrestate = errors.New("no code executed, prestate unavailable")
+
+// prestateAccount is the pre-transaction state of a single account.
+type prestateAccount struct {
+	Balance string            `json:"balance"`
+	Nonce   uint64            `json:"nonce"`
+	Code    string            `json:"code"`
+	Storage map[string]string `json:"storage"`
+}
+
+// prestateTracer is a native port of the JavaScript prestateTracer, gathering
+// sufficient information to create a local execution of the transaction from
+// a custom assembled genesis block.
+type prestateTracer struct {
+	interrupter
+
+	prestate map[string]*prestateAccount // Genesis allocation that's being built
+	db       vm.StateDB                  // State database to look accounts up in
+
+	create bool           // Whether the outer transaction is a contract creation
+	from   common.Address // Sender of the outer transaction
+	to     common.Address // Recipient of the outer transaction
+	value  *big.Int       // Value transferred by the outer transaction
+}
+
+// newPrestateTracer creates a native prestate tracer.
+func newPrestateTracer() NativeTracer {
+	return new(prestateTracer)
+}
+
+// CaptureStart implements the Tracer interface to initialize the tracing operation.
+func (t *prestateTracer) CaptureStart(from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) error {
+	t.create, t.from, t.to, t.value = create, from, to, value
+	return nil
+}
+
+// CaptureState implements the Tracer interface to trace a single step of VM execution.
+func (t *prestateTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	if t.interrupted() {
+		return nil
+	}
+	// Add the current account if we just started tracing. Balance will potentially
+	// be wrong here, since this will include the value sent along with the message.
+	// We fix that in GetResult.
+	if t.prestate == nil {
+		t.prestate = make(map[string]*prestateAccount)
+		t.db = env.StateDB
+		t.lookupAccount(contract.Address())
+	}
+	// Whenever new state is accessed, add it to the prestate
+	switch op {
+	case vm.EXTCODECOPY, vm.EXTCODESIZE, vm.EXTCODEHASH, vm.BALANCE:
+		t.lookupAccount(common.BigToAddress(peekStack(stack, 0)))
+	case vm.CREATE:
+		from := contract.Address()
+		t.lookupAccount(crypto.CreateAddress(from, t.db.GetNonce(from)))
+	case vm.CREATE2:
+		code := sliceMemory(memory, peekStack(stack, 1), peekStack(stack, 2))
+		t.lookupAccount(crypto.CreateAddress2(contract.Address(), common.BigToHash(peekStack(stack, 3)), code))
+	case vm.CALL, vm.CALLCODE, vm.DELEGATECALL, vm.STATICCALL:
+		t.lookupAccount(common.BigToAddress(peekStack(stack, 1)))
+	case vm.SSTORE, vm.SLOAD:
+		t.lookupStorage(contract.Address(), common.BigToHash(peekStack(stack, 0)))
+	}
+	return nil
+}
+
+// CaptureFault implements the Tracer interface to trace an execution fault
+// while running an opcode.
+func (t *prestateTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	return nil
+}
+
+// CaptureEnd is called after the call finishes to finalize the tracing.
+func (t *prestateTracer) CaptureEnd(output []byte, gasUsed uint64, d time.Duration, err error) error {
+	return nil
+}
+
+// lookupAccount injects the specified account into the prestate.
+func (t *prestateTracer) lookupAccount(addr common.Address) {
+	acc := hexutil.Encode(addr.Bytes())
+	if _, ok := t.prestate[acc]; ok {
+		return
+	}
+	t.prestate[acc] = &prestateAccount{
+		Balance: "0x" + t.db.GetBalance(addr).Text(16),
+		Nonce:   t.db.GetNonce(addr),
+		Code:    hexutil.Encode(t.db.GetCode(addr)),
+		Storage: make(map[string]string),
+	}
+}
+
+// lookupStorage injects the specified storage entry of the given account into
+// the prestate, unless it's empty.
+func (t *prestateTracer) lookupStorage(addr common.Address, key common.Hash) {
+	t.lookupAccount(addr)
+
+	storage := t.prestate[hexutil.Encode(addr.Bytes())].Storage
+	idx := hexutil.Encode(key.Bytes())
+	if _, ok := storage[idx]; ok {
+		return
+	}
+	if val := t.db.GetState(addr, key); val != (common.Hash{}) {
+		storage[idx] = hexutil.Encode(val.Bytes())
+	}
+}
+
+// GetResult returns the assembled prestate allocations, or any error that
+// occurred during tracing.
+func (t *prestateTracer) GetResult() (json.RawMessage, error) {
+	if t.err != nil {
+		return nil, t.err
+	}
+	if t.prestate == nil {
+		return nil, errNoPrestate
+	}
+	// At this point, we need to deduct the 'value' from the outer transaction,
+	// and move it back to the origin
+	t.lookupAccount(t.from)
+	t.lookupAccount(t.to)
+
+	var (
+		from = t.prestate[hexutil.Encode(t.from.Bytes())]
+		to   = t.prestate[hexutil.Encode(t.to.Bytes())]
+
+		fromBal, _ = new(big.Int).SetString(from.Balance[2:], 16)
+		toBal, _   = new(big.Int).SetString(to.Balance[2:], 16)
+	)
+	to.Balance = "0x" + toBal.Sub(toBal, t.value).Text(16)
+	from.Balance = "0x" + fromBal.Add(fromBal, t.value).Text(16)
+
+	// Decrement the caller's nonce, and remove empty create targets. We can blindly
+	// delete the contract prestate, as any existing state would have caused the
+	// transaction to be rejected as invalid in the first place.
+	from.Nonce--
+	if t.create {
+		delete(t.prestate, hexutil.Encode(t.to.Bytes()))
+	}
+	return json.Marshal(t.prestate)
+}
@@ -0,0 +1,255 @@
+// Copyright 2018 The go-ruereum Authors
+// This file is part of the go-ruereum library.
+//
+// The go-ruereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ruereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ruereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Rue-Foundation/go-rue/common"
+	"github.com/Rue-Foundation/go-rue/common/hexutil"
+	"github.com/Rue-Foundation/go-rue/core"
+	"github.com/Rue-Foundation/go-rue/core/types"
+	"github.com/Rue-Foundation/go-rue/core/vm"
+	"github.com/Rue-Foundation/go-rue/params"
+	"github.com/Rue-Foundation/go-rue/rlp"
+	"github.com/Rue-Foundation/go-rue/ruedb"
+	"github.com/Rue-Foundation/go-rue/tests"
+)
+
+// traceTest executes the transaction of a callTracer test case with the given
+// tracer attached, returning the raw trace result.
+func traceTest(t *testing.T, test *callTracerTest, tracer vm.Tracer, result func() (json.RawMessage, error)) json.RawMessage {
+	tx := new(types.Transaction)
+	if err := rlp.DecodeBytes(common.FromHex(test.Input), tx); err != nil {
+		t.Fatalf("failed to parse testcase input: %v", err)
+	}
+	signer := types.MakeSigner(test.Genesis.Config, new(big.Int).SetUint64(uint64(test.Context.Number)))
+	origin, _ := signer.Sender(tx)
+
+	context := vm.Context{
+		CanTransfer: core.CanTransfer,
+		Transfer:    core.Transfer,
+		Origin:      origin,
+		Coinbase:    test.Context.Miner,
+		BlockNumber: new(big.Int).SetUint64(uint64(test.Context.Number)),
+		Time:        new(big.Int).SetUint64(uint64(test.Context.Time)),
+		Difficulty:  (*big.Int)(test.Context.Difficulty),
+		GasLimit:    new(big.Int).SetUint64(uint64(test.Context.GasLimit)),
+		GasPrice:    tx.GasPrice(),
+	}
+	db, _ := ruedb.NewMemDatabase()
+	statedb := tests.MakePreState(db, test.Genesis.Alloc)
+
+	evm := vm.NewEVM(context, statedb, test.Genesis.Config, vm.Config{Debug: true, Tracer: tracer})
+
+	msg, err := tx.AsMessage(signer)
+	if err != nil {
+		t.Fatalf("failed to prepare transaction for tracing: %v", err)
+	}
+	st := core.NewStateTransition(evm, msg, new(core.GasPool).AddGas(tx.Gas()))
+	if _, _, _, _, err = st.TransitionDb(); err != nil {
+		t.Fatalf("failed to execute transaction: %v", err)
+	}
+	res, err := result()
+	if err != nil {
+		t.Fatalf("failed to retrieve trace result: %v", err)
+	}
+	return res
+}
+
+// loadTracerTest reads a callTracer test case from the test harness.
+func loadTracerTest(t *testing.T, file string) *callTracerTest {
+	blob, err := ioutil.ReadFile(filepath.Join("testdata", file))
+	if err != nil {
+		t.Fatalf("failed to read testcase: %v", err)
+	}
+	test := new(callTracerTest)
+	if err := json.Unmarshal(blob, test); err != nil {
+		t.Fatalf("failed to parse testcase: %v", err)
+	}
+	return test
+}
+
+// decodeTrace decodes a trace result, dropping the execution time as it
+// naturally differs between runs.
+func decodeTrace(t *testing.T, res json.RawMessage) interface{} {
+	var ret interface{}
+	if err := json.Unmarshal(res, &ret); err != nil {
+		t.Fatalf("failed to unmarshal trace result: %v", err)
+	}
+	if ret, ok := ret.(map[string]interface{}); ok {
+		delete(ret, "time")
+	}
+	return ret
+}
+
+// Tests that the native tracers produce exactly the same output as their
+// JavaScript counterparts.
+func TestNativeTracers(t *testing.T) {
+	files, err := ioutil.ReadDir("testdata")
+	if err != nil {
+		t.Fatalf("failed to retrieve tracer test suite: %v", err)
+	}
+	for name := range natives {
+		if _, ok := tracer(name); !ok {
+			continue
+		}
+		for _, file := range files {
+			if !strings.HasPrefix(file.Name(), "call_tracer_") {
+				continue
+			}
+			name, file := name, file // capture range variables
+			t.Run(name+"/"+camel(strings.TrimSuffix(strings.TrimPrefix(file.Name(), "call_tracer_"), ".json")), func(t *testing.T) {
+				t.Parallel()
+
+				test := loadTracerTest(t, file.Name())
+
+				jst, err := New(name)
+				if err != nil {
+					t.Fatalf("failed to create JavaScript tracer: %v", err)
+				}
+				native, ok := NewNative(name)
+				if !ok {
+					t.Fatalf("native tracer not found")
+				}
+				want := decodeTrace(t, traceTest(t, test, jst, jst.GetResult))
+				have := decodeTrace(t, traceTest(t, test, native, native.GetResult))
+				if !reflect.DeepEqual(have, want) {
+					t.Fatalf("trace mismatch: have %+v, want %+v", have, want)
+				}
+			})
+		}
+	}
+}
+
+// Tests that the flat call tracer reports the same calls as the call tracer, in
+// depth first order with the correct trace addresses.
+func TestFlatCallTracer(t *testing.T) {
+	for _, file := range []string{"call_tracer_deep_calls.json", "call_tracer_create2_call.json"} {
+		file := file // capture range variable
+		t.Run(camel(strings.TrimSuffix(strings.TrimPrefix(file, "call_tracer_"), ".json")), func(t *testing.T) {
+			test := loadTracerTest(t, file)
+
+			tracer, _ := NewNative("flatCallTracer")
+
+			var frames []*FlatCallFrame
+			if err := json.Unmarshal(traceTest(t, test, tracer, tracer.GetResult), &frames); err != nil {
+				t.Fatalf("failed to unmarshal trace result: %v", err)
+			}
+			var (
+				index int
+				check func(call *callTrace, address []int)
+			)
+			check = func(call *callTrace, address []int) {
+				if index >= len(frames) {
+					t.Fatalf("missing flat trace for call %v", address)
+				}
+				frame := frames[index]
+				index++
+
+				if !reflect.DeepEqual(frame.TraceAddress, address) {
+					t.Errorf("trace %d: address mismatch: have %v, want %v", index, frame.TraceAddress, address)
+				}
+				if frame.Subtraces != len(call.Calls) {
+					t.Errorf("trace %d: subtraces mismatch: have %d, want %d", index, frame.Subtraces, len(call.Calls))
+				}
+				if call.Type == "CREATE" || call.Type == "CREATE2" {
+					if frame.Type != "create" {
+						t.Errorf("trace %d: type mismatch: have %s, want create", index, frame.Type)
+					}
+				}
+				if frame.Type == "call" {
+					if frame.Action.CallType != strings.ToLower(call.Type) {
+						t.Errorf("trace %d: call type mismatch: have %s, want %s", index, frame.Action.CallType, call.Type)
+					}
+					if common.HexToAddress(frame.Action.From) != call.From || common.HexToAddress(frame.Action.To) != call.To {
+						t.Errorf("trace %d: endpoints mismatch: have %s->%s, want %x->%x", index, frame.Action.From, frame.Action.To, call.From, call.To)
+					}
+				}
+				for i := range call.Calls {
+					check(&call.Calls[i], append(append([]int{}, address...), i))
+				}
+			}
+			check(test.Result, []int{})
+			if index != len(frames) {
+				t.Errorf("flat trace count mismatch: have %d, want %d", len(frames), index)
+			}
+		})
+	}
+}
+
+// Tests that the vm tracer reports every executed opcode of the outer call frame
+// along with its effects.
+func TestVMTracer(t *testing.T) {
+	tracer, _ := NewNative("vmTracer")
+
+	env := vm.NewEVM(vm.Context{BlockNumber: big.NewInt(1)}, nil, params.TestChainConfig, vm.Config{Debug: true, Tracer: tracer})
+	contract := vm.NewContract(account{}, account{}, big.NewInt(0), 10000)
+	contract.Code = []byte{byte(vm.PUSH1), 0x2a, byte(vm.PUSH1), 0x0, byte(vm.MSTORE), byte(vm.STOP)}
+
+	if _, err := env.Interpreter().Run(contract, []byte{}); err != nil {
+		t.Fatal(err)
+	}
+	tracer.CaptureEnd(nil, 0, 0, nil)
+
+	res, err := tracer.GetResult()
+	if err != nil {
+		t.Fatal(err)
+	}
+	trace := new(vmTrace)
+	if err := json.Unmarshal(res, trace); err != nil {
+		t.Fatalf("failed to unmarshal trace result: %v", err)
+	}
+	if len(trace.Ops) != 4 {
+		t.Fatalf("operation count mismatch: have %d, want %d", len(trace.Ops), 4)
+	}
+	if push := trace.Ops[0].Ex.Push; len(push) != 1 || push[0] != "0x2a" {
+		t.Errorf("push mismatch: have %v, want [0x2a]", push)
+	}
+	if mem := trace.Ops[2].Ex.Mem; mem == nil || mem.Off != 0 || mem.Data != hexutil.Encode(common.LeftPadBytes([]byte{0x2a}, 32)) {
+		t.Errorf("memory write mismatch: have %+v", mem)
+	}
+	if used := trace.Ops[3].Ex.Used; used != 10000-3-3-6 {
+		t.Errorf("gas usage mismatch: have %d, want %d", used, 10000-3-3-6)
+	}
+}
+
+// Tests that a stopped native tracer reports the interruption reason.
+func TestNativeTracerStop(t *testing.T) {
+	tracer, _ := NewNative("callTracer")
+
+	timeout := errors.New("stahp")
+	tracer.Stop(timeout)
+
+	env := vm.NewEVM(vm.Context{BlockNumber: big.NewInt(1)}, nil, params.TestChainConfig, vm.Config{Debug: true, Tracer: tracer})
+	contract := vm.NewContract(account{}, account{}, big.NewInt(0), 10000)
+	contract.Code = []byte{byte(vm.PUSH1), 0x1, byte(vm.PUSH1), 0x1, 0x0}
+
+	if _, err := env.Interpreter().Run(contract, []byte{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tracer.GetResult(); err != timeout {
+		t.Errorf("Expected timeout error, got %v", err)
+	}
+}
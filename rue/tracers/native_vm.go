@@ -0,0 +1,230 @@
+// Copyright 2018 The go-ruereum Authors
+// This file is part of the go-ruereum library.
+//
+// The go-ruereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ruereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ruereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"encoding/json"
+	"math/big"
+	"time"
+
+	"github.com/Rue-Foundation/go-rue/common"
+	"github.com/Rue-Foundation/go-rue/common/hexutil"
+	"github.com/Rue-Foundation/go-rue/core/vm"
+)
+
+// vmTrace is the Parity style trace of the opcodes executed within a single
+// call frame.
+type vmTrace struct {
+	Code string         `json:"code"`
+	Ops  []*vmOperation `json:"ops"`
+}
+
+// vmOperation is a single executed opcode of a vmTrace.
+type vmOperation struct {
+	Cost uint64               `json:"cost"`
+	Ex   *vmExecutedOperation `json:"ex"`
+	PC   uint64               `json:"pc"`
+	Sub  *vmTrace             `json:"sub"`
+}
+
+// vmExecutedOperation contains the effects of an executed opcode.
+type vmExecutedOperation struct {
+	Mem   *vmMemoryDiff  `json:"mem"`
+	Push  []string       `json:"push"`
+	Store *vmStorageDiff `json:"store"`
+	Used  uint64         `json:"used"`
+}
+
+// vmMemoryDiff is a memory region written by an opcode.
+type vmMemoryDiff struct {
+	Off  int64  `json:"off"`
+	Data string `json:"data"`
+}
+
+// vmStorageDiff is a storage slot written by an opcode.
+type vmStorageDiff struct {
+	Key string `json:"key"`
+	Val string `json:"val"`
+}
+
+// vmPendingOp is an opcode which started executing, but whose effects are only
+// known when the next opcode is reached in the same call frame.
+type vmPendingOp struct {
+	entry  *vmOperation
+	gas    uint64   // Gas available before executing the opcode
+	pushes int      // Number of stack items to report after execution
+	memOff *big.Int // Offset of the memory region written by the opcode
+	memLen *big.Int // Length of the memory region written by the opcode
+	store  *vmStorageDiff
+}
+
+// vmFrame is the tracing state of a single call frame.
+type vmFrame struct {
+	trace   *vmTrace
+	pending *vmPendingOp
+}
+
+// vmTracer is a native tracer reporting every executed opcode along with its
+// effects on the stack, memory and storage in the Parity vmTrace format.
+type vmTracer struct {
+	interrupter
+
+	root   *vmTrace   // Trace of the outermost call frame
+	frames []*vmFrame // Tracing state of the currently active call frames
+}
+
+// newVMTracer creates a native vmTrace tracer.
+func newVMTracer() NativeTracer {
+	return new(vmTracer)
+}
+
+// CaptureStart implements the Tracer interface to initialize the tracing operation.
+func (t *vmTracer) CaptureStart(from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) error {
+	return nil
+}
+
+// CaptureState implements the Tracer interface to trace a single step of VM execution.
+func (t *vmTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	if t.interrupted() {
+		return nil
+	}
+	// Returning from inner calls completes any opcodes left pending in them
+	for len(t.frames) > depth {
+		t.frames[len(t.frames)-1].complete()
+		t.frames = t.frames[:len(t.frames)-1]
+	}
+	// Descending into an inner call starts a new trace, otherwise the effects of
+	// the previous opcode are now available
+	if len(t.frames) < depth {
+		frame := &vmFrame{trace: &vmTrace{Code: hexutil.Encode(contract.Code), Ops: []*vmOperation{}}}
+		if len(t.frames) == 0 {
+			t.root = frame.trace
+		} else if parent := t.frames[len(t.frames)-1]; parent.pending != nil {
+			parent.pending.entry.Sub = frame.trace
+		}
+		t.frames = append(t.frames, frame)
+	}
+	frame := t.frames[len(t.frames)-1]
+	if frame.pending != nil {
+		frame.pending.execute(gas, memory, stack)
+		frame.pending = nil
+	}
+	// Opcodes failing before execution are not reported
+	if err != nil {
+		return nil
+	}
+	pending := &vmPendingOp{
+		entry:  &vmOperation{Cost: cost, PC: pc},
+		gas:    gas,
+		pushes: vmPushes(op),
+	}
+	switch op {
+	case vm.SSTORE:
+		pending.store = &vmStorageDiff{
+			Key: hexutil.EncodeBig(peekStack(stack, 0)),
+			Val: hexutil.EncodeBig(peekStack(stack, 1)),
+		}
+	case vm.MSTORE:
+		pending.memOff, pending.memLen = peekStack(stack, 0), big.NewInt(32)
+	case vm.MSTORE8:
+		pending.memOff, pending.memLen = peekStack(stack, 0), big.NewInt(1)
+	case vm.CALLDATACOPY, vm.CODECOPY, vm.RETURNDATACOPY:
+		pending.memOff, pending.memLen = peekStack(stack, 0), peekStack(stack, 2)
+	case vm.EXTCODECOPY:
+		pending.memOff, pending.memLen = peekStack(stack, 1), peekStack(stack, 3)
+	case vm.CALL, vm.CALLCODE:
+		pending.memOff, pending.memLen = peekStack(stack, 5), peekStack(stack, 6)
+	case vm.DELEGATECALL, vm.STATICCALL:
+		pending.memOff, pending.memLen = peekStack(stack, 4), peekStack(stack, 5)
+	}
+	if pending.memOff != nil {
+		pending.memOff, pending.memLen = new(big.Int).Set(pending.memOff), new(big.Int).Set(pending.memLen)
+	}
+	frame.trace.Ops = append(frame.trace.Ops, pending.entry)
+	frame.pending = pending
+	return nil
+}
+
+// CaptureFault implements the Tracer interface to trace an execution fault
+// while running an opcode. Failed opcodes are reported without any effects.
+func (t *vmTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	if !t.interrupted() && len(t.frames) > 0 {
+		t.frames[len(t.frames)-1].pending = nil
+	}
+	return nil
+}
+
+// CaptureEnd is called after the call finishes to finalize the tracing.
+func (t *vmTracer) CaptureEnd(output []byte, gasUsed uint64, d time.Duration, err error) error {
+	for len(t.frames) > 0 {
+		t.frames[len(t.frames)-1].complete()
+		t.frames = t.frames[:len(t.frames)-1]
+	}
+	return nil
+}
+
+// GetResult returns the trace of the outermost call frame, or any error that
+// occurred during tracing.
+func (t *vmTracer) GetResult() (json.RawMessage, error) {
+	if t.err != nil {
+		return nil, t.err
+	}
+	return json.Marshal(t.root)
+}
+
+// complete finishes the last opcode of a call frame, which halted execution.
+func (f *vmFrame) complete() {
+	if f.pending != nil {
+		f.pending.entry.Ex = &vmExecutedOperation{Push: []string{}, Used: f.pending.gas - f.pending.entry.Cost}
+		f.pending = nil
+	}
+}
+
+// execute fills in the effects of an opcode from the state of the call frame
+// after its execution.
+func (p *vmPendingOp) execute(gas uint64, memory *vm.Memory, stack *vm.Stack) {
+	ex := &vmExecutedOperation{Push: make([]string, 0, p.pushes), Store: p.store, Used: gas}
+	for i := p.pushes - 1; i >= 0; i-- {
+		ex.Push = append(ex.Push, hexutil.EncodeBig(peekStack(stack, i)))
+	}
+	if p.memOff != nil && p.memLen.Sign() > 0 {
+		if data := sliceMemory(memory, p.memOff, p.memLen); data != nil {
+			ex.Mem = &vmMemoryDiff{Off: p.memOff.Int64(), Data: hexutil.Encode(data)}
+		}
+	}
+	p.entry.Ex = ex
+}
+
+// vmPushes returns the number of stack items reported as pushed by an opcode.
+// Duplications and swaps report all the items they touched.
+func vmPushes(op vm.OpCode) int {
+	switch {
+	case op >= vm.DUP1 && op <= vm.DUP16:
+		return int(op-vm.DUP1) + 2
+	case op >= vm.SWAP1 && op <= vm.SWAP16:
+		return int(op-vm.SWAP1) + 2
+	case op >= vm.LOG0 && op <= vm.LOG4:
+		return 0
+	}
+	switch op {
+	case vm.STOP, vm.POP, vm.MSTORE, vm.MSTORE8, vm.SSTORE, vm.JUMP, vm.JUMPI, vm.JUMPDEST,
+		vm.CALLDATACOPY, vm.CODECOPY, vm.EXTCODECOPY, vm.RETURNDATACOPY,
+		vm.RETURN, vm.REVERT, vm.SELFDESTRUCT:
+		return 0
+	}
+	return 1
+}
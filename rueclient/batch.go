@@ -0,0 +1,291 @@
+// Copyright 2018 The go-ruereum Authors
+// This file is part of the go-ruereum library.
+//
+// The go-ruereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ruereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ruereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rueclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/Rue-Foundation/go-rue"
+	"github.com/Rue-Foundation/go-rue/common"
+	"github.com/Rue-Foundation/go-rue/common/hexutil"
+	"github.com/Rue-Foundation/go-rue/core/types"
+	"github.com/Rue-Foundation/go-rue/rpc"
+)
+
+// Batch collects calls to the Ruereum RPC API and sends them to the server in a
+// single round-trip. Calls are added with the typed builder methods, mirroring
+// the methods of Client, and their results are retrieved by Execute in the order
+// they were added:
+//
+//	results, err := client.Batch().
+//		BlockByNumber(number).
+//		TransactionReceipt(hash).
+//		Execute(ctx)
+//
+// A Batch is not safe for concurrent use.
+type Batch struct {
+	client *Client
+	calls  []*batchCall
+}
+
+// BatchResult is the outcome of a single call of a batch.
+type BatchResult struct {
+	// Result is the decoded result of the call. Its type is documented by the
+	// builder method adding the call, e.g. *types.Block for BlockByNumber.
+	Result interface{}
+
+	// Err is the error of the call, ruereum.NotFound if the requested object
+	// does not exist.
+	Err error
+}
+
+// batchCall is a single call of a batch along with the means to decode its result.
+type batchCall struct {
+	raw    json.RawMessage
+	elem   rpc.BatchElem
+	decode func(raw json.RawMessage) (interface{}, error)
+
+	// Blocks need their uncles to be retrieved in a follow-up request
+	head   *types.Header
+	body   *rpcBlock
+	uncles []*types.Header
+	reqs   []rpc.BatchElem
+}
+
+// Batch creates a new, empty batch of calls.
+func (ec *Client) Batch() *Batch {
+	return &Batch{client: ec}
+}
+
+// Len returns the number of calls in the batch.
+func (b *Batch) Len() int {
+	return len(b.calls)
+}
+
+// add appends a call to the batch, decoding its result with the given function.
+func (b *Batch) add(decode func(json.RawMessage) (interface{}, error), method string, args ...interface{}) *Batch {
+	call := &batchCall{decode: decode}
+	call.elem = rpc.BatchElem{Method: method, Args: args, Result: &call.raw}
+	b.calls = append(b.calls, call)
+	return b
+}
+
+// addBlock appends a block retrieval to the batch.
+func (b *Batch) addBlock(method string, args ...interface{}) *Batch {
+	b.add(nil, method, args...)
+	b.calls[len(b.calls)-1].body = new(rpcBlock)
+	return b
+}
+
+// BlockByHash adds the retrieval of a full block to the batch, resulting in a
+// *types.Block.
+func (b *Batch) BlockByHash(hash common.Hash) *Batch {
+	return b.addBlock("rue_getBlockByHash", hash, true)
+}
+
+// BlockByNumber adds the retrieval of a full block from the current canonical
+// chain to the batch, resulting in a *types.Block. If number is nil, the latest
+// known block is retrieved.
+func (b *Batch) BlockByNumber(number *big.Int) *Batch {
+	return b.addBlock("rue_getBlockByNumber", toBlockNumArg(number), true)
+}
+
+// HeaderByHash adds the retrieval of a block header to the batch, resulting in a
+// *types.Header.
+func (b *Batch) HeaderByHash(hash common.Hash) *Batch {
+	return b.add(decodeHeader, "rue_getBlockByHash", hash, false)
+}
+
+// HeaderByNumber adds the retrieval of a block header from the current canonical
+// chain to the batch, resulting in a *types.Header. If number is nil, the latest
+// known header is retrieved.
+func (b *Batch) HeaderByNumber(number *big.Int) *Batch {
+	return b.add(decodeHeader, "rue_getBlockByNumber", toBlockNumArg(number), false)
+}
+
+// TransactionByHash adds the retrieval of a transaction to the batch, resulting
+// in a *types.Transaction.
+func (b *Batch) TransactionByHash(hash common.Hash) *Batch {
+	return b.add(decodeTransaction, "rue_getTransactionByHash", hash)
+}
+
+// TransactionReceipt adds the retrieval of a transaction receipt to the batch,
+// resulting in a *types.Receipt.
+func (b *Batch) TransactionReceipt(hash common.Hash) *Batch {
+	return b.add(decodeReceipt, "rue_getTransactionReceipt", hash)
+}
+
+// BalanceAt adds the retrieval of the wei balance of an account to the batch,
+// resulting in a *big.Int. If number is nil, the latest known block is used.
+func (b *Batch) BalanceAt(account common.Address, number *big.Int) *Batch {
+	return b.add(decodeBig, "rue_getBalance", account, toBlockNumArg(number))
+}
+
+// NonceAt adds the retrieval of the nonce of an account to the batch, resulting
+// in a uint64. If number is nil, the latest known block is used.
+func (b *Batch) NonceAt(account common.Address, number *big.Int) *Batch {
+	return b.add(decodeUint64, "rue_getTransactionCount", account, toBlockNumArg(number))
+}
+
+// CodeAt adds the retrieval of the code of an account to the batch, resulting in
+// a []byte. If number is nil, the latest known block is used.
+func (b *Batch) CodeAt(account common.Address, number *big.Int) *Batch {
+	return b.add(decodeBytes, "rue_getCode", account, toBlockNumArg(number))
+}
+
+// StorageAt adds the retrieval of a storage slot of an account to the batch,
+// resulting in a []byte. If number is nil, the latest known block is used.
+func (b *Batch) StorageAt(account common.Address, key common.Hash, number *big.Int) *Batch {
+	return b.add(decodeBytes, "rue_getStorageAt", account, key, toBlockNumArg(number))
+}
+
+// GetProof adds the retrieval of the Merkle proof of an account and some of its
+// storage slots to the batch, resulting in an *AccountResult. If number is nil,
+// the latest known block is used.
+func (b *Batch) GetProof(account common.Address, keys []string, number *big.Int) *Batch {
+	return b.add(decodeProof, "rue_getProof", account, keys, toBlockNumArg(number))
+}
+
+// Execute sends all calls of the batch to the server and decodes their results.
+// Only I/O errors are returned, errors specific to a call are reported through
+// the Err field of its result.
+//
+// Note that the uncles of blocks are retrieved in a second round-trip, as they
+// are not included in block responses.
+func (b *Batch) Execute(ctx context.Context) ([]BatchResult, error) {
+	if len(b.calls) == 0 {
+		return nil, nil
+	}
+	elems := make([]rpc.BatchElem, len(b.calls))
+	for i, call := range b.calls {
+		elems[i] = call.elem
+	}
+	if err := b.client.c.BatchCallContext(ctx, elems); err != nil {
+		return nil, err
+	}
+	results := make([]BatchResult, len(b.calls))
+
+	// Decode the results, collecting the uncles to retrieve for blocks
+	var uncleReqs []rpc.BatchElem
+	for i, call := range b.calls {
+		if results[i].Err = elems[i].Error; results[i].Err != nil {
+			continue
+		}
+		if call.body == nil {
+			results[i].Result, results[i].Err = call.decode(call.raw)
+			continue
+		}
+		if call.head, call.body, results[i].Err = decodeBlock(call.raw); results[i].Err != nil {
+			continue
+		}
+		call.reqs, call.uncles = uncleRequests(call.body)
+		uncleReqs = append(uncleReqs, call.reqs...)
+	}
+	if len(uncleReqs) > 0 {
+		if err := b.client.c.BatchCallContext(ctx, uncleReqs); err != nil {
+			return nil, err
+		}
+	}
+	// Assemble the blocks with their uncles
+	for i, call := range b.calls {
+		if call.body == nil || call.head == nil || results[i].Err != nil {
+			continue
+		}
+		reqs := uncleReqs[:len(call.reqs)]
+		uncleReqs = uncleReqs[len(call.reqs):]
+
+		if results[i].Err = checkUncles(call.body, reqs, call.uncles); results[i].Err == nil {
+			results[i].Result = assembleBlock(call.head, call.body, call.uncles)
+		}
+	}
+	return results, nil
+}
+
+func decodeHeader(raw json.RawMessage) (interface{}, error) {
+	var head *types.Header
+	if err := json.Unmarshal(raw, &head); err != nil {
+		return nil, err
+	}
+	if head == nil {
+		return nil, ruereum.NotFound
+	}
+	return head, nil
+}
+
+func decodeTransaction(raw json.RawMessage) (interface{}, error) {
+	var tx *rpcTransaction
+	if err := json.Unmarshal(raw, &tx); err != nil {
+		return nil, err
+	}
+	if tx == nil {
+		return nil, ruereum.NotFound
+	}
+	if _, r, _ := tx.tx.RawSignatureValues(); r == nil {
+		return nil, fmt.Errorf("server returned transaction without signature")
+	}
+	setSenderFromServer(tx.tx, tx.From, tx.BlockHash)
+	return tx.tx, nil
+}
+
+func decodeReceipt(raw json.RawMessage) (interface{}, error) {
+	var receipt *types.Receipt
+	if err := json.Unmarshal(raw, &receipt); err != nil {
+		return nil, err
+	}
+	if receipt == nil {
+		return nil, ruereum.NotFound
+	}
+	return receipt, nil
+}
+
+func decodeBig(raw json.RawMessage) (interface{}, error) {
+	var result hexutil.Big
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, err
+	}
+	return (*big.Int)(&result), nil
+}
+
+func decodeUint64(raw json.RawMessage) (interface{}, error) {
+	var result hexutil.Uint64
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, err
+	}
+	return uint64(result), nil
+}
+
+func decodeBytes(raw json.RawMessage) (interface{}, error) {
+	var result hexutil.Bytes
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, err
+	}
+	return []byte(result), nil
+}
+
+func decodeProof(raw json.RawMessage) (interface{}, error) {
+	var proof *accountResult
+	if err := json.Unmarshal(raw, &proof); err != nil {
+		return nil, err
+	}
+	if proof == nil {
+		return nil, ruereum.NotFound
+	}
+	return proof.export(), nil
+}
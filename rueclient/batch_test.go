@@ -0,0 +1,191 @@
+// Copyright 2018 The go-ruereum Authors
+// This file is part of the go-ruereum library.
+//
+// The go-ruereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ruereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ruereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rueclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/Rue-Foundation/go-rue"
+	"github.com/Rue-Foundation/go-rue/common"
+	"github.com/Rue-Foundation/go-rue/common/hexutil"
+	"github.com/Rue-Foundation/go-rue/core/types"
+	"github.com/Rue-Foundation/go-rue/rpc"
+)
+
+// BatchTestService serves a tiny, canned chain of an empty genesis block and a
+// block with two transactions.
+type BatchTestService struct {
+	headers  []*types.Header
+	txs      [][]common.Hash
+	prices   map[common.Hash]*big.Int
+	receipts map[common.Hash]*types.Receipt
+}
+
+func newBatchTestService() *BatchTestService {
+	s := &BatchTestService{
+		prices:   make(map[common.Hash]*big.Int),
+		receipts: make(map[common.Hash]*types.Receipt),
+	}
+
+	genesis := &types.Header{Number: big.NewInt(0), Difficulty: big.NewInt(1), GasLimit: big.NewInt(5000), GasUsed: new(big.Int), Time: new(big.Int), UncleHash: types.EmptyUncleHash, TxHash: types.EmptyRootHash}
+	block := &types.Header{ParentHash: genesis.Hash(), Number: big.NewInt(1), Difficulty: big.NewInt(1), GasLimit: big.NewInt(5000), GasUsed: big.NewInt(2500), Time: new(big.Int), UncleHash: types.EmptyUncleHash, TxHash: common.Hash{0x01}}
+
+	s.headers = []*types.Header{genesis, block}
+	s.txs = [][]common.Hash{nil, {{0xaa}, {0xbb}}}
+	for i, hash := range s.txs[1] {
+		receipt := types.NewReceipt(nil, false, big.NewInt(int64(21000*(i+1))))
+		receipt.TxHash, receipt.GasUsed, receipt.Logs = hash, big.NewInt(21000), []*types.Log{}
+		s.receipts[hash] = receipt
+		s.prices[hash] = big.NewInt(int64(3*i + 1))
+	}
+	return s
+}
+
+func (s *BatchTestService) block(number int, full bool) (map[string]interface{}, error) {
+	if number < 0 || number >= len(s.headers) {
+		return nil, nil
+	}
+	blob, err := json.Marshal(s.headers[number])
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(blob, &fields); err != nil {
+		return nil, err
+	}
+	fields["uncles"] = []common.Hash{}
+	fields["transactions"] = []common.Hash{}
+	if len(s.txs[number]) > 0 {
+		if !full {
+			fields["transactions"] = s.txs[number]
+		} else {
+			txs := make([]map[string]interface{}, len(s.txs[number]))
+			for i, hash := range s.txs[number] {
+				txs[i] = map[string]interface{}{"hash": hash, "gasPrice": (*hexutil.Big)(s.prices[hash])}
+			}
+			fields["transactions"] = txs
+		}
+	}
+	return fields, nil
+}
+
+func (s *BatchTestService) GetBlockByNumber(number rpc.BlockNumber, full bool) (map[string]interface{}, error) {
+	if number == rpc.LatestBlockNumber {
+		number = rpc.BlockNumber(len(s.headers) - 1)
+	}
+	return s.block(int(number), full)
+}
+
+func (s *BatchTestService) GetBlockByHash(hash common.Hash, full bool) (map[string]interface{}, error) {
+	for i, header := range s.headers {
+		if header.Hash() == hash {
+			return s.block(i, full)
+		}
+	}
+	return nil, nil
+}
+
+func (s *BatchTestService) GetTransactionReceipt(hash common.Hash) *types.Receipt {
+	return s.receipts[hash]
+}
+
+func (s *BatchTestService) GetBalance(address common.Address, number rpc.BlockNumber) (*hexutil.Big, error) {
+	if address == (common.Address{}) {
+		return nil, errors.New("no balance for the zero address")
+	}
+	return (*hexutil.Big)(big.NewInt(int64(address[0]))), nil
+}
+
+func (s *BatchTestService) GetProof(address common.Address, keys []string, number rpc.BlockNumber) (map[string]interface{}, error) {
+	if address == (common.Address{}) {
+		return nil, nil
+	}
+	storage := make([]map[string]interface{}, len(keys))
+	for i, key := range keys {
+		storage[i] = map[string]interface{}{"key": key, "value": (*hexutil.Big)(big.NewInt(int64(i + 1))), "proof": []string{"0x" + key}}
+	}
+	return map[string]interface{}{
+		"address":      address,
+		"accountProof": []string{"0x01", "0x02"},
+		"balance":      (*hexutil.Big)(big.NewInt(int64(address[0]))),
+		"codeHash":     common.Hash{0xcc},
+		"nonce":        hexutil.Uint64(number),
+		"storageHash":  common.Hash{0x55},
+		"storageProof": storage,
+	}, nil
+}
+
+// Tests that batched calls are decoded into typed results, with errors reported
+// individually.
+func TestBatch(t *testing.T) {
+	server := rpc.NewServer()
+	defer server.Stop()
+
+	service := newBatchTestService()
+	if err := server.RegisterName("rue", service); err != nil {
+		t.Fatal(err)
+	}
+	client := NewClient(rpc.DialInProc(server))
+
+	results, err := client.Batch().
+		BlockByNumber(big.NewInt(0)).
+		HeaderByNumber(nil).
+		HeaderByNumber(big.NewInt(5)).
+		TransactionReceipt(common.Hash{0xbb}).
+		BalanceAt(common.Address{0x42}, nil).
+		BalanceAt(common.Address{}, nil).
+		Execute(context.Background())
+	if err != nil {
+		t.Fatalf("failed to execute batch: %v", err)
+	}
+	if len(results) != 6 {
+		t.Fatalf("result count mismatch: have %d, want %d", len(results), 6)
+	}
+	if block, ok := results[0].Result.(*types.Block); !ok || block.Hash() != service.headers[0].Hash() {
+		t.Errorf("block mismatch: have %v (err %v), want %x", results[0].Result, results[0].Err, service.headers[0].Hash())
+	}
+	if header, ok := results[1].Result.(*types.Header); !ok || header.Hash() != service.headers[1].Hash() {
+		t.Errorf("header mismatch: have %v (err %v), want %x", results[1].Result, results[1].Err, service.headers[1].Hash())
+	}
+	if results[2].Err != ruereum.NotFound {
+		t.Errorf("missing header error mismatch: have %v, want %v", results[2].Err, ruereum.NotFound)
+	}
+	if receipt, ok := results[3].Result.(*types.Receipt); !ok || receipt.TxHash != (common.Hash{0xbb}) {
+		t.Errorf("receipt mismatch: have %v (err %v)", results[3].Result, results[3].Err)
+	}
+	if balance, ok := results[4].Result.(*big.Int); !ok || balance.Int64() != 0x42 {
+		t.Errorf("balance mismatch: have %v (err %v), want %d", results[4].Result, results[4].Err, 0x42)
+	}
+	if results[5].Err == nil {
+		t.Errorf("failing call succeeded: %v", results[5].Result)
+	}
+	// Retrieve the receipts of a whole block
+	receipts, err := client.BlockReceipts(context.Background(), service.headers[1].Hash())
+	if err != nil {
+		t.Fatalf("failed to retrieve block receipts: %v", err)
+	}
+	if len(receipts) != 2 || receipts[0].TxHash != (common.Hash{0xaa}) || receipts[1].TxHash != (common.Hash{0xbb}) {
+		t.Errorf("block receipts mismatch: have %v", receipts)
+	}
+	if _, err := client.BlockReceipts(context.Background(), common.Hash{}); err != ruereum.NotFound {
+		t.Errorf("missing block receipts error mismatch: have %v, want %v", err, ruereum.NotFound)
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright 2018 The go-ruereum Authors
+// This file is part of the go-ruereum library.
+//
+// The go-ruereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ruereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ruereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rueclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/Rue-Foundation/go-rue"
+	"github.com/Rue-Foundation/go-rue/common/hexutil"
+)
+
+// maxGasPriceHistory is the maximum number of blocks a gas price history may span.
+const maxGasPriceHistory = 1024
+
+// GasPriceHistory summarizes the gas usage and the gas prices paid in a range of
+// consecutive blocks.
+type GasPriceHistory struct {
+	OldestBlock  *big.Int     // Number of the first block in the range
+	GasUsedRatio []float64    // Fraction of the gas limit used by each block
+	Percentiles  [][]*big.Int // Requested gas price percentiles of the transactions in each block
+}
+
+// gasPriceBlock is the subset of a block response needed for the gas price history.
+type gasPriceBlock struct {
+	GasUsed      *hexutil.Big `json:"gasUsed"`
+	GasLimit     *hexutil.Big `json:"gasLimit"`
+	Transactions []struct {
+		GasPrice *hexutil.Big `json:"gasPrice"`
+	} `json:"transactions"`
+}
+
+// GasPriceHistory retrieves the gas usage ratio and the given percentiles (in
+// ascending order, between 0 and 100) of the gas prices paid in blockCount blocks
+// ending with lastBlock, in a single batch. If lastBlock is nil, the range ends
+// with the latest known block. Percentiles of blocks without transactions are zero.
+func (ec *Client) GasPriceHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, percentiles []float64) (*GasPriceHistory, error) {
+	if blockCount == 0 || blockCount > maxGasPriceHistory {
+		return nil, fmt.Errorf("invalid block count %d, must be between 1 and %d", blockCount, maxGasPriceHistory)
+	}
+	for i, p := range percentiles {
+		if p < 0 || p > 100 || (i > 0 && p < percentiles[i-1]) {
+			return nil, fmt.Errorf("invalid percentile %v at index %d", p, i)
+		}
+	}
+	// Resolve the range of blocks to summarize
+	if lastBlock == nil {
+		head, err := ec.HeaderByNumber(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		lastBlock = head.Number
+	}
+	oldest := new(big.Int).Sub(lastBlock, new(big.Int).SetUint64(blockCount-1))
+	if oldest.Sign() < 0 {
+		oldest.SetUint64(0)
+	}
+	// Retrieve all the blocks with their transactions in one go
+	batch := ec.Batch()
+	for number := new(big.Int).Set(oldest); number.Cmp(lastBlock) <= 0; number = new(big.Int).Add(number, big.NewInt(1)) {
+		batch.add(decodeGasPriceBlock, "rue_getBlockByNumber", toBlockNumArg(number), true)
+	}
+	results, err := batch.Execute(ctx)
+	if err != nil {
+		return nil, err
+	}
+	history := &GasPriceHistory{
+		OldestBlock:  oldest,
+		GasUsedRatio: make([]float64, len(results)),
+		Percentiles:  make([][]*big.Int, len(results)),
+	}
+	for i, result := range results {
+		if result.Err != nil {
+			return nil, fmt.Errorf("block #%v: %v", new(big.Int).Add(oldest, big.NewInt(int64(i))), result.Err)
+		}
+		block := result.Result.(*gasPriceBlock)
+		if limit := block.GasLimit.ToInt(); limit.Sign() > 0 {
+			used, _ := new(big.Float).Quo(new(big.Float).SetInt(block.GasUsed.ToInt()), new(big.Float).SetInt(limit)).Float64()
+			history.GasUsedRatio[i] = used
+		}
+		prices := make(bigIntSlice, len(block.Transactions))
+		for j, tx := range block.Transactions {
+			prices[j] = tx.GasPrice.ToInt()
+		}
+		sort.Sort(prices)
+
+		history.Percentiles[i] = make([]*big.Int, len(percentiles))
+		for j, p := range percentiles {
+			if len(prices) == 0 {
+				history.Percentiles[i][j] = new(big.Int)
+				continue
+			}
+			idx := int(p / 100 * float64(len(prices)-1))
+			history.Percentiles[i][j] = new(big.Int).Set(prices[idx])
+		}
+	}
+	return history, nil
+}
+
+func decodeGasPriceBlock(raw json.RawMessage) (interface{}, error) {
+	var block *gasPriceBlock
+	if err := json.Unmarshal(raw, &block); err != nil {
+		return nil, err
+	}
+	if block == nil || block.GasUsed == nil || block.GasLimit == nil {
+		return nil, ruereum.NotFound
+	}
+	for _, tx := range block.Transactions {
+		if tx.GasPrice == nil {
+			return nil, fmt.Errorf("server returned transaction without gas price")
+		}
+	}
+	return block, nil
+}
+
+// bigIntSlice attaches the methods of sort.Interface to []*big.Int, sorting in
+// increasing order.
+type bigIntSlice []*big.Int
+
+func (s bigIntSlice) Len() int           { return len(s) }
+func (s bigIntSlice) Less(i, j int) bool { return s[i].Cmp(s[j]) < 0 }
+func (s bigIntSlice) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
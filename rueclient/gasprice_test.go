@@ -0,0 +1,80 @@
+// Copyright 2018 The go-ruereum Authors
+// This file is part of the go-ruereum library.
+//
+// The go-ruereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ruereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ruereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rueclient
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/Rue-Foundation/go-rue/rpc"
+)
+
+// Tests that the gas price history summarizes the gas usage and the requested
+// gas price percentiles of each block in the range.
+func TestGasPriceHistory(t *testing.T) {
+	server := rpc.NewServer()
+	defer server.Stop()
+
+	if err := server.RegisterName("rue", newBatchTestService()); err != nil {
+		t.Fatal(err)
+	}
+	client := NewClient(rpc.DialInProc(server))
+
+	// Summarize the entire chain, ending with the latest block
+	history, err := client.GasPriceHistory(context.Background(), 5, nil, []float64{0, 50, 100})
+	if err != nil {
+		t.Fatalf("failed to retrieve gas price history: %v", err)
+	}
+	if history.OldestBlock.Sign() != 0 {
+		t.Errorf("oldest block mismatch: have %v, want %v", history.OldestBlock, 0)
+	}
+	if len(history.GasUsedRatio) != 2 || history.GasUsedRatio[0] != 0 || history.GasUsedRatio[1] != 0.5 {
+		t.Errorf("gas used ratio mismatch: have %v, want %v", history.GasUsedRatio, []float64{0, 0.5})
+	}
+	want := [][]int64{{0, 0, 0}, {1, 1, 4}}
+	if len(history.Percentiles) != len(want) {
+		t.Fatalf("percentile block count mismatch: have %d, want %d", len(history.Percentiles), len(want))
+	}
+	for i, prices := range history.Percentiles {
+		if len(prices) != len(want[i]) {
+			t.Fatalf("block %d: percentile count mismatch: have %d, want %d", i, len(prices), len(want[i]))
+		}
+		for j, price := range prices {
+			if price.Int64() != want[i][j] {
+				t.Errorf("block %d, percentile %d: price mismatch: have %v, want %v", i, j, price, want[i][j])
+			}
+		}
+	}
+	// Check that a range reaching beyond the chain is rejected
+	if _, err := client.GasPriceHistory(context.Background(), 1, big.NewInt(5), nil); err == nil {
+		t.Errorf("missing block accepted")
+	}
+	// Check that invalid parameters are rejected before any request
+	if _, err := client.GasPriceHistory(context.Background(), 0, nil, nil); err == nil {
+		t.Errorf("zero block count accepted")
+	}
+	if _, err := client.GasPriceHistory(context.Background(), maxGasPriceHistory+1, nil, nil); err == nil {
+		t.Errorf("oversized block count accepted")
+	}
+	if _, err := client.GasPriceHistory(context.Background(), 1, nil, []float64{50, 10}); err == nil {
+		t.Errorf("descending percentiles accepted")
+	}
+	if _, err := client.GasPriceHistory(context.Background(), 1, nil, []float64{101}); err == nil {
+		t.Errorf("out of bounds percentile accepted")
+	}
+}
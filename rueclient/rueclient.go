@@ -24,6 +24,7 @@ import (
 	"fmt"
 	"math/big"
 
+	ruereum "github.com/Rue-Foundation/go-rue"
 	"github.com/Rue-Foundation/go-rue/common"
 	"github.com/Rue-Foundation/go-rue/common/hexutil"
 	"github.com/Rue-Foundation/go-rue/core/types"
@@ -80,62 +81,94 @@ func (ec *Client) getBlock(ctx context.Context, method string, args ...interface
 	err := ec.c.CallContext(ctx, &raw, method, args...)
 	if err != nil {
 		return nil, err
-	} else if len(raw) == 0 {
-		return nil, ruereum.NotFound
+	}
+	head, body, err := decodeBlock(raw)
+	if err != nil {
+		return nil, err
+	}
+	// Load uncles because they are not included in the block response.
+	reqs, uncles := uncleRequests(body)
+	if len(reqs) > 0 {
+		if err := ec.c.BatchCallContext(ctx, reqs); err != nil {
+			return nil, err
+		}
+		if err := checkUncles(body, reqs, uncles); err != nil {
+			return nil, err
+		}
+	}
+	return assembleBlock(head, body, uncles), nil
+}
+
+// decodeBlock parses the header and the body of a block response.
+func decodeBlock(raw json.RawMessage) (*types.Header, *rpcBlock, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil, ruereum.NotFound
 	}
 	// Decode header and transactions.
 	var head *types.Header
 	var body rpcBlock
 	if err := json.Unmarshal(raw, &head); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	if err := json.Unmarshal(raw, &body); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	// Quick-verify transaction and uncle lists. This mostly helps with debugging the server.
 	if head.UncleHash == types.EmptyUncleHash && len(body.UncleHashes) > 0 {
-		return nil, fmt.Errorf("server returned non-empty uncle list but block header indicates no uncles")
+		return nil, nil, fmt.Errorf("server returned non-empty uncle list but block header indicates no uncles")
 	}
 	if head.UncleHash != types.EmptyUncleHash && len(body.UncleHashes) == 0 {
-		return nil, fmt.Errorf("server returned empty uncle list but block header indicates uncles")
+		return nil, nil, fmt.Errorf("server returned empty uncle list but block header indicates uncles")
 	}
 	if head.TxHash == types.EmptyRootHash && len(body.Transactions) > 0 {
-		return nil, fmt.Errorf("server returned non-empty transaction list but block header indicates no transactions")
+		return nil, nil, fmt.Errorf("server returned non-empty transaction list but block header indicates no transactions")
 	}
 	if head.TxHash != types.EmptyRootHash && len(body.Transactions) == 0 {
-		return nil, fmt.Errorf("server returned empty transaction list but block header indicates transactions")
+		return nil, nil, fmt.Errorf("server returned empty transaction list but block header indicates transactions")
 	}
-	// Load uncles because they are not included in the block response.
-	var uncles []*types.Header
-	if len(body.UncleHashes) > 0 {
-		uncles = make([]*types.Header, len(body.UncleHashes))
-		reqs := make([]rpc.BatchElem, len(body.UncleHashes))
-		for i := range reqs {
-			reqs[i] = rpc.BatchElem{
-				Method: "rue_getUncleByBlockHashAndIndex",
-				Args:   []interface{}{body.Hash, hexutil.EncodeUint64(uint64(i))},
-				Result: &uncles[i],
-			}
+	return head, &body, nil
+}
+
+// uncleRequests creates the requests retrieving the uncles of a block, which are
+// not included in the block response, along with the slice they are stored into.
+func uncleRequests(body *rpcBlock) ([]rpc.BatchElem, []*types.Header) {
+	if len(body.UncleHashes) == 0 {
+		return nil, nil
+	}
+	uncles := make([]*types.Header, len(body.UncleHashes))
+	reqs := make([]rpc.BatchElem, len(body.UncleHashes))
+	for i := range reqs {
+		reqs[i] = rpc.BatchElem{
+			Method: "rue_getUncleByBlockHashAndIndex",
+			Args:   []interface{}{body.Hash, hexutil.EncodeUint64(uint64(i))},
+			Result: &uncles[i],
 		}
-		if err := ec.c.BatchCallContext(ctx, reqs); err != nil {
-			return nil, err
+	}
+	return reqs, uncles
+}
+
+// checkUncles verifies that all uncles of a block were retrieved.
+func checkUncles(body *rpcBlock, reqs []rpc.BatchElem, uncles []*types.Header) error {
+	for i := range reqs {
+		if reqs[i].Error != nil {
+			return reqs[i].Error
 		}
-		for i := range reqs {
-			if reqs[i].Error != nil {
-				return nil, reqs[i].Error
-			}
-			if uncles[i] == nil {
-				return nil, fmt.Errorf("got null header for uncle %d of block %x", i, body.Hash[:])
-			}
+		if uncles[i] == nil {
+			return fmt.Errorf("got null header for uncle %d of block %x", i, body.Hash[:])
 		}
 	}
+	return nil
+}
+
+// assembleBlock creates a block from its decoded parts.
+func assembleBlock(head *types.Header, body *rpcBlock, uncles []*types.Header) *types.Block {
 	// Fill the sender cache of transactions in the block.
 	txs := make([]*types.Transaction, len(body.Transactions))
 	for i, tx := range body.Transactions {
 		setSenderFromServer(tx.tx, tx.From, body.Hash)
 		txs[i] = tx.tx
 	}
-	return types.NewBlockWithHeader(head).WithBody(txs, uncles), nil
+	return types.NewBlockWithHeader(head).WithBody(txs, uncles)
 }
 
 // HeaderByHash returns the block header with the given hash.
@@ -252,6 +285,39 @@ func (ec *Client) TransactionReceipt(ctx context.Context, txHash common.Hash) (*
 	return r, err
 }
 
+// BlockReceipts returns the receipts of all transactions in the given block,
+// retrieving them in a single batch.
+func (ec *Client) BlockReceipts(ctx context.Context, blockHash common.Hash) (types.Receipts, error) {
+	var block *struct {
+		Transactions []common.Hash `json:"transactions"`
+	}
+	if err := ec.c.CallContext(ctx, &block, "rue_getBlockByHash", blockHash, false); err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, ruereum.NotFound
+	}
+	batch := ec.Batch()
+	for _, hash := range block.Transactions {
+		batch.TransactionReceipt(hash)
+	}
+	results, err := batch.Execute(ctx)
+	if err != nil {
+		return nil, err
+	}
+	receipts := make(types.Receipts, len(results))
+	for i, result := range results {
+		if result.Err != nil {
+			return nil, fmt.Errorf("receipt %d of block %x: %v", i, blockHash[:], result.Err)
+		}
+		receipts[i] = result.Result.(*types.Receipt)
+		if receipts[i].TxHash != block.Transactions[i] {
+			return nil, fmt.Errorf("server returned receipt of transaction %x instead of %x", receipts[i].TxHash, block.Transactions[i])
+		}
+	}
+	return receipts, nil
+}
+
 func toBlockNumArg(number *big.Int) string {
 	if number == nil {
 		return "latest"
@@ -345,6 +411,72 @@ func (ec *Client) NonceAt(ctx context.Context, account common.Address, blockNumb
 	return uint64(result), err
 }
 
+// AccountResult is the Merkle proof of an account and some of its storage slots.
+type AccountResult struct {
+	Address      common.Address
+	AccountProof []string
+	Balance      *big.Int
+	CodeHash     common.Hash
+	Nonce        uint64
+	StorageHash  common.Hash
+	StorageProof []StorageResult
+}
+
+// StorageResult is the Merkle proof of a single storage slot.
+type StorageResult struct {
+	Key   string
+	Value *big.Int
+	Proof []string
+}
+
+// accountResult is the wire format of an AccountResult.
+type accountResult struct {
+	Address      common.Address  `json:"address"`
+	AccountProof []string        `json:"accountProof"`
+	Balance      *hexutil.Big    `json:"balance"`
+	CodeHash     common.Hash     `json:"codeHash"`
+	Nonce        hexutil.Uint64  `json:"nonce"`
+	StorageHash  common.Hash     `json:"storageHash"`
+	StorageProof []storageResult `json:"storageProof"`
+}
+
+// storageResult is the wire format of a StorageResult.
+type storageResult struct {
+	Key   string       `json:"key"`
+	Value *hexutil.Big `json:"value"`
+	Proof []string     `json:"proof"`
+}
+
+// export converts a proof from its wire format.
+func (res *accountResult) export() *AccountResult {
+	storage := make([]StorageResult, len(res.StorageProof))
+	for i, proof := range res.StorageProof {
+		storage[i] = StorageResult{Key: proof.Key, Value: (*big.Int)(proof.Value), Proof: proof.Proof}
+	}
+	return &AccountResult{
+		Address:      res.Address,
+		AccountProof: res.AccountProof,
+		Balance:      (*big.Int)(res.Balance),
+		CodeHash:     res.CodeHash,
+		Nonce:        uint64(res.Nonce),
+		StorageHash:  res.StorageHash,
+		StorageProof: storage,
+	}
+}
+
+// GetProof returns the Merkle proof of the given account and storage keys.
+// The block number can be nil, in which case the proof is taken from the latest known block.
+func (ec *Client) GetProof(ctx context.Context, account common.Address, keys []string, blockNumber *big.Int) (*AccountResult, error) {
+	var res *accountResult
+	if err := ec.c.CallContext(ctx, &res, "rue_getProof", account, keys, toBlockNumArg(blockNumber)); err != nil {
+		return nil, err
+	}
+	if res == nil {
+		return nil, ruereum.NotFound
+	}
+	return res.export(), nil
+}
+
 // Filters
 
 // FilterLogs executes a filter query.
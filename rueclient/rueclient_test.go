@@ -16,7 +16,15 @@
 
 package rueclient
 
-import "github.com/Rue-Foundation/go-rue"
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/Rue-Foundation/go-rue"
+	"github.com/Rue-Foundation/go-rue/common"
+	"github.com/Rue-Foundation/go-rue/rpc"
+)
 
 // Verify that Client implements the ruereum interfaces.
 var (
@@ -32,3 +40,37 @@ var (
 	// _ = ruereum.PendingStateEventer(&Client{})
 	_ = ruereum.PendingContractCaller(&Client{})
 )
+
+// Tests that account and storage proofs are decoded from their wire format.
+func TestGetProof(t *testing.T) {
+	server := rpc.NewServer()
+	defer server.Stop()
+
+	if err := server.RegisterName("rue", newBatchTestService()); err != nil {
+		t.Fatal(err)
+	}
+	client := NewClient(rpc.DialInProc(server))
+
+	account := common.Address{0x42}
+	result, err := client.GetProof(context.Background(), account, []string{"0x00", "0x01"}, big.NewInt(1))
+	if err != nil {
+		t.Fatalf("failed to retrieve proof: %v", err)
+	}
+	if result.Address != account || result.Balance.Int64() != 0x42 || result.Nonce != 1 {
+		t.Errorf("account mismatch: have %x, balance %v, nonce %d", result.Address, result.Balance, result.Nonce)
+	}
+	if result.CodeHash != (common.Hash{0xcc}) || result.StorageHash != (common.Hash{0x55}) || len(result.AccountProof) != 2 {
+		t.Errorf("account proof mismatch: %+v", result)
+	}
+	if len(result.StorageProof) != 2 {
+		t.Fatalf("storage proof count mismatch: have %d, want %d", len(result.StorageProof), 2)
+	}
+	for i, proof := range result.StorageProof {
+		if proof.Key != []string{"0x00", "0x01"}[i] || proof.Value.Int64() != int64(i+1) || len(proof.Proof) != 1 {
+			t.Errorf("storage proof %d mismatch: %+v", i, proof)
+		}
+	}
+	if _, err := client.GetProof(context.Background(), common.Address{}, nil, nil); err != ruereum.NotFound {
+		t.Errorf("missing account error mismatch: have %v, want %v", err, ruereum.NotFound)
+	}
+}
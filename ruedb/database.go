@@ -22,13 +22,14 @@ import (
 	"sync"
 	"time"
 
+	"github.com/Rue-Foundation/go-rue/common"
 	"github.com/Rue-Foundation/go-rue/log"
 	"github.com/Rue-Foundation/go-rue/metrics"
 	"github.com/syndtr/goleveldb/leveldb"
 	"github.com/syndtr/goleveldb/leveldb/errors"
 	"github.com/syndtr/goleveldb/leveldb/filter"
-	"github.com/syndtr/goleveldb/leveldb/iterator"
 	"github.com/syndtr/goleveldb/leveldb/opt"
+	"github.com/syndtr/goleveldb/leveldb/util"
 
 	gometrics "github.com/rcrowley/go-metrics"
 )
@@ -145,8 +146,24 @@ func (db *LDBDatabase) Delete(key []byte) error {
 	return db.db.Delete(key, nil)
 }
 
-func (db *LDBDatabase) NewIterator() iterator.Iterator {
-	return db.db.NewIterator(nil, nil)
+// NewIterator creates an iterator over the subset of database entries with the
+// given key prefix, starting at the key prefix+start.
+func (db *LDBDatabase) NewIterator(prefix []byte, start []byte) Iterator {
+	r := util.BytesPrefix(prefix)
+	r.Start = append(common.CopyBytes(prefix), start...)
+	return db.db.NewIterator(r, nil)
+}
+
+// Stat returns a particular internal stat of the database.
+func (db *LDBDatabase) Stat(property string) (string, error) {
+	return db.db.GetProperty(property)
+}
+
+// Compact flattens the underlying data store for the given key range. A nil
+// start is treated as a key before all keys, a nil limit as a key after all
+// keys in the data store.
+func (db *LDBDatabase) Compact(start []byte, limit []byte) error {
+	return db.db.CompactRange(util.Range{Start: start, Limit: limit})
 }
 
 func (db *LDBDatabase) Close() {
@@ -291,6 +308,12 @@ func (b *ldbBatch) Put(key, value []byte) error {
 	return nil
 }
 
+func (b *ldbBatch) Delete(key []byte) error {
+	b.b.Delete(key)
+	b.size += len(key)
+	return nil
+}
+
 func (b *ldbBatch) Write() error {
 	return b.db.Write(b.b, nil)
 }
@@ -299,6 +322,11 @@ func (b *ldbBatch) ValueSize() int {
 	return b.size
 }
 
+func (b *ldbBatch) Reset() {
+	b.b.Reset()
+	b.size = 0
+}
+
 type table struct {
 	db     Database
 	prefix string
@@ -329,10 +357,52 @@ func (dt *table) Delete(key []byte) error {
 	return dt.db.Delete(append([]byte(dt.prefix), key...))
 }
 
+func (dt *table) NewIterator(prefix []byte, start []byte) Iterator {
+	return &tableIterator{
+		it:     dt.db.NewIterator(append([]byte(dt.prefix), prefix...), start),
+		prefix: len(dt.prefix),
+	}
+}
+
+func (dt *table) Stat(property string) (string, error) {
+	return dt.db.Stat(property)
+}
+
+func (dt *table) Compact(start []byte, limit []byte) error {
+	// Restrict the compaction to the table's key range
+	start = append([]byte(dt.prefix), start...)
+	if limit == nil {
+		limit = util.BytesPrefix([]byte(dt.prefix)).Limit
+	} else {
+		limit = append([]byte(dt.prefix), limit...)
+	}
+	return dt.db.Compact(start, limit)
+}
+
 func (dt *table) Close() {
 	// Do nothing; don't close the underlying DB.
 }
 
+// tableIterator wraps a database iterator, stripping the table prefix from the
+// iterated keys.
+type tableIterator struct {
+	it     Iterator
+	prefix int
+}
+
+func (it *tableIterator) Next() bool    { return it.it.Next() }
+func (it *tableIterator) Error() error  { return it.it.Error() }
+func (it *tableIterator) Value() []byte { return it.it.Value() }
+func (it *tableIterator) Release()      { it.it.Release() }
+
+func (it *tableIterator) Key() []byte {
+	key := it.it.Key()
+	if key == nil {
+		return nil
+	}
+	return key[it.prefix:]
+}
+
 type tableBatch struct {
 	batch  Batch
 	prefix string
@@ -351,6 +421,10 @@ func (tb *tableBatch) Put(key, value []byte) error {
 	return tb.batch.Put(append([]byte(tb.prefix), key...), value)
 }
 
+func (tb *tableBatch) Delete(key []byte) error {
+	return tb.batch.Delete(append([]byte(tb.prefix), key...))
+}
+
 func (tb *tableBatch) Write() error {
 	return tb.batch.Write()
 }
@@ -358,3 +432,7 @@ func (tb *tableBatch) Write() error {
 func (tb *tableBatch) ValueSize() int {
 	return tb.batch.ValueSize()
 }
+
+func (tb *tableBatch) Reset() {
+	tb.batch.Reset()
+}
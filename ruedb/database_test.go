@@ -190,3 +190,67 @@ func testParallelPutGet(db ruedb.Database, t *testing.T) {
 	}
 	pending.Wait()
 }
+
+func TestLDB_IterateBatch(t *testing.T) {
+	db, remove := newTestLDB()
+	defer remove()
+	testIterateBatch(db, t)
+}
+
+func TestMemoryDB_IterateBatch(t *testing.T) {
+	db, _ := ruedb.NewMemDatabase()
+	testIterateBatch(db, t)
+}
+
+func TestTable_IterateBatch(t *testing.T) {
+	db, _ := ruedb.NewMemDatabase()
+	db.Put([]byte("other"), []byte("entry"))
+	testIterateBatch(ruedb.NewTable(db, "table-"), t)
+}
+
+// testIterateBatch checks that entries written and deleted through batches are
+// iterated over correctly, honouring the requested prefix and start position.
+func testIterateBatch(db ruedb.Database, t *testing.T) {
+	batch := db.NewBatch()
+	for _, key := range []string{"a1", "a2", "b1", "b2", "b3", "c"} {
+		batch.Put([]byte(key), []byte("v"+key))
+	}
+	batch.Delete([]byte("b2"))
+	if err := batch.Write(); err != nil {
+		t.Fatalf("batch write failed: %v", err)
+	}
+	tests := []struct {
+		prefix, start string
+		keys          []string
+	}{
+		{"", "", []string{"a1", "a2", "b1", "b3", "c"}},
+		{"b", "", []string{"b1", "b3"}},
+		{"b", "2", []string{"b3"}},
+		{"", "b", []string{"b1", "b3", "c"}},
+		{"d", "", nil},
+	}
+	for i, tt := range tests {
+		var keys []string
+		it := db.NewIterator([]byte(tt.prefix), []byte(tt.start))
+		for it.Next() {
+			if want := "v" + string(it.Key()); string(it.Value()) != want {
+				t.Errorf("test %d: value mismatch: have %q, want %q", i, it.Value(), want)
+			}
+			keys = append(keys, string(it.Key()))
+		}
+		if err := it.Error(); err != nil {
+			t.Errorf("test %d: iteration failed: %v", i, err)
+		}
+		it.Release()
+
+		if fmt.Sprint(keys) != fmt.Sprint(tt.keys) {
+			t.Errorf("test %d: keys mismatch: have %v, want %v", i, keys, tt.keys)
+		}
+	}
+	if err := db.Compact(nil, nil); err != nil {
+		t.Fatalf("compaction failed: %v", err)
+	}
+	if data, err := db.Get([]byte("c")); err != nil || string(data) != "vc" {
+		t.Fatalf("entry lost after compaction: %q, %v", data, err)
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2018 The go-ruereum Authors
+// This file is part of the go-ruereum library.
+//
+// The go-ruereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ruereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ruereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ruedb
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// DefaultEngine is the database backend used if none was explicitly requested.
+const DefaultEngine = "leveldb"
+
+// Opener opens a persistent database of a particular backend engine at the
+// given path, creating it if it doesn't exist yet. The cache is the amount of
+// memory in megabytes and handles the number of open files the database may use.
+type Opener func(file string, cache int, handles int) (Database, error)
+
+var (
+	enginesLock sync.RWMutex
+	engines     = make(map[string]Opener)
+)
+
+func init() {
+	Register(DefaultEngine, func(file string, cache int, handles int) (Database, error) {
+		db, err := NewLDBDatabase(file, cache, handles)
+		if err != nil {
+			return nil, err
+		}
+		return db, nil
+	})
+}
+
+// Register makes a database backend engine available by the provided name. The
+// databases of an engine should report their general statistics through the
+// "<name>.stats" property. If Register is called twice with the same name or if
+// opener is nil, it panics.
+func Register(name string, opener Opener) {
+	enginesLock.Lock()
+	defer enginesLock.Unlock()
+
+	if opener == nil {
+		panic("ruedb: Register opener is nil")
+	}
+	if _, dup := engines[name]; dup {
+		panic("ruedb: Register called twice for engine " + name)
+	}
+	engines[name] = opener
+}
+
+// Engines returns the sorted list of the names of the registered engines.
+func Engines() []string {
+	enginesLock.RLock()
+	defer enginesLock.RUnlock()
+
+	names := make([]string, 0, len(engines))
+	for name := range engines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Open opens a persistent database at the given path using the requested backend
+// engine. An empty engine name selects the default one.
+func Open(engine string, file string, cache int, handles int) (Database, error) {
+	if engine == "" {
+		engine = DefaultEngine
+	}
+	enginesLock.RLock()
+	opener, ok := engines[engine]
+	enginesLock.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown database engine %q (available: %v)", engine, Engines())
+	}
+	return opener(file, cache, handles)
+}
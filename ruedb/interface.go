@@ -25,20 +25,69 @@ type Putter interface {
 	Put(key []byte, value []byte) error
 }
 
+// Deleter wraps the database delete operation supported by both batches and regular databases.
+type Deleter interface {
+	Delete(key []byte) error
+}
+
 // Database wraps all database operations. All methods are safe for concurrent use.
 type Database interface {
 	Putter
+	Deleter
 	Get(key []byte) ([]byte, error)
 	Has(key []byte) (bool, error)
-	Delete(key []byte) error
 	Close()
 	NewBatch() Batch
+
+	// NewIterator creates an iterator over the subset of database entries with
+	// the given key prefix, starting at the key prefix+start. The iterator walks
+	// the entries in ascending key order.
+	NewIterator(prefix []byte, start []byte) Iterator
+
+	// Stat returns a backend specific statistic of the database, e.g. the
+	// compaction stats of leveldb for the property "leveldb.stats".
+	Stat(property string) (string, error)
+
+	// Compact flattens the underlying data store for the given key range. A nil
+	// start is treated as a key before all keys, a nil limit as a key after all
+	// keys in the data store.
+	Compact(start []byte, limit []byte) error
 }
 
 // Batch is a write-only database that commits changes to its host database
 // when Write is called. Batch cannot be used concurrently.
 type Batch interface {
 	Putter
+	Deleter
 	ValueSize() int // amount of data in the batch
 	Write() error
+	// Reset resets the batch for reuse
+	Reset()
+}
+
+// Iterator iterates over a database's key/value pairs in ascending key order.
+// The iterator must be released after use. Iterators cannot be used
+// concurrently, but it is safe to use multiple iterators at the same time.
+type Iterator interface {
+	// Next moves the iterator to the next key/value pair. It returns whether the
+	// iterator is exhausted.
+	Next() bool
+
+	// Error returns any accumulated error. Exhausting all the key/value pairs
+	// is not considered to be an error.
+	Error() error
+
+	// Key returns the key of the current key/value pair, or nil if done. The
+	// caller should not modify the contents of the returned slice, and its
+	// contents may change on the next call to Next.
+	Key() []byte
+
+	// Value returns the value of the current key/value pair, or nil if done.
+	// The caller should not modify the contents of the returned slice, and its
+	// contents may change on the next call to Next.
+	Value() []byte
+
+	// Release releases associated resources. Release should always succeed and
+	// can be called multiple times without causing error.
+	Release()
 }
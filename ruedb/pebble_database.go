@@ -0,0 +1,288 @@
+// Copyright 2018 The go-ruereum Authors
+// This file is part of the go-ruereum library.
+//
+// The go-ruereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ruereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ruereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// +build amd64 arm64
+// +build !netbsd,!openbsd
+
+package ruedb
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Rue-Foundation/go-rue/common"
+	"github.com/Rue-Foundation/go-rue/log"
+	"github.com/cockroachdb/pebble"
+	"github.com/cockroachdb/pebble/bloom"
+)
+
+var errUnknownProperty = errors.New("unknown property")
+
+func init() {
+	Register("pebble", func(file string, cache int, handles int) (Database, error) {
+		db, err := NewPebbleDatabase(file, cache, handles)
+		if err != nil {
+			return nil, err
+		}
+		return db, nil
+	})
+}
+
+// PebbleDatabase is a persistent key-value store backed by pebble, a LevelDB
+// inspired LSM tree with concurrent compactions and range deletions.
+type PebbleDatabase struct {
+	fn string     // filename for reporting
+	db *pebble.DB // Pebble instance
+
+	log log.Logger // Contextual logger tracking the database path
+}
+
+// NewPebbleDatabase returns a pebble wrapped object.
+func NewPebbleDatabase(file string, cache int, handles int) (*PebbleDatabase, error) {
+	logger := log.New("database", file)
+
+	// Ensure we have some minimal caching and file guarantees
+	if cache < 16 {
+		cache = 16
+	}
+	if handles < 16 {
+		handles = 16
+	}
+	logger.Info("Allocated cache and file handles", "cache", cache, "handles", handles)
+
+	// The block cache is reference counted, the database keeps its own reference
+	pcache := pebble.NewCache(int64(cache/2) * 1024 * 1024)
+	defer pcache.Unref()
+
+	db, err := pebble.Open(file, &pebble.Options{
+		Cache:        pcache,
+		MaxOpenFiles: handles,
+		MemTableSize: cache / 4 * 1024 * 1024, // Two of these are used internally
+		Levels: []pebble.LevelOptions{
+			{FilterPolicy: bloom.FilterPolicy(10)},
+		},
+		Logger: pebbleLogger{logger},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &PebbleDatabase{
+		fn:  file,
+		db:  db,
+		log: logger,
+	}, nil
+}
+
+// Path returns the path to the database directory.
+func (db *PebbleDatabase) Path() string {
+	return db.fn
+}
+
+// Put inserts the given value into the database.
+func (db *PebbleDatabase) Put(key []byte, value []byte) error {
+	return db.db.Set(key, value, pebble.NoSync)
+}
+
+// Has retrieves whether a key is present in the database.
+func (db *PebbleDatabase) Has(key []byte) (bool, error) {
+	_, closer, err := db.db.Get(key)
+	switch err {
+	case nil:
+		closer.Close()
+		return true, nil
+	case pebble.ErrNotFound:
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+// Get retrieves the given key if it's present in the database.
+func (db *PebbleDatabase) Get(key []byte) ([]byte, error) {
+	dat, closer, err := db.db.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	// The returned slice is only valid until the closer is called
+	defer closer.Close()
+	return common.CopyBytes(dat), nil
+}
+
+// Delete removes the key from the database.
+func (db *PebbleDatabase) Delete(key []byte) error {
+	return db.db.Delete(key, pebble.NoSync)
+}
+
+// NewIterator creates an iterator over a consistent snapshot of the database
+// entries with the given key prefix, starting at the key prefix+start.
+func (db *PebbleDatabase) NewIterator(prefix []byte, start []byte) Iterator {
+	it := db.db.NewIter(&pebble.IterOptions{
+		LowerBound: append(common.CopyBytes(prefix), start...),
+		UpperBound: upperBound(prefix),
+	})
+	it.First()
+	return &pebbleIterator{it: it}
+}
+
+// Stat returns a particular internal stat of the database. The only supported
+// property is "pebble.stats", the metrics of the LSM tree levels.
+func (db *PebbleDatabase) Stat(property string) (string, error) {
+	if property != "pebble.stats" {
+		return "", errUnknownProperty
+	}
+	return db.db.Metrics().String(), nil
+}
+
+// Compact flattens the underlying data store for the given key range. A nil
+// start is treated as a key before all keys, a nil limit as a key after all
+// keys in the data store.
+func (db *PebbleDatabase) Compact(start []byte, limit []byte) error {
+	// Pebble needs explicit bounds, substitute the last key for a missing limit
+	if limit == nil {
+		it := db.db.NewIter(nil)
+		if it.Last() {
+			limit = common.CopyBytes(it.Key())
+		}
+		if err := it.Close(); err != nil {
+			return err
+		}
+		if limit == nil {
+			return nil // Empty database, nothing to compact
+		}
+	}
+	if start == nil {
+		start = []byte{}
+	}
+	return db.db.Compact(start, limit)
+}
+
+// Close flushes any pending writes and closes the database.
+func (db *PebbleDatabase) Close() {
+	if err := db.db.Close(); err == nil {
+		db.log.Info("Database closed")
+	} else {
+		db.log.Error("Failed to close database", "err", err)
+	}
+}
+
+// NewBatch creates a write-only batch buffering its changes until written.
+func (db *PebbleDatabase) NewBatch() Batch {
+	return &pebbleBatch{b: db.db.NewBatch()}
+}
+
+type pebbleBatch struct {
+	b    *pebble.Batch
+	size int
+}
+
+func (b *pebbleBatch) Put(key, value []byte) error {
+	b.size += len(value)
+	return b.b.Set(key, value, nil)
+}
+
+func (b *pebbleBatch) Delete(key []byte) error {
+	b.size += len(key)
+	return b.b.Delete(key, nil)
+}
+
+func (b *pebbleBatch) Write() error {
+	return b.b.Commit(pebble.NoSync)
+}
+
+func (b *pebbleBatch) ValueSize() int {
+	return b.size
+}
+
+func (b *pebbleBatch) Reset() {
+	b.b.Reset()
+	b.size = 0
+}
+
+// pebbleIterator adapts a pebble iterator, which is positioned on creation, to
+// the Next-first iteration of the database interface.
+type pebbleIterator struct {
+	it     *pebble.Iterator
+	moved  bool
+	closed bool
+}
+
+func (it *pebbleIterator) Next() bool {
+	if it.closed {
+		return false
+	}
+	if it.moved {
+		return it.it.Next()
+	}
+	it.moved = true
+	return it.it.Valid()
+}
+
+func (it *pebbleIterator) Error() error {
+	if it.closed {
+		return nil
+	}
+	return it.it.Error()
+}
+
+func (it *pebbleIterator) Key() []byte {
+	if it.closed || !it.it.Valid() {
+		return nil
+	}
+	return it.it.Key()
+}
+
+func (it *pebbleIterator) Value() []byte {
+	if it.closed || !it.it.Valid() {
+		return nil
+	}
+	return it.it.Value()
+}
+
+func (it *pebbleIterator) Release() {
+	if !it.closed {
+		it.it.Close()
+		it.closed = true
+	}
+}
+
+// upperBound returns the smallest key larger than all keys with the given
+// prefix, or nil if there's no such key.
+func upperBound(prefix []byte) []byte {
+	var limit []byte
+	for i := len(prefix) - 1; i >= 0; i-- {
+		if c := prefix[i]; c < 0xff {
+			limit = make([]byte, i+1)
+			copy(limit, prefix)
+			limit[i] = c + 1
+			break
+		}
+	}
+	return limit
+}
+
+// pebbleLogger forwards the log messages of pebble to a contextual logger.
+type pebbleLogger struct {
+	log.Logger
+}
+
+func (l pebbleLogger) Infof(format string, args ...interface{}) {
+	l.Debug(strings.TrimSpace(fmt.Sprintf(format, args...)))
+}
+
+func (l pebbleLogger) Fatalf(format string, args ...interface{}) {
+	l.Crit(strings.TrimSpace(fmt.Sprintf(format, args...)))
+}
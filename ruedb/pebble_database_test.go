@@ -0,0 +1,62 @@
+// Copyright 2018 The go-ruereum Authors
+// This file is part of the go-ruereum library.
+//
+// The go-ruereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ruereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ruereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// +build amd64 arm64
+// +build !netbsd,!openbsd
+
+package ruedb_test
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/Rue-Foundation/go-rue/ruedb"
+)
+
+func newTestPebble() (*ruedb.PebbleDatabase, func()) {
+	dirname, err := ioutil.TempDir(os.TempDir(), "ruedb_test_")
+	if err != nil {
+		panic("failed to create test file: " + err.Error())
+	}
+	db, err := ruedb.NewPebbleDatabase(dirname, 0, 0)
+	if err != nil {
+		panic("failed to create test database: " + err.Error())
+	}
+
+	return db, func() {
+		db.Close()
+		os.RemoveAll(dirname)
+	}
+}
+
+func TestPebble_PutGet(t *testing.T) {
+	db, remove := newTestPebble()
+	defer remove()
+	testPutGet(db, t)
+}
+
+func TestPebble_ParallelPutGet(t *testing.T) {
+	db, remove := newTestPebble()
+	defer remove()
+	testParallelPutGet(db, t)
+}
+
+func TestPebble_IterateBatch(t *testing.T) {
+	db, remove := newTestPebble()
+	defer remove()
+	testIterateBatch(db, t)
+}